@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+
+	"github.com/jwc20/ssh-ttt/engine"
+	"github.com/jwc20/ssh-ttt/variant"
 )
 
 const PlayerPrompt = "Player %s, enter your move (1-9): "
@@ -13,6 +16,10 @@ const SquareTakenErrMsg = "That square is already taken, please choose another\n
 const DrawMsg = "It's a draw!\n"
 const WinMsg = "Player %s wins!\n"
 const BoardHeader = "\nCurrent board:\n"
+const CoordinatePrompt = "Player %s, enter your move as layer,row,column (1-%d): "
+const BadCoordinateErrMsg = "Bad value received for move, please enter layer,row,column, each between 1 and %d\n"
+const ComputerMoveMsg = "Player %s (computer) moves\n"
+const ComputerErrMsg = "The computer can't move: %v\n"
 
 type TicTacToeGame interface {
 	Game
@@ -23,10 +30,49 @@ type TicTacToeGame interface {
 	Winner() string
 }
 
+// CoordinateGame is a TicTacToeGame whose moves are entered as
+// coordinates rather than the numbers 1-9.
+type CoordinateGame interface {
+	TicTacToeGame
+	Size() int
+	// ParseMove turns the player's input into the position MakeMove takes.
+	ParseMove(input string) (int, error)
+}
+
+// ComputerGame is a TicTacToeGame a computer player can join. State is the
+// position for an engine to search, or an error if the game is in an
+// invalid position, and PlayMove takes the engine's move.
+type ComputerGame interface {
+	TicTacToeGame
+	State() (variant.State, error)
+	PlayMove(move int) error
+}
+
+// RecordableGame is a ComputerGame the CLI can save and load in game
+// notation. Moves are the moves played so far, as PlayMove takes them.
+type RecordableGame interface {
+	ComputerGame
+	Variant() string
+	Moves() []int
+}
+
+// SetUpGame is a RecordableGame that can start from a set-up position.
+type SetUpGame interface {
+	RecordableGame
+	SetUp(p *Position)
+	Setup() *Position
+}
+
 type CLI struct {
 	in   *bufio.Scanner
 	out  io.Writer
 	game TicTacToeGame
+
+	computer       engine.Engine
+	computerPlayer string
+
+	// opening holds the moves of a loaded game, played once it starts.
+	opening []int
 }
 
 func NewCLI(in io.Reader, out io.Writer, game TicTacToeGame) *CLI {
@@ -37,23 +83,90 @@ func NewCLI(in io.Reader, out io.Writer, game TicTacToeGame) *CLI {
 	}
 }
 
+// SetComputer has e play for player, "X" or "O". It only takes effect when
+// the game is a ComputerGame.
+func (cli *CLI) SetComputer(player string, e engine.Engine) {
+	cli.computer, cli.computerPlayer = e, player
+}
+
+// Load has PlayGame carry on from the game r records. It fails unless the
+// game is a RecordableGame of the same variant, a SetUpGame if r starts
+// from a set-up position, and the moves are legal and leave the game
+// unfinished.
+func (cli *CLI) Load(r GameRecord) error {
+	game, ok := cli.game.(RecordableGame)
+	if !ok || game.Variant() != r.Variant {
+		return fmt.Errorf("%w: can't load a %s game here", ErrBadNotation, r.Variant)
+	}
+	s, err := r.State()
+	if err != nil {
+		return err
+	}
+	if s.Over() {
+		return fmt.Errorf("%w: the game is already over", ErrBadNotation)
+	}
+	if r.Setup != nil {
+		setUp, ok := game.(SetUpGame)
+		if !ok {
+			return fmt.Errorf("%w: can't set up a %s game here", ErrBadPosition, r.Variant)
+		}
+		setUp.SetUp(r.Setup)
+	}
+	cli.opening = r.Moves
+	return nil
+}
+
+// Record returns the game so far in notation form. It reports false when
+// the game can't be recorded.
+func (cli *CLI) Record() (GameRecord, bool) {
+	game, ok := cli.game.(RecordableGame)
+	if !ok {
+		return GameRecord{}, false
+	}
+	s, err := game.State()
+	if err != nil {
+		return GameRecord{}, false
+	}
+	r := GameRecord{
+		Variant: game.Variant(),
+		Moves:   game.Moves(),
+		Result:  ResultOf(s),
+	}
+	if setUp, ok := game.(SetUpGame); ok {
+		r.Setup = setUp.Setup()
+	}
+	return r, true
+}
+
 func (cli *CLI) PlayGame() {
 	cli.game.Start(2)
+	if game, ok := cli.game.(RecordableGame); ok {
+		for _, m := range cli.opening {
+			game.PlayMove(m)
+		}
+	}
 
 	for !cli.game.IsOver() {
 		fmt.Fprint(cli.out, BoardHeader)
 		fmt.Fprint(cli.out, cli.game.Board())
-		fmt.Fprintf(cli.out, PlayerPrompt, cli.game.CurrentPlayer())
 
-		input := cli.readLine()
-		position, err := strconv.Atoi(input)
+		if game, ok := cli.computerTurn(); ok {
+			s, err := game.State()
+			if err != nil {
+				fmt.Fprintf(cli.out, ComputerErrMsg, err)
+				return
+			}
+			fmt.Fprintf(cli.out, ComputerMoveMsg, game.CurrentPlayer())
+			game.PlayMove(cli.computer.Move(s))
+			continue
+		}
 
-		if err != nil || position < 1 || position > 9 {
-			fmt.Fprint(cli.out, BadMoveInputErrMsg)
+		position, ok := cli.readMove()
+		if !ok {
 			continue
 		}
 
-		err = cli.game.MakeMove(position)
+		err := cli.game.MakeMove(position)
 		if err != nil {
 			fmt.Fprint(cli.out, SquareTakenErrMsg)
 			continue
@@ -72,6 +185,37 @@ func (cli *CLI) PlayGame() {
 	}
 }
 
+// computerTurn reports whether the computer is to move.
+func (cli *CLI) computerTurn() (ComputerGame, bool) {
+	game, ok := cli.game.(ComputerGame)
+	if !ok || cli.computer == nil || game.CurrentPlayer() != cli.computerPlayer {
+		return nil, false
+	}
+	return game, true
+}
+
+// readMove prompts for the next move and reports false, after telling the
+// player why, when the input is not a valid position.
+func (cli *CLI) readMove() (int, bool) {
+	if game, ok := cli.game.(CoordinateGame); ok {
+		fmt.Fprintf(cli.out, CoordinatePrompt, game.CurrentPlayer(), game.Size())
+		position, err := game.ParseMove(cli.readLine())
+		if err != nil {
+			fmt.Fprintf(cli.out, BadCoordinateErrMsg, game.Size())
+			return 0, false
+		}
+		return position, true
+	}
+
+	fmt.Fprintf(cli.out, PlayerPrompt, cli.game.CurrentPlayer())
+	position, err := strconv.Atoi(cli.readLine())
+	if err != nil || position < 1 || position > 9 {
+		fmt.Fprint(cli.out, BadMoveInputErrMsg)
+		return 0, false
+	}
+	return position, true
+}
+
 func (cli *CLI) readLine() string {
 	cli.in.Scan()
 	return cli.in.Text()
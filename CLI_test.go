@@ -2,10 +2,13 @@ package ttt_test
 
 import (
 	"bytes"
+	"errors"
+	"reflect"
 	"strings"
 	"testing"
 
 	ttt "github.com/jwc20/ssh-ttt"
+	"github.com/jwc20/ssh-ttt/variant"
 )
 
 var dummyPlayerStore = &ttt.StubPlayerStore{}
@@ -143,6 +146,106 @@ func TestCLI(t *testing.T) {
 
 		assertOutputContains(t, stdout, "Player X wins!\n")
 	})
+
+	t.Run("computer plays its side", func(t *testing.T) {
+		store := &ttt.StubPlayerStore{}
+		stdout := &bytes.Buffer{}
+
+		in := userSends("1", "2", "3")
+		cli := ttt.NewCLI(in, stdout, ttt.NewTicTacToe(store))
+		cli.SetComputer("O", &scriptedEngine{moves: []int{3, 4}})
+
+		cli.PlayGame()
+
+		assertOutputContains(t, stdout, "Player O (computer) moves\n")
+		assertOutputContains(t, stdout, " o | o |   \n")
+		assertOutputContains(t, stdout, "Player X wins!\n")
+	})
+
+	t.Run("carries on from a loaded game and records it", func(t *testing.T) {
+		record, err := ttt.ParseGame("[Variant \"classic\"]\n\n1. b2 b3 2. c3 a1 *")
+		if err != nil {
+			t.Fatal(err)
+		}
+		stdout := &bytes.Buffer{}
+		cli := ttt.NewCLI(userSends("9", "1", "6"), stdout, ttt.NewTicTacToe(&ttt.StubPlayerStore{}))
+		if err := cli.Load(record); err != nil {
+			t.Fatal(err)
+		}
+
+		cli.PlayGame()
+
+		assertOutputContains(t, stdout, "Player X wins!\n")
+		got, ok := cli.Record()
+		if !ok {
+			t.Fatal("game can't be recorded")
+		}
+		want := ttt.GameRecord{Variant: "classic", Result: ttt.ResultXWins, Moves: []int{4, 1, 2, 6, 8, 0, 5}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("starts from a set-up position", func(t *testing.T) {
+		setup, err := ttt.ParsePosition("x1o/1x1/3 o")
+		if err != nil {
+			t.Fatal(err)
+		}
+		stdout := &bytes.Buffer{}
+		cli := ttt.NewCLI(userSends("7", "9"), stdout, ttt.NewTicTacToe(&ttt.StubPlayerStore{}))
+		if err := cli.Load(ttt.GameRecord{Variant: "classic", Setup: &setup}); err != nil {
+			t.Fatal(err)
+		}
+
+		cli.PlayGame()
+
+		assertOutputContains(t, stdout, "Player X wins!\n")
+		got, _ := cli.Record()
+		want := ttt.GameRecord{Variant: "classic", Setup: &setup, Result: ttt.ResultXWins, Moves: []int{6, 8}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("records qubic games", func(t *testing.T) {
+		cli := ttt.NewCLI(userSends("1,1,1", "2,1,1", "1,2,2", "2,2,2", "1,3,3"), dummyStdOut, ttt.NewQubic(&ttt.StubPlayerStore{}, 3))
+
+		cli.PlayGame()
+
+		got, _ := cli.Record()
+		want := ttt.GameRecord{Variant: "qubic3", Result: ttt.ResultXWins, Moves: []int{0, 9, 4, 13, 8}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("won't load another variant", func(t *testing.T) {
+		cli := ttt.NewCLI(userSends(), dummyStdOut, ttt.NewTicTacToe(dummyPlayerStore))
+		err := cli.Load(ttt.GameRecord{Variant: "qubic3"})
+		if !errors.Is(err, ttt.ErrBadNotation) {
+			t.Errorf("got %v, want ErrBadNotation", err)
+		}
+		cli = ttt.NewCLI(userSends(), dummyStdOut, &GameSpy{})
+		if err := cli.Load(ttt.GameRecord{Variant: "classic"}); err == nil {
+			t.Error("loaded a game that can't replay moves")
+		}
+		cli = ttt.NewCLI(userSends(), dummyStdOut, ttt.NewTicTacToe(dummyPlayerStore))
+		finished := ttt.GameRecord{Variant: "classic", Moves: []int{4, 1, 2, 6, 8, 0, 5}}
+		if err := cli.Load(finished); !errors.Is(err, ttt.ErrBadNotation) {
+			t.Errorf("got %v loading a finished game, want ErrBadNotation", err)
+		}
+	})
+}
+
+// scriptedEngine plays its moves in order.
+type scriptedEngine struct {
+	moves []int
+}
+
+func (e *scriptedEngine) Move(variant.State) int {
+	m := e.moves[0]
+	e.moves = e.moves[1:]
+	return m
 }
 
 func userSends(messages ...string) *strings.Reader {
@@ -0,0 +1,413 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Admin Operations
+// ─────────────────────────────────────────────────────────────────────────────
+
+type SessionInfo struct {
+	ID          string
+	UserID      string
+	Room        string
+	Addr        string
+	ConnectedAt time.Time
+}
+
+type (
+	AnnouncementMsg struct {
+		From, Text string
+		At         time.Time
+	}
+	RoomClosedMsg struct{ Reason string }
+)
+
+// Audit records an admin action. Failures are logged, never surfaced, so an
+// audit problem can't block moderation.
+func (s *SQLiteStore) Audit(admin, action, target, detail string) {
+	defer storeTimer("audit")()
+
+	_, err := s.db.Exec(
+		"INSERT INTO admin_audit (admin, action, target, detail) VALUES (?, ?, ?, ?)",
+		admin, action, target, detail,
+	)
+	if err != nil {
+		log.Error("audit log", "error", err)
+	}
+}
+
+// GamesSince counts games finished since the given time.
+func (s *SQLiteStore) GamesSince(since time.Time) int {
+	defer storeTimer("games_since")()
+
+	var n int
+	_ = s.db.QueryRow("SELECT COUNT(*) FROM games WHERE finished_at >= ?",
+		since.UTC().Format(sqliteTimeLayout)).Scan(&n)
+	return n
+}
+
+// Sessions lists connected sessions, oldest first.
+func (s *SharedState) Sessions() []SessionInfo {
+	s.sessMu.RLock()
+	defer s.sessMu.RUnlock()
+
+	list := make([]SessionInfo, 0, len(s.sessions))
+	for id, sess := range s.sessions {
+		list = append(list, SessionInfo{
+			ID:          id,
+			UserID:      sess.UserID,
+			Room:        sess.Room,
+			Addr:        sess.Addr,
+			ConnectedAt: sess.ConnectedAt,
+		})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ConnectedAt.Before(list[j].ConnectedAt) })
+	return list
+}
+
+// Kick ends every session of userID. It returns how many were closed.
+func (s *SharedState) Kick(userID string) int {
+	s.sessMu.RLock()
+	defer s.sessMu.RUnlock()
+
+	n := 0
+	for _, sess := range s.sessions {
+		if sess.UserID == userID {
+			go sess.Program.Quit()
+			n++
+		}
+	}
+	return n
+}
+
+// Announce shows a server-wide message on every session.
+func (s *SharedState) Announce(from, text string) {
+	msg := AnnouncementMsg{From: from, Text: text, At: time.Now()}
+	s.sessMu.RLock()
+	defer s.sessMu.RUnlock()
+	for _, sess := range s.sessions {
+		p := sess.Program
+		go p.Send(msg)
+	}
+}
+
+// Close sends everyone in the room back to the lobby and stops its clock.
+func (r *Room) Close(reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.clock.timer != nil {
+		r.clock.timer.Stop()
+	}
+	r.broadcastLocked(RoomClosedMsg{Reason: reason})
+	r.clients = make(map[string]*Client)
+}
+
+// Reset starts a fresh game with the same seats.
+func (r *Room) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.clock.timer != nil {
+		r.clock.timer.Stop()
+	}
+	r.game = NewGameState(r.settings)
+	r.clock = gameClock{}
+	r.started = false
+	r.hinted = false
+	r.finishedAt = time.Time{}
+	if r.seatTakenLocked(RolePlayerX) && r.seatTakenLocked(RolePlayerO) {
+		r.startLocked()
+	}
+
+	r.persistLocked()
+	r.postLocked(RoomChatMsg{Text: "the game was reset by an admin", Kind: chatSystem})
+	r.broadcastLocked(r.gameSnapshot())
+}
+
+// Remove deletes a room and its saved snapshot.
+func (rm *RoomManager) Remove(id string) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	delete(rm.rooms, id)
+	if err := rm.store.DeleteRoomSnapshot(id); err != nil {
+		log.Error("delete room snapshot", "room", id, "error", err)
+	}
+	events.Emit(evRoomRemoved, "room", id, "reason", "closed")
+}
+
+func (rm *RoomManager) Get(id string) *Room {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	return rm.rooms[id]
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Admin Console Model
+// ─────────────────────────────────────────────────────────────────────────────
+
+type adminTab int
+
+const (
+	adminSessions adminTab = iota
+	adminRooms
+	adminMetrics
+)
+
+type adminRefreshMsg struct{}
+
+func adminRefresh() tea.Cmd {
+	return tea.Tick(2*time.Second, func(time.Time) tea.Msg { return adminRefreshMsg{} })
+}
+
+type adminModel struct {
+	shared    *SharedState
+	userID    string
+	startedAt time.Time
+
+	tab      adminTab
+	cursor   int
+	sessions []SessionInfo
+	rooms    []RoomInfo
+
+	announcing bool
+	input      textinput.Model
+	status     string
+}
+
+func newAdminModel(shared *SharedState, userID string) adminModel {
+	ti := textinput.New()
+	ti.Placeholder = "Announcement..."
+	ti.CharLimit = 200
+	ti.Width = 50
+
+	m := adminModel{shared: shared, userID: userID, startedAt: shared.StartedAt, input: ti}
+	m.reload()
+	return m
+}
+
+func (m *adminModel) reload() {
+	m.sessions = m.shared.Sessions()
+	m.rooms = m.shared.Rooms.List()
+	m.cursor = min(m.cursor, max(m.rowCount()-1, 0))
+}
+
+func (m adminModel) rowCount() int {
+	switch m.tab {
+	case adminSessions:
+		return len(m.sessions)
+	case adminRooms:
+		return len(m.rooms)
+	default:
+		return 0
+	}
+}
+
+func (m adminModel) Init() tea.Cmd { return adminRefresh() }
+
+func (m adminModel) Update(msg tea.Msg) (adminModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case adminRefreshMsg:
+		m.reload()
+		return m, adminRefresh()
+
+	case tea.KeyMsg:
+		if m.announcing {
+			return m.handleAnnounceInput(msg)
+		}
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+func (m adminModel) handleAnnounceInput(msg tea.KeyMsg) (adminModel, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.announcing = false
+		m.input.Blur()
+		return m, nil
+	case "enter":
+		text := strings.TrimSpace(m.input.Value())
+		if text != "" {
+			m.shared.Announce(m.userID, text)
+			m.shared.Store.Audit(m.userID, "announce", "", text)
+			m.status = "announcement sent"
+		}
+		m.announcing = false
+		m.input.Blur()
+		m.input.Reset()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m adminModel) handleKey(msg tea.KeyMsg) (adminModel, tea.Cmd) {
+	switch msg.String() {
+	case "tab":
+		m.tab = (m.tab + 1) % (adminMetrics + 1)
+		m.cursor = 0
+	case "up", "k":
+		m.cursor = max(m.cursor-1, 0)
+	case "down", "j":
+		m.cursor = min(m.cursor+1, max(m.rowCount()-1, 0))
+	case "a":
+		m.announcing = true
+		m.input.Focus()
+		return m, textinput.Blink
+	case "K":
+		if s, ok := m.selectedSession(); ok {
+			n := m.shared.Kick(s.UserID)
+			m.shared.Store.Audit(m.userID, "kick", s.UserID, fmt.Sprintf("%d sessions", n))
+			m.status = fmt.Sprintf("kicked %s (%d sessions)", s.UserID, n)
+		}
+	case "B":
+		if s, ok := m.selectedSession(); ok {
+			if err := m.shared.Store.SetSanction(s.UserID, sanctionBan, m.userID); err != nil {
+				m.status = "ban failed: " + err.Error()
+				break
+			}
+			m.shared.Kick(s.UserID)
+			m.shared.Store.Audit(m.userID, "ban", s.UserID, "")
+			m.status = "banned " + s.UserID
+		}
+	case "X":
+		if r, ok := m.selectedRoom(); ok {
+			if room := m.shared.Rooms.Get(r.ID); room != nil {
+				room.Close("closed by an admin")
+			}
+			m.shared.Rooms.Remove(r.ID)
+			m.shared.BroadcastLobby()
+			m.shared.Store.Audit(m.userID, "close_room", r.ID, "")
+			m.status = "closed room " + r.ID
+		}
+	case "R":
+		if r, ok := m.selectedRoom(); ok {
+			if room := m.shared.Rooms.Get(r.ID); room != nil {
+				room.Reset()
+				m.shared.Store.Audit(m.userID, "reset_room", r.ID, "")
+				m.status = "reset room " + r.ID
+			}
+		}
+	case "esc":
+		return m, func() tea.Msg { return closeAdminMsg{} }
+	default:
+		return m, nil
+	}
+
+	m.reload()
+	return m, nil
+}
+
+type closeAdminMsg struct{}
+
+func (m adminModel) selectedSession() (SessionInfo, bool) {
+	if m.tab != adminSessions || m.cursor >= len(m.sessions) {
+		return SessionInfo{}, false
+	}
+	return m.sessions[m.cursor], true
+}
+
+func (m adminModel) selectedRoom() (RoomInfo, bool) {
+	if m.tab != adminRooms || m.cursor >= len(m.rooms) {
+		return RoomInfo{}, false
+	}
+	return m.rooms[m.cursor], true
+}
+
+var adminTitleStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("1")).MarginBottom(1)
+
+func (m adminModel) View() string {
+	var b strings.Builder
+	b.WriteString(adminTitleStyle.Render("⚙  Admin Console") + "\n\n")
+
+	labels := []string{"Sessions", "Rooms", "Metrics"}
+	var tabs []string
+	for i, l := range labels {
+		if adminTab(i) == m.tab {
+			tabs = append(tabs, lobbyTabActive.Render(l))
+		} else {
+			tabs = append(tabs, lobbyTabInactive.Render(l))
+		}
+	}
+	b.WriteString("  " + strings.Join(tabs, "  ") + "\n\n")
+
+	switch m.tab {
+	case adminSessions:
+		for i, s := range m.sessions {
+			where := "lobby"
+			if s.Room != "" {
+				where = s.Room
+			}
+			line := fmt.Sprintf("%-16s %-16s %-21s %s", s.UserID, where, s.Addr,
+				time.Since(s.ConnectedAt).Round(time.Second))
+			b.WriteString(m.row(i, line))
+		}
+	case adminRooms:
+		for i, r := range m.rooms {
+			line := fmt.Sprintf("%-20s %-9s players %d  watching %d", r.ID, r.Status, r.Players, r.Spectators)
+			b.WriteString(m.row(i, line))
+		}
+	case adminMetrics:
+		b.WriteString(m.viewMetrics())
+	}
+
+	if m.announcing {
+		b.WriteString("\n  " + m.input.View() + "\n")
+	}
+	if m.status != "" {
+		b.WriteString("\n  " + focusLabel.Render(m.status) + "\n")
+	}
+
+	help := "  tab: section  ↑/↓: select  a: announce  esc: back"
+	switch m.tab {
+	case adminSessions:
+		help += "  K: kick  B: ban"
+	case adminRooms:
+		help += "  X: close  R: reset"
+	}
+	b.WriteString(lobbyHelpStyle.Render(help))
+	return b.String()
+}
+
+func (m adminModel) row(i int, line string) string {
+	if i == m.cursor {
+		return lobbySelectedItem.Render("▸ "+line) + "\n"
+	}
+	return lobbyItemStyle.Render("  "+line) + "\n"
+}
+
+func (m adminModel) viewMetrics() string {
+	byStatus := map[string]int{}
+	for _, r := range m.rooms {
+		byStatus[r.Status]++
+	}
+
+	m.shared.lobbyMu.RLock()
+	lobby := len(m.shared.lobby)
+	m.shared.lobbyMu.RUnlock()
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	today := windowToday.Since(time.Now())
+	return fmt.Sprintf(
+		"  sessions      %d\n  in lobby      %d\n  rooms         %d (waiting %d, playing %d, finished %d)\n"+
+			"  games today   %d\n  uptime        %s\n  goroutines    %d\n  heap          %.1f MiB\n",
+		len(m.sessions), lobby, len(m.rooms), byStatus["waiting"], byStatus["playing"], byStatus["finished"],
+		m.shared.Store.GamesSince(today), time.Since(m.startedAt).Round(time.Second),
+		runtime.NumGoroutine(), float64(mem.HeapAlloc)/(1<<20),
+	)
+}
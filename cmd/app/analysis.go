@@ -0,0 +1,364 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	ttt "github.com/jwc20/ssh-ttt"
+	"github.com/jwc20/ssh-ttt/engine"
+	"github.com/jwc20/ssh-ttt/variant"
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Hints & Post-Game Analysis (room model)
+// ─────────────────────────────────────────────────────────────────────────────
+
+type (
+	// hintMsg carries the engine's suggestion for the position after moves
+	// moves; it is dropped if the game has moved on.
+	hintMsg struct{ moves, cell int }
+	// analysisMsg carries a finished analysis.
+	analysisMsg struct{ analysis *gameAnalysis }
+)
+
+// The analysis search is shallower than the computer player's: it scores
+// every move of every position, not just one.
+const (
+	analysisDepth = 4
+	analysisNodes = 20_000
+)
+
+// analysisSlack is how far below the best a heuristic score may fall before
+// the move counts as an inaccuracy; small differences are search noise.
+const analysisSlack = 3
+
+// hintsAllowed reports whether players may ask for hints. Every game
+// between two people moves both their ratings, so hints are only offered
+// against the computer, whose games are unrated. A game in which a hint
+// was shown earns no achievements either.
+func hintsAllowed(settings RoomSettings) bool {
+	return settings.Computer != ""
+}
+
+// NoteHint records that a hint was shown in the current game.
+func (r *Room) NoteHint() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hinted = true
+}
+
+// classicPosition is a classic board in the library's terms.
+func classicPosition(s variant.State) ttt.Position {
+	return ttt.Position{
+		Turn:  strings.ToLower(string(s.Turn())),
+		Board: strings.ToLower(string(s.Cells())),
+	}
+}
+
+// bestMove is the move the engine would play: the tablebase's for classic
+// games, a depth-limited search's otherwise.
+func bestMove(s variant.State) int {
+	if _, ok := s.(*variant.Classic); ok {
+		return classicPosition(s).BestMove()
+	}
+	search, _ := engine.New("alphabeta")
+	return search.Move(s)
+}
+
+// moveCell is the cell move puts a mark on in s.
+func moveCell(s variant.State, move int) int {
+	if g, ok := gravityOf(s); ok {
+		return g.Drop(move)
+	}
+	if _, ok := s.(variant.MarkChooser); ok {
+		return move % len(s.Cells())
+	}
+	return move
+}
+
+// requestHint asks the engine for a move when it is the player's turn in a
+// game that allows hints.
+func (m roomModel) requestHint() (roomModel, tea.Cmd) {
+	if m.role == RoleSpectator || !m.gameStarted || m.gameOver ||
+		m.board.Turn() != m.role.Mark() || !hintsAllowed(m.room.Settings()) {
+		return m, nil
+	}
+	m.room.NoteHint()
+	board, moves := m.board.Clone(), len(m.sequence)
+	return m, func() tea.Msg {
+		return hintMsg{moves: moves, cell: moveCell(board, bestMove(board))}
+	}
+}
+
+// requestAnalysis starts analysing the game once it is over.
+func (m roomModel) requestAnalysis() (roomModel, tea.Cmd) {
+	if !m.gameOver || m.analysing || len(m.sequence) == 0 {
+		return m, nil
+	}
+	m.analysing = true
+	settings, sequence := m.room.Settings(), m.sequence
+	return m, func() tea.Msg {
+		return analysisMsg{analysis: analyseGame(settings, sequence)}
+	}
+}
+
+type moveGrade int
+
+const (
+	gradeBest moveGrade = iota
+	gradeInaccuracy
+	gradeBlunder
+)
+
+func (g moveGrade) String() string {
+	switch g {
+	case gradeInaccuracy:
+		return "inaccuracy"
+	case gradeBlunder:
+		return "blunder"
+	}
+	return "best"
+}
+
+// scoredMove is a legal move as the analysis sees it. Score ranks moves;
+// outcome is 1 for a forced win, -1 for a forced loss and 0 for a draw or
+// a result the search could not see. label describes a known result.
+type scoredMove struct {
+	move, score, outcome int
+	label                string
+}
+
+// scoreMoves rates every legal move in s for the side to move. Classic
+// positions come from the tablebase, so their results are exact.
+func scoreMoves(s variant.State) []scoredMove {
+	if _, ok := s.(*variant.Classic); ok {
+		if evals := classicPosition(s).MoveEvals(); evals != nil {
+			scored := make([]scoredMove, len(evals))
+			for i, e := range evals {
+				sc := scoredMove{move: e.Move, outcome: int(e.Outcome), label: e.String()}
+				switch e.Outcome {
+				case ttt.Win:
+					sc.score = 100 - e.Plies
+				case ttt.Loss:
+					sc.score = -100 + e.Plies
+				}
+				scored[i] = sc
+			}
+			return scored
+		}
+	}
+
+	search := engine.AlphaBeta{MaxDepth: analysisDepth, MaxNodes: analysisNodes}
+	scores := search.Scores(s)
+	scored := make([]scoredMove, len(scores))
+	for i, sc := range scores {
+		scored[i] = scoredMove{move: sc.Move, score: sc.Score, outcome: sc.Outcome()}
+		switch sc.Outcome() {
+		case 1:
+			scored[i].label = "forced win"
+		case -1:
+			scored[i].label = "forced loss"
+		}
+	}
+	return scored
+}
+
+// analysedMove is one move of a finished game: the cell it was played on,
+// the cell the engine preferred and how much worse the move was.
+type analysedMove struct {
+	side             rune
+	cell, bestCell   int
+	grade            moveGrade
+	label, bestLabel string
+}
+
+// gameAnalysis walks through a finished game; positions[i] is the board
+// after moves[i].
+type gameAnalysis struct {
+	moves     []analysedMove
+	positions []variant.State
+	step      int
+}
+
+// analyseGame replays sequence from the start, grading each move against
+// the engine's best: a move that throws away a result is a blunder, one
+// that only scores lower an inaccuracy.
+func analyseGame(settings RoomSettings, sequence []int) *gameAnalysis {
+	board := newBoard(settings)
+	a := &gameAnalysis{}
+	for _, move := range sequence {
+		scores := scoreMoves(board)
+		if len(scores) == 0 {
+			break
+		}
+		best, played := scores[0], scores[0]
+		for _, sc := range scores {
+			if sc.score > best.score {
+				best = sc
+			}
+			if sc.move == move {
+				played = sc
+			}
+		}
+
+		slack := analysisSlack
+		if _, ok := board.(*variant.Classic); ok {
+			slack = 0
+		}
+		grade := gradeBest
+		switch {
+		case played.outcome < best.outcome:
+			grade = gradeBlunder
+		case played.score < best.score-slack:
+			grade = gradeInaccuracy
+		}
+		am := analysedMove{
+			side: board.Turn(), cell: moveCell(board, move), bestCell: moveCell(board, best.move),
+			grade: grade, label: played.label, bestLabel: best.label,
+		}
+		if board.Play(move) != nil {
+			break
+		}
+		a.moves = append(a.moves, am)
+		a.positions = append(a.positions, board.Clone())
+	}
+	return a
+}
+
+// handleAnalysisInput steps through the analysed game with ←/→ and closes
+// it with a or esc.
+func (m roomModel) handleAnalysisInput(msg tea.KeyMsg) (roomModel, tea.Cmd) {
+	a := *m.analysis
+	switch msg.String() {
+	case "left", "h":
+		a.step = max(0, a.step-1)
+	case "right", "l":
+		a.step = min(len(a.moves)-1, a.step+1)
+	case "home":
+		a.step = 0
+	case "end":
+		a.step = len(a.moves) - 1
+	case "a", "esc":
+		m.analysis = nil
+		return m, nil
+	}
+	m.analysis = &a
+	return m, nil
+}
+
+var gradeStyles = map[moveGrade]lipgloss.Style{
+	gradeBest:       lipgloss.NewStyle().Foreground(lipgloss.Color("2")),
+	gradeInaccuracy: lipgloss.NewStyle().Foreground(lipgloss.Color("3")),
+	gradeBlunder:    lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Bold(true),
+}
+
+// viewAnalysis draws the position after the selected move with that move
+// highlighted, what the engine made of it and a tally for each side.
+func (m roomModel) viewAnalysis() string {
+	a := m.analysis
+	if len(a.moves) == 0 {
+		return "  Nothing to analyse.\n"
+	}
+	am := a.moves[a.step]
+
+	v := m
+	v.board, v.focus, v.hint = a.positions[a.step], paneChat, am.cell
+	var b strings.Builder
+	b.WriteString(v.viewBoard() + "\n")
+
+	grade := am.grade.String()
+	if am.label != "" {
+		grade += " (" + am.label + ")"
+	}
+	b.WriteString(fmt.Sprintf("  Move %d of %d: %c on %s, %s\n", a.step+1, len(a.moves),
+		am.side, cellName(m.board, am.cell), gradeStyles[am.grade].Render(grade)))
+	if am.grade != gradeBest {
+		best := "best was " + cellName(m.board, am.bestCell)
+		if am.bestLabel != "" {
+			best += " (" + am.bestLabel + ")"
+		}
+		b.WriteString("  " + best + "\n")
+	}
+
+	for _, side := range []rune{'X', 'O'} {
+		var counts [3]int
+		for _, mv := range a.moves {
+			if mv.side == side {
+				counts[mv.grade]++
+			}
+		}
+		b.WriteString(fmt.Sprintf("\n  %c: %d best, %d inaccurate, %d blundered", side,
+			counts[gradeBest], counts[gradeInaccuracy], counts[gradeBlunder]))
+	}
+	return b.String() + "\n"
+}
+
+// cellName describes a cell of s for the analysis text.
+func cellName(s variant.State, cell int) string {
+	if cell < 0 {
+		return "nowhere"
+	}
+	if _, ok := s.(*variant.Ultimate); ok {
+		return fmt.Sprintf("%s of the %s board", squareNames[cell%9], squareNames[cell/9])
+	}
+	layout := layoutOf(s)
+	if layout == (boardLayout{layers: 1, cols: 3, rows: 3}) {
+		return squareNames[cell]
+	}
+	per := layout.cols * layout.rows
+	row, col := cell%per/layout.cols+1, cell%layout.cols+1
+	if layout.layers > 1 {
+		return fmt.Sprintf("%s %d row %d column %d", strings.ToLower(layout.label), cell/per+1, row, col)
+	}
+	return fmt.Sprintf("row %d column %d", row, col)
+}
+
+// ── HTTP API ─────────────────────────────────────────────────────────────────
+
+// positionEval is what the API makes of a classic position: its result
+// with perfect play for the side to move and each move's.
+type positionEval struct {
+	Position string     `json:"position"`
+	Over     bool       `json:"over"`
+	Winner   string     `json:"winner,omitempty"`
+	Outcome  string     `json:"outcome,omitempty"`
+	Moves    []moveEval `json:"moves,omitempty"`
+}
+
+type moveEval struct {
+	Move string `json:"move"`
+	Eval string `json:"eval"`
+}
+
+// positionRoutes evaluates classic positions given in position notation,
+// e.g. GET /api/position?p=x1o/1x1/3+o.
+func positionRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/position", func(w http.ResponseWriter, r *http.Request) {
+		p, err := ttt.ParsePosition(r.URL.Query().Get("p"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		eval := positionEval{Position: p.Notation(), Over: p.IsGameEnd()}
+		for _, side := range []string{"x", "o"} {
+			if p.IsWinFor(side) {
+				eval.Winner = strings.ToUpper(side)
+			}
+		}
+		if outcome, plies, ok := p.Solved(); ok && !eval.Over {
+			eval.Outcome = ttt.MoveEval{Outcome: outcome, Plies: plies}.String()
+		}
+		s, err := p.State()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for _, e := range p.MoveEvals() {
+			eval.Moves = append(eval.Moves, moveEval{Move: ttt.FormatMove(s, e.Move), Eval: e.String()})
+		}
+		writeJSON(w, eval)
+	})
+}
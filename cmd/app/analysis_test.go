@@ -0,0 +1,43 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAnalyseGame(t *testing.T) {
+	tests := []struct {
+		name     string
+		sequence []int
+		want     []moveGrade
+	}{
+		{"sound opening", []int{4, 0}, []moveGrade{gradeBest, gradeBest}},
+		{"an edge reply to the centre loses", []int{4, 1}, []moveGrade{gradeBest, gradeBlunder}},
+		{"a corner reply to the double corner loses", []int{0, 4, 8, 2}, []moveGrade{gradeBest, gradeBest, gradeBest, gradeBlunder}},
+		{"a slower win is an inaccuracy", []int{0, 1, 4, 2, 3}, []moveGrade{gradeBest, gradeBlunder, gradeBest, gradeInaccuracy, gradeInaccuracy}},
+		{"the fastest win is best", []int{0, 1, 4, 2, 8}, []moveGrade{gradeBest, gradeBlunder, gradeBest, gradeInaccuracy, gradeBest}},
+		{"stops at an illegal move", []int{4, 4, 0}, []moveGrade{gradeBest}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := analyseGame(DefaultRoomSettings(), tt.sequence)
+			var got []moveGrade
+			for _, m := range a.moves {
+				got = append(got, m.grade)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if len(a.positions) != len(a.moves) {
+				t.Errorf("got %d positions for %d moves", len(a.positions), len(a.moves))
+			}
+		})
+	}
+
+	t.Run("suggests the move that keeps the draw", func(t *testing.T) {
+		a := analyseGame(DefaultRoomSettings(), []int{0, 4, 8, 2})
+		if got := a.moves[3]; got.bestCell != 1 || got.bestLabel != "draw" {
+			t.Errorf("got best cell %d (%s), want 1 (draw)", got.bestCell, got.bestLabel)
+		}
+	})
+}
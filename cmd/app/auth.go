@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Admins & Bans
+// ─────────────────────────────────────────────────────────────────────────────
+
+// loadAuthorizedKeys parses an authorized_keys style file. A missing file
+// yields no keys; blank lines and lines starting with # are ignored, and
+// any other line that isn't a key is an error naming its line number.
+func loadAuthorizedKeys(path string) ([]ssh.PublicKey, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var keys []ssh.PublicKey
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(line))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, scanner.Err()
+}
+
+// IsAdmin reports whether the session authenticated with an admin key.
+func (s *SharedState) IsAdmin(key ssh.PublicKey) bool {
+	if key == nil {
+		return false
+	}
+	for _, k := range s.Admins {
+		if ssh.KeysEqual(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// banMiddleware turns away users an admin has banned, by name or by the
+// public key they logged in with. Keys of users who get in are remembered
+// so a later ban covers them too.
+func banMiddleware(store *SQLiteStore) wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(sess ssh.Session) {
+			var fingerprint string
+			if key := sess.PublicKey(); key != nil {
+				fingerprint = gossh.FingerprintSHA256(key)
+			}
+			if store.HasSanction(sess.User(), sanctionBan) || store.KeyBanned(fingerprint) {
+				wish.Fatalln(sess, "You have been banned from this server.")
+				return
+			}
+			if fingerprint != "" {
+				if err := store.RecordKey(sess.User(), fingerprint); err != nil {
+					log.Error("record key", "user", sess.User(), "error", err)
+				}
+			}
+			next(sess)
+		}
+	}
+}
+
+// RecordKey remembers that name logged in with the key with the given
+// fingerprint.
+func (s *SQLiteStore) RecordKey(name, fingerprint string) error {
+	defer storeTimer("record_key")()
+
+	_, err := s.db.Exec(`
+		INSERT INTO user_keys (name, fingerprint) VALUES (?, ?)
+		ON CONFLICT(name, fingerprint) DO UPDATE SET last_seen = CURRENT_TIMESTAMP
+	`, name, fingerprint)
+	return err
+}
+
+// KeyBanned reports whether the key with the given fingerprint belongs to
+// a banned user.
+func (s *SQLiteStore) KeyBanned(fingerprint string) bool {
+	if fingerprint == "" {
+		return false
+	}
+	defer storeTimer("key_banned")()
+
+	var n int
+	_ = s.db.QueryRow("SELECT COUNT(*) FROM key_bans WHERE fingerprint = ?", fingerprint).Scan(&n)
+	return n > 0
+}
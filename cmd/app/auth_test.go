@@ -0,0 +1,97 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func authorizedKey(t *testing.T) string {
+	t.Helper()
+	pub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := gossh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(string(gossh.MarshalAuthorizedKey(key)))
+}
+
+func TestLoadAuthorizedKeys(t *testing.T) {
+	a, b := authorizedKey(t), authorizedKey(t)
+	tests := []struct {
+		name    string
+		content string
+		keys    int
+		err     string
+	}{
+		{"keys with comments and blank lines", "# admins\n" + a + " ann@laptop\n\n" + b + "\n", 2, ""},
+		{"an empty file", "", 0, ""},
+		{"a bad line names its line number", a + "\n# bob\nnot a key\n" + b + "\n", 0, "admins:3:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "admins")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			keys, err := loadAuthorizedKeys(path)
+			switch {
+			case tt.err == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Fatalf("got error %v, want one containing %q", err, tt.err)
+			}
+			if len(keys) != tt.keys {
+				t.Errorf("got %d keys, want %d", len(keys), tt.keys)
+			}
+		})
+	}
+
+	t.Run("a missing file has no keys", func(t *testing.T) {
+		keys, err := loadAuthorizedKeys(filepath.Join(t.TempDir(), "missing"))
+		if err != nil || keys != nil {
+			t.Errorf("got %v, %v; want no keys and no error", keys, err)
+		}
+	})
+}
+
+func TestKeyBans(t *testing.T) {
+	store := newTestStore(t)
+	if err := store.RecordKey("eve", "SHA256:eve"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RecordKey("bob", "SHA256:bob"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.SetSanction("eve", sanctionMute, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	if store.KeyBanned("SHA256:eve") {
+		t.Error("a mute banned the key")
+	}
+
+	if err := store.SetSanction("eve", sanctionBan, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	if !store.KeyBanned("SHA256:eve") {
+		t.Error("banning eve did not ban the key eve used")
+	}
+	if store.KeyBanned("SHA256:bob") || store.KeyBanned("") {
+		t.Error("the ban covered keys that aren't eve's")
+	}
+
+	if err := store.ClearSanction("eve", sanctionBan); err != nil {
+		t.Fatal(err)
+	}
+	if store.KeyBanned("SHA256:eve") {
+		t.Error("unbanning eve left the key banned")
+	}
+}
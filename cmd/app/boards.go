@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jwc20/ssh-ttt/variant"
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Board Layouts (room model)
+// ─────────────────────────────────────────────────────────────────────────────
+
+// boardLayout is how a board is drawn and how the cursor maps onto it:
+// layers grids of cols×rows cells, side by side. Ultimate is drawn as a
+// single 9×9 grid of its own.
+type boardLayout struct {
+	layers     int
+	cols, rows int
+	// label names one grid of a layered board.
+	label string
+}
+
+func layoutOf(s variant.State) boardLayout {
+	switch board := s.(type) {
+	case *variant.Ultimate:
+		return boardLayout{layers: 1, cols: 9, rows: 9}
+	case *variant.Qubic:
+		return boardLayout{layers: board.Size(), cols: board.Size(), rows: board.Size(), label: "Layer"}
+	case *variant.Notakto:
+		return boardLayout{layers: board.Boards(), cols: 3, rows: 3, label: "Board"}
+	case *variant.OrderChaos:
+		return boardLayout{layers: 1, cols: 6, rows: 6}
+	case *variant.Grid:
+		return boardLayout{layers: 1, cols: board.Options().Cols, rows: board.Options().Rows}
+	default:
+		return boardLayout{layers: 1, cols: 3, rows: 3}
+	}
+}
+
+// cursorMove is the move under the cursor, placing the chosen mark in
+// variants that let players choose.
+func (m roomModel) cursorMove() int {
+	if _, ok := m.board.(*variant.Ultimate); ok {
+		sub := (m.cursorRow/3)*3 + m.cursorCol/3
+		return sub*9 + (m.cursorRow%3)*3 + m.cursorCol%3
+	}
+	if _, ok := gravityOf(m.board); ok {
+		return m.cursorCol
+	}
+	layout := layoutOf(m.board)
+	cell := (m.cursorLayer*layout.rows+m.cursorRow)*layout.cols + m.cursorCol
+	if c, ok := m.board.(variant.MarkChooser); ok {
+		return c.MoveFor(cell, m.placeMark)
+	}
+	return cell
+}
+
+var (
+	layerGap  = lipgloss.NewStyle().MarginRight(3)
+	deadLayer = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+)
+
+// viewLayers draws the grids of a layered board side by side; the cursor
+// only shows on the grid it is on. Dead Notakto boards are greyed out.
+func (m roomModel) viewLayers(layout boardLayout) string {
+	n := layout.cols
+	cells := m.board.Cells()
+	notakto, _ := m.board.(*variant.Notakto)
+
+	grids := make([]string, layout.layers)
+	for l := range layout.layers {
+		title := fmt.Sprintf("%s %d", layout.label, l+1)
+		cursor := -1
+		if l == m.cursorLayer {
+			title = focusLabel.Render(title)
+			if m.focus == paneGame {
+				cursor = m.cursorRow*n + m.cursorCol
+			}
+		}
+		hint := m.hint - l*n*n
+		if hint >= n*n {
+			hint = -1
+		}
+		grid := renderGrid(cells[l*n*n:(l+1)*n*n], n, n, cursor, hint, "")
+		if notakto != nil && notakto.Dead(l) {
+			title += deadLayer.Render(" (dead)")
+			grid = deadLayer.Render(grid)
+		}
+		grids[l] = lipgloss.JoinVertical(lipgloss.Left, title, grid)
+		if l < layout.layers-1 {
+			grids[l] = layerGap.Render(grids[l])
+		}
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, grids...)
+}
+
+// gravityOf returns the board when marks drop down its columns.
+func gravityOf(s variant.State) (*variant.Grid, bool) {
+	g, ok := s.(*variant.Grid)
+	return g, ok && g.Options().Gravity
+}
+
+// handleDropInput takes column-only input: a digit drops straight into
+// that column (0 is the tenth), ←/→ pick a column and enter drops into it.
+func (m roomModel) handleDropInput(g *variant.Grid, msg tea.KeyMsg) (roomModel, tea.Cmd) {
+	cols := g.Options().Cols
+	key := msg.String()
+	switch {
+	case key == "left" || key == "h":
+		m.cursorCol = max(0, m.cursorCol-1)
+	case key == "right" || key == "l":
+		m.cursorCol = min(cols-1, m.cursorCol+1)
+	case key == "enter" || key == " ":
+		m.drop()
+	case len(key) == 1 && key[0] >= '0' && key[0] <= '9':
+		col := int(key[0]-'0') - 1
+		if col < 0 {
+			col = 9
+		}
+		if col < cols {
+			m.cursorCol = col
+			m.drop()
+		}
+	}
+	return m, nil
+}
+
+// dropKeysHelp names the keys that drop into a board's columns. Digits
+// only reach the first ten, so wider boards need ←/→ for the rest.
+func dropKeysHelp(cols int) string {
+	switch {
+	case cols < 10:
+		return fmt.Sprintf("1-%d: drop  ←/→: column", cols)
+	case cols == 10:
+		return "1-9,0: drop  ←/→: column"
+	}
+	return fmt.Sprintf("1-9,0: drop  ←/→: column (11-%d)", cols)
+}
+
+func (m roomModel) drop() {
+	if m.role != RoleSpectator && m.gameStarted && !m.gameOver {
+		m.room.HandleMove(m.sessID, m.cursorCol)
+	}
+}
+
+// viewDrop draws a gravity board with the cell the next mark would land on
+// highlighted and the column numbers underneath.
+func (m roomModel) viewDrop(g *variant.Grid) string {
+	opts := g.Options()
+	cursor := -1
+	if m.focus == paneGame {
+		cursor = g.Drop(m.cursorCol)
+	}
+	labels := make([]string, opts.Cols)
+	for col := range opts.Cols {
+		labels[col] = fmt.Sprintf(" %d ", (col+1)%10)
+		if col == m.cursorCol && m.focus == paneGame {
+			labels[col] = focusLabel.Render(labels[col])
+		}
+	}
+	return renderGrid(g.Cells(), opts.Cols, opts.Rows, cursor, m.hint, "  ") + "  " + strings.Join(labels, " ") + "\n"
+}
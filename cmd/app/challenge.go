@@ -0,0 +1,324 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Challenges
+// ─────────────────────────────────────────────────────────────────────────────
+
+const challengeTTL = 60 * time.Second
+
+// A user may send challengeRateBurst challenges at once and regains one
+// every challengeRateInterval.
+const (
+	challengeRateBurst    = 3
+	challengeRateInterval = 20 * time.Second
+)
+
+type Challenge struct {
+	ID       string
+	From     string
+	To       string
+	Settings RoomSettings
+	Expires  time.Time
+
+	fromSess string
+	timer    *time.Timer
+}
+
+type (
+	ChallengeMsg       struct{ Challenge Challenge }
+	ChallengeClosedMsg struct {
+		ID     string
+		Reason string
+	}
+	// challengeTickMsg redraws the invite countdown.
+	challengeTickMsg struct{}
+)
+
+var challengeSeq atomic.Uint64
+
+var (
+	errSelfChallenge = errors.New("you can't challenge yourself")
+	errNotChallenged = errors.New("that challenge isn't for you")
+	errChallengeOpen = errors.New("a challenge between you is already waiting for an answer")
+	errChallengeRate = errors.New("slow down, you are sending challenges too fast")
+)
+
+// SendChallenge invites an online user to a game. The invitation is shown on
+// every session of the recipient and expires after challengeTTL. Only one
+// challenge may be pending between two users, in either direction, and
+// senders are rate limited.
+func (s *SharedState) SendChallenge(fromSess, to string, settings RoomSettings) (Challenge, error) {
+	s.sessMu.RLock()
+	from, ok := s.sessions[fromSess]
+	var targets []*tea.Program
+	for _, sess := range s.sessions {
+		if sess.UserID == to {
+			targets = append(targets, sess.Program)
+		}
+	}
+	s.sessMu.RUnlock()
+
+	switch {
+	case !ok:
+		return Challenge{}, errUserOffline
+	case from.UserID == to:
+		return Challenge{}, errSelfChallenge
+	case len(targets) == 0:
+		return Challenge{}, fmt.Errorf("%s: %w", to, errUserOffline)
+	}
+
+	c := &Challenge{
+		ID:       fmt.Sprintf("c%d", challengeSeq.Add(1)),
+		From:     from.UserID,
+		To:       to,
+		Settings: settings,
+		Expires:  time.Now().Add(challengeTTL),
+		fromSess: fromSess,
+	}
+
+	s.challengeMu.Lock()
+	for _, open := range s.challenges {
+		if (open.From == c.From && open.To == c.To) || (open.From == c.To && open.To == c.From) {
+			s.challengeMu.Unlock()
+			return Challenge{}, errChallengeOpen
+		}
+	}
+	if !s.limits.challenges.Allow(c.From) {
+		s.challengeMu.Unlock()
+		return Challenge{}, errChallengeRate
+	}
+	s.challenges[c.ID] = c
+	c.timer = time.AfterFunc(challengeTTL, func() {
+		s.closeChallenge(c.ID, "expired")
+	})
+	s.challengeMu.Unlock()
+
+	for _, p := range targets {
+		go p.Send(ChallengeMsg{Challenge: *c})
+	}
+	return *c, nil
+}
+
+// RespondChallenge accepts or declines a pending challenge. Accepting creates
+// a room with the seats reserved and moves both players into it.
+// Only the challenged user's sessions may respond.
+func (s *SharedState) RespondChallenge(id, sessID string, accept bool) error {
+	s.sessMu.RLock()
+	sess, online := s.sessions[sessID]
+	s.sessMu.RUnlock()
+
+	s.challengeMu.Lock()
+	c, ok := s.challenges[id]
+	if ok && (!online || sess.UserID != c.To) {
+		s.challengeMu.Unlock()
+		return errNotChallenged
+	}
+	if ok {
+		delete(s.challenges, id)
+		c.timer.Stop()
+	}
+	s.challengeMu.Unlock()
+	if !ok {
+		return errors.New("challenge is no longer available")
+	}
+
+	if !accept {
+		s.notifyChallenge(c, "declined by "+c.To)
+		return nil
+	}
+
+	room, err := s.Rooms.CreateUnique(fmt.Sprintf("%s-vs-%s", c.From, c.To), c.Settings)
+	if err != nil {
+		s.notifyChallenge(c, "failed: "+err.Error())
+		return err
+	}
+	room.Reserve(c.From, c.To)
+	s.BroadcastLobby()
+	s.notifyChallenge(c, "accepted by "+c.To)
+
+	join := JoinRoomMsg{RoomID: room.ID}
+	s.sessMu.RLock()
+	defer s.sessMu.RUnlock()
+	for sid, sess := range s.sessions {
+		if sid == c.fromSess || sid == sessID {
+			p := sess.Program
+			go p.Send(join)
+		}
+	}
+	return nil
+}
+
+func (s *SharedState) closeChallenge(id, reason string) {
+	s.challengeMu.Lock()
+	c, ok := s.challenges[id]
+	delete(s.challenges, id)
+	s.challengeMu.Unlock()
+	if ok {
+		s.notifyChallenge(c, reason)
+	}
+}
+
+// notifyChallenge tells the challenger's and recipient's sessions that the
+// challenge is closed.
+func (s *SharedState) notifyChallenge(c *Challenge, reason string) {
+	msg := ChallengeClosedMsg{ID: c.ID, Reason: reason}
+	s.sessMu.RLock()
+	defer s.sessMu.RUnlock()
+	for sid, sess := range s.sessions {
+		if sid == c.fromSess || sess.UserID == c.To {
+			p := sess.Program
+			go p.Send(msg)
+		}
+	}
+}
+
+// dropChallenges cancels challenges sent from a session that disconnected.
+func (s *SharedState) dropChallenges(sessID string) {
+	s.challengeMu.Lock()
+	var dropped []*Challenge
+	for id, c := range s.challenges {
+		if c.fromSess == sessID {
+			c.timer.Stop()
+			delete(s.challenges, id)
+			dropped = append(dropped, c)
+		}
+	}
+	s.challengeMu.Unlock()
+
+	for _, c := range dropped {
+		s.notifyChallenge(c, "cancelled, "+c.From+" left")
+	}
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Challenge Form (lobby) & Banner (root)
+// ─────────────────────────────────────────────────────────────────────────────
+
+type challengeField int
+
+const (
+	fieldOpponent challengeField = iota
+	fieldVariant
+	fieldTimeControl
+)
+
+type challengeForm struct {
+	users    []OnlineUser
+	userID   string
+	opponent int
+	variant  int
+	clock    int
+	field    challengeField
+}
+
+func (f challengeForm) opponents() []string {
+	seen := map[string]bool{f.userID: true}
+	var names []string
+	for _, u := range f.users {
+		if !seen[u.Name] {
+			seen[u.Name] = true
+			names = append(names, u.Name)
+		}
+	}
+	return names
+}
+
+func (f challengeForm) settings() RoomSettings {
+	return RoomSettings{Variant: roomVariants[f.variant], TimeControl: timeControls[f.clock]}
+}
+
+func (f challengeForm) Update(msg tea.KeyMsg) challengeForm {
+	step := func(i, delta, n int) int { return ((i+delta)%n + n) % n }
+
+	switch msg.String() {
+	case "up", "k":
+		f.field = challengeField(step(int(f.field), -1, 3))
+	case "down", "j":
+		f.field = challengeField(step(int(f.field), 1, 3))
+	case "left", "h", "right", "l":
+		delta := 1
+		if s := msg.String(); s == "left" || s == "h" {
+			delta = -1
+		}
+		switch f.field {
+		case fieldOpponent:
+			if n := len(f.opponents()); n > 0 {
+				f.opponent = step(f.opponent, delta, n)
+			}
+		case fieldVariant:
+			f.variant = step(f.variant, delta, len(roomVariants))
+		case fieldTimeControl:
+			f.clock = step(f.clock, delta, len(timeControls))
+		}
+	}
+	return f
+}
+
+func (f challengeForm) View() string {
+	opponent := "(nobody online)"
+	if names := f.opponents(); len(names) > 0 {
+		opponent = names[min(f.opponent, len(names)-1)]
+	}
+
+	rows := []struct {
+		field challengeField
+		label string
+		value string
+	}{
+		{fieldOpponent, "Opponent", opponent},
+		{fieldVariant, "Variant", roomVariants[f.variant]},
+		{fieldTimeControl, "Clock", timeControls[f.clock].String()},
+	}
+
+	var b strings.Builder
+	for _, r := range rows {
+		line := fmt.Sprintf("%-9s ‹ %s ›", r.label, r.value)
+		if r.field == f.field {
+			b.WriteString(lobbySelectedItem.Render("▸ "+line) + "\n")
+		} else {
+			b.WriteString(lobbyItemStyle.Render("  "+line) + "\n")
+		}
+	}
+	return b.String()
+}
+
+var challengeModal = lipgloss.NewStyle().
+	Border(lipgloss.DoubleBorder()).
+	BorderForeground(lipgloss.Color("170")).
+	Padding(1, 3)
+
+var challengeBanner = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("0")).
+	Background(lipgloss.Color("170")).
+	Bold(true).
+	Padding(0, 1)
+
+// challengeTick redraws the invite banner every second so its countdown
+// keeps moving.
+func challengeTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return challengeTickMsg{} })
+}
+
+// viewChallengeBanner shows the oldest invite above whatever the player is
+// doing, so it never gets in the way of room or chat input. more is the
+// number of invites queued behind it.
+func viewChallengeBanner(c Challenge, more int) string {
+	left := max(time.Until(c.Expires), 0).Round(time.Second)
+	text := fmt.Sprintf("⚔ %s challenges you to %s, expires in %s  ctrl+y: accept  ctrl+n: decline  esc: dismiss",
+		c.From, c.Settings, left)
+	if more > 0 {
+		text += fmt.Sprintf("  (+%d more)", more)
+	}
+	return challengeBanner.Render(text)
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jwc20/ssh-ttt/config"
+)
+
+// newTestShared returns server state backed by a fresh store.
+func newTestShared(t *testing.T) *SharedState {
+	t.Helper()
+	store := newTestStore(t)
+	return NewSharedState(config.Default(), store, NewChatModerator(store, nil))
+}
+
+// discardProgram returns a program that drops every message sent to it.
+func discardProgram() *tea.Program {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return tea.NewProgram(nil, tea.WithContext(ctx), tea.WithInput(nil), tea.WithOutput(io.Discard))
+}
+
+func connect(s *SharedState, sessID, userID string) {
+	s.Connect(sessID, userID, "127.0.0.1", discardProgram())
+}
+
+func pendingChallenge(s *SharedState, id string) bool {
+	s.challengeMu.Lock()
+	defer s.challengeMu.Unlock()
+	_, ok := s.challenges[id]
+	return ok
+}
+
+func TestChallenges(t *testing.T) {
+	t.Run("a challenge expires after its time to live", func(t *testing.T) {
+		s := newTestShared(t)
+		connect(s, "s1", "ann")
+		connect(s, "s2", "bob")
+		c, err := s.SendChallenge("s1", "bob", DefaultRoomSettings())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := time.Now().Add(challengeTTL); c.Expires.After(want) || c.Expires.Before(want.Add(-time.Second)) {
+			t.Errorf("expires at %v, want about %v", c.Expires, want)
+		}
+
+		s.challengeMu.Lock()
+		s.challenges[c.ID].timer.Reset(0)
+		s.challengeMu.Unlock()
+		for deadline := time.Now().Add(time.Second); pendingChallenge(s, c.ID); {
+			if time.Now().After(deadline) {
+				t.Fatal("the challenge did not expire")
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		if err := s.RespondChallenge(c.ID, "s2", true); err == nil {
+			t.Error("accepted an expired challenge")
+		}
+		if len(s.Rooms.List()) != 0 {
+			t.Error("an expired challenge opened a room")
+		}
+	})
+
+	t.Run("accepting a challenge reserves both seats", func(t *testing.T) {
+		s := newTestShared(t)
+		connect(s, "s1", "ann")
+		connect(s, "s2", "bob")
+		c, err := s.SendChallenge("s1", "bob", DefaultRoomSettings())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.RespondChallenge(c.ID, "s2", true); err != nil {
+			t.Fatal(err)
+		}
+		if pendingChallenge(s, c.ID) {
+			t.Error("the challenge is still pending")
+		}
+		rooms := s.Rooms.List()
+		if len(rooms) != 1 {
+			t.Fatalf("got %d rooms, want 1", len(rooms))
+		}
+		r := s.Rooms.rooms[rooms[0].ID]
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+		if r.reserved != [2]string{"ann", "bob"} {
+			t.Errorf("got seats %v, want ann and bob", r.reserved)
+		}
+	})
+
+	t.Run("players can't challenge themselves or offline users", func(t *testing.T) {
+		s := newTestShared(t)
+		connect(s, "s1", "ann")
+		if _, err := s.SendChallenge("s1", "ann", DefaultRoomSettings()); err == nil {
+			t.Error("challenged themselves")
+		}
+		if _, err := s.SendChallenge("s1", "bob", DefaultRoomSettings()); err == nil {
+			t.Error("challenged an offline user")
+		}
+	})
+	t.Run("only one challenge may wait between two users", func(t *testing.T) {
+		s := newTestShared(t)
+		connect(s, "s1", "ann")
+		connect(s, "s2", "bob")
+		c, err := s.SendChallenge("s1", "bob", DefaultRoomSettings())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.SendChallenge("s1", "bob", DefaultRoomSettings()); !errors.Is(err, errChallengeOpen) {
+			t.Errorf("a second challenge got %v, want %v", err, errChallengeOpen)
+		}
+		if _, err := s.SendChallenge("s2", "ann", DefaultRoomSettings()); !errors.Is(err, errChallengeOpen) {
+			t.Errorf("a challenge back got %v, want %v", err, errChallengeOpen)
+		}
+		if err := s.RespondChallenge(c.ID, "s2", false); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.SendChallenge("s1", "bob", DefaultRoomSettings()); err != nil {
+			t.Errorf("could not challenge again after a decline: %v", err)
+		}
+	})
+	t.Run("senders are rate limited", func(t *testing.T) {
+		s := newTestShared(t)
+		connect(s, "s1", "ann")
+		var err error
+		for i := range challengeRateBurst + 1 {
+			to := fmt.Sprintf("user%d", i)
+			connect(s, "t"+to, to)
+			_, err = s.SendChallenge("s1", to, DefaultRoomSettings())
+			if i < challengeRateBurst && err != nil {
+				t.Fatalf("challenge %d failed: %v", i+1, err)
+			}
+		}
+		if !errors.Is(err, errChallengeRate) {
+			t.Errorf("got %v, want %v", err, errChallengeRate)
+		}
+	})
+	t.Run("only the challenged user can respond", func(t *testing.T) {
+		s := newTestShared(t)
+		connect(s, "s1", "ann")
+		connect(s, "s2", "bob")
+		connect(s, "s3", "eve")
+		c, err := s.SendChallenge("s1", "bob", DefaultRoomSettings())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, sessID := range []string{"s1", "s3", "gone"} {
+			if err := s.RespondChallenge(c.ID, sessID, true); !errors.Is(err, errNotChallenged) {
+				t.Errorf("session %s responded with %v, want %v", sessID, err, errNotChallenged)
+			}
+		}
+		if !pendingChallenge(s, c.ID) {
+			t.Fatal("a refused response closed the challenge")
+		}
+		if err := s.RespondChallenge(c.ID, "s2", false); err != nil {
+			t.Errorf("bob could not decline: %v", err)
+		}
+	})
+}
+
+func TestChallengeBanner(t *testing.T) {
+	invite := func(m tea.Model, id string) tea.Model {
+		m, _ = m.Update(ChallengeMsg{Challenge: Challenge{ID: id, From: "ann", To: "bob", Settings: DefaultRoomSettings(), Expires: time.Now().Add(challengeTTL)}})
+		return m
+	}
+
+	t.Run("the countdown ticks while an invite is up", func(t *testing.T) {
+		s := newTestShared(t)
+		m := invite(NewRootModel(s, "bob", "s2"), "c1")
+		m, cmd := m.Update(challengeTickMsg{})
+		if cmd == nil {
+			t.Error("the countdown stopped with an invite up")
+		}
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		if _, cmd = m.Update(challengeTickMsg{}); cmd != nil {
+			t.Error("the countdown kept ticking with no invites")
+		}
+	})
+	t.Run("esc dismisses the invite", func(t *testing.T) {
+		s := newTestShared(t)
+		m := invite(NewRootModel(s, "bob", "s2"), "c1")
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		if n := len(m.(rootModel).invites); n != 0 {
+			t.Errorf("got %d invites after esc, want 0", n)
+		}
+	})
+	t.Run("other keys still reach the lobby", func(t *testing.T) {
+		s := newTestShared(t)
+		m := invite(NewRootModel(s, "bob", "s2"), "c1")
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+		root := m.(rootModel)
+		if len(root.invites) != 1 {
+			t.Errorf("got %d invites, want the invite to stay up", len(root.invites))
+		}
+		if root.lobby.mode != lobbySearch {
+			t.Error("the key did not reach the lobby")
+		}
+	})
+}
@@ -0,0 +1,437 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+	"github.com/jwc20/ssh-ttt/limits"
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Chat Moderation
+// ─────────────────────────────────────────────────────────────────────────────
+
+const (
+	chatHistoryLimit = 50
+	chatRateBurst    = 5
+	chatRateInterval = 2 * time.Second
+)
+
+type chatKind int
+
+const (
+	chatSay chatKind = iota
+	chatEmote
+	chatWhisper
+	chatSystem
+)
+
+func (k chatKind) String() string {
+	switch k {
+	case chatEmote:
+		return "emote"
+	case chatWhisper:
+		return "whisper"
+	case chatSystem:
+		return "system"
+	default:
+		return "say"
+	}
+}
+
+type sanction string
+
+const (
+	sanctionMute sanction = "mute"
+	sanctionBan  sanction = "ban"
+)
+
+var (
+	errChatMuted   = errors.New("you are muted")
+	errChatTooFast = errors.New("slow down, you are sending messages too fast")
+)
+
+// ChatModerator holds the chat policy shared by every room: per-user rate
+// limits, the word filter and persistent mutes.
+type ChatModerator struct {
+	store   *SQLiteStore
+	limiter *limits.RateLimiter
+	filter  *regexp.Regexp
+}
+
+func NewChatModerator(store *SQLiteStore, words []string) *ChatModerator {
+	m := &ChatModerator{
+		store:   store,
+		limiter: limits.NewRateLimiter(chatRateBurst, chatRateInterval),
+	}
+
+	var quoted []string
+	for _, w := range words {
+		if w = strings.TrimSpace(w); w != "" {
+			quoted = append(quoted, regexp.QuoteMeta(w))
+		}
+	}
+	if len(quoted) > 0 {
+		m.filter = regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)\b`)
+	}
+
+	return m
+}
+
+// Allow reports whether userID may post a message right now.
+func (m *ChatModerator) Allow(userID string) error {
+	if m.store.HasSanction(userID, sanctionMute) {
+		return errChatMuted
+	}
+	if !m.limiter.Allow(userID) {
+		return errChatTooFast
+	}
+	return nil
+}
+
+// Filter masks every filtered word in text.
+func (m *ChatModerator) Filter(text string) string {
+	if m.filter == nil {
+		return text
+	}
+	return m.filter.ReplaceAllStringFunc(text, func(w string) string {
+		return strings.Repeat("*", len([]rune(w)))
+	})
+}
+
+// loadWordList reads one filtered word per line. A missing file means no
+// filter; blank lines and lines starting with # are ignored.
+func loadWordList(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			words = append(words, line)
+		}
+	}
+	return words, scanner.Err()
+}
+
+// ── Sanctions ────────────────────────────────────────────────────────────────
+
+// SetSanction mutes or bans name. A ban also covers every public key name
+// has logged in with, so picking a new user name doesn't get around it.
+func (s *SQLiteStore) SetSanction(name string, kind sanction, issuedBy string) error {
+	defer storeTimer("set_sanction")()
+
+	_, err := s.db.Exec(`
+		INSERT INTO sanctions (name, kind, issued_by) VALUES (?, ?, ?)
+		ON CONFLICT(name, kind) DO UPDATE SET issued_by = excluded.issued_by, created_at = CURRENT_TIMESTAMP
+	`, name, string(kind), issuedBy)
+	if err != nil || kind != sanctionBan {
+		return err
+	}
+	_, err = s.db.Exec(`
+		INSERT INTO key_bans (fingerprint, name, issued_by)
+		SELECT fingerprint, name, ? FROM user_keys WHERE name = ?
+		ON CONFLICT(fingerprint) DO NOTHING
+	`, issuedBy, name)
+	return err
+}
+
+func (s *SQLiteStore) ClearSanction(name string, kind sanction) error {
+	defer storeTimer("clear_sanction")()
+
+	_, err := s.db.Exec("DELETE FROM sanctions WHERE name = ? AND kind = ?", name, string(kind))
+	if err != nil || kind != sanctionBan {
+		return err
+	}
+	_, err = s.db.Exec("DELETE FROM key_bans WHERE name = ?", name)
+	return err
+}
+
+func (s *SQLiteStore) HasSanction(name string, kind sanction) bool {
+	defer storeTimer("has_sanction")()
+
+	var n int
+	_ = s.db.QueryRow("SELECT COUNT(*) FROM sanctions WHERE name = ? AND kind = ?", name, string(kind)).Scan(&n)
+	return n > 0
+}
+
+// ── Ignores ──────────────────────────────────────────────────────────────────
+
+// SetIgnore hides ignored's chat, whispers and direct messages from name.
+// Unlike sanctions, ignores are personal and need no admin.
+func (s *SQLiteStore) SetIgnore(name, ignored string) error {
+	defer storeTimer("set_ignore")()
+
+	_, err := s.db.Exec("INSERT INTO ignores (name, ignored) VALUES (?, ?) ON CONFLICT DO NOTHING", name, ignored)
+	return err
+}
+
+func (s *SQLiteStore) ClearIgnore(name, ignored string) error {
+	defer storeTimer("clear_ignore")()
+
+	_, err := s.db.Exec("DELETE FROM ignores WHERE name = ? AND ignored = ?", name, ignored)
+	return err
+}
+
+// Ignoring returns the users name has ignored.
+func (s *SQLiteStore) Ignoring(name string) map[string]bool {
+	return s.ignoreSet("ignoring", "SELECT ignored FROM ignores WHERE name = ?", name)
+}
+
+// IgnoredBy returns the users who have ignored name.
+func (s *SQLiteStore) IgnoredBy(name string) map[string]bool {
+	return s.ignoreSet("ignored_by", "SELECT name FROM ignores WHERE ignored = ?", name)
+}
+
+func (s *SQLiteStore) ignoreSet(op, query, name string) map[string]bool {
+	defer storeTimer(op)()
+
+	set := make(map[string]bool)
+	rows, err := s.db.Query(query, name)
+	if err != nil {
+		log.Error("load ignores", "user", name, "error", err)
+		return set
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var other string
+		if rows.Scan(&other) == nil {
+			set[other] = true
+		}
+	}
+	return set
+}
+
+// ignoreCommand runs /ignore or /unignore for userID and returns the
+// feedback to show them.
+func ignoreCommand(store *SQLiteStore, userID, cmd, target string) string {
+	if target == "" {
+		return fmt.Sprintf("usage: %s <user>", cmd)
+	}
+	if target == userID {
+		return "you can't ignore yourself"
+	}
+
+	if cmd == "/unignore" {
+		if err := store.ClearIgnore(userID, target); err != nil {
+			return fmt.Sprintf("%s failed: %v", cmd, err)
+		}
+		return fmt.Sprintf("you see messages from %s again", target)
+	}
+	if err := store.SetIgnore(userID, target); err != nil {
+		return fmt.Sprintf("%s failed: %v", cmd, err)
+	}
+	return fmt.Sprintf("you no longer see messages from %s", target)
+}
+
+// withoutIgnored drops the messages sent by ignored users from history.
+func withoutIgnored(history []RoomChatMsg, ignored map[string]bool) []RoomChatMsg {
+	var out []RoomChatMsg
+	for _, msg := range history {
+		if !ignored[msg.Sender] {
+			out = append(out, msg)
+		}
+	}
+	return out
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Room Chat
+// ─────────────────────────────────────────────────────────────────────────────
+
+const chatHelp = "commands: /me <action>  /whisper <user> <text>  /ignore /unignore <user>  /help" +
+	"  admin: /mute /unmute /ban /unban <user>"
+
+// HandleChat posts a message or runs a slash command on behalf of a client.
+// Errors and command feedback go only to the sender.
+func (r *Room) HandleChat(sessID, text string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	client, ok := r.clients[sessID]
+	if !ok {
+		return
+	}
+
+	if strings.HasPrefix(text, "/") {
+		r.runChatCommandLocked(client, text)
+		return
+	}
+
+	if err := r.moderator.Allow(client.UserID); err != nil {
+		client.notify(err.Error())
+		return
+	}
+	r.postLocked(RoomChatMsg{Sender: client.UserID, Text: r.moderator.Filter(text)})
+}
+
+func (r *Room) runChatCommandLocked(client *Client, text string) {
+	cmd, rest, _ := strings.Cut(text, " ")
+	rest = strings.TrimSpace(rest)
+
+	switch cmd {
+	case "/help":
+		client.notify(chatHelp)
+
+	case "/me":
+		if rest == "" {
+			client.notify("usage: /me <action>")
+			return
+		}
+		if err := r.moderator.Allow(client.UserID); err != nil {
+			client.notify(err.Error())
+			return
+		}
+		r.postLocked(RoomChatMsg{Sender: client.UserID, Text: r.moderator.Filter(rest), Kind: chatEmote})
+
+	case "/whisper", "/w":
+		to, body, _ := strings.Cut(rest, " ")
+		body = strings.TrimSpace(body)
+		if to == "" || body == "" {
+			client.notify("usage: /whisper <user> <text>")
+			return
+		}
+		if err := r.moderator.Allow(client.UserID); err != nil {
+			client.notify(err.Error())
+			return
+		}
+		r.whisperLocked(client, to, r.moderator.Filter(body))
+
+	case "/ignore", "/unignore":
+		client.notify(ignoreCommand(r.store, client.UserID, cmd, rest))
+
+	case "/mute", "/unmute", "/ban", "/unban":
+		r.moderateLocked(client, cmd, rest)
+
+	default:
+		client.notify(fmt.Sprintf("unknown command %s, try /help", cmd))
+	}
+}
+
+func (r *Room) whisperLocked(from *Client, to, text string) {
+	msg := RoomChatMsg{Sender: from.UserID, To: to, Text: text, Kind: chatWhisper}
+
+	// Someone ignoring the sender doesn't get the whisper, but the sender
+	// isn't told so.
+	ignored := r.store.IgnoredBy(from.UserID)[to]
+	delivered := false
+	for _, c := range r.clients {
+		if c.UserID == to {
+			if !ignored {
+				go c.Program.Send(msg)
+			}
+			delivered = true
+		}
+	}
+	if !delivered {
+		from.notify(fmt.Sprintf("%s is not in this room", to))
+		return
+	}
+	// Whispers are private: the log records who talked, not what was said.
+	events.Emit(evChat, "scope", "room", "room", r.ID, "user", from.UserID, "to", to, "kind", msg.Kind.String())
+	if from.UserID != to {
+		go from.Program.Send(msg)
+	}
+}
+
+func (r *Room) moderateLocked(admin *Client, cmd, target string) {
+	if !admin.Admin {
+		admin.notify(fmt.Sprintf("%s is for admins only", cmd))
+		return
+	}
+	if target == "" {
+		admin.notify(fmt.Sprintf("usage: %s <user>", cmd))
+		return
+	}
+
+	var (
+		err    error
+		notice string
+	)
+	switch cmd {
+	case "/mute":
+		err = r.store.SetSanction(target, sanctionMute, admin.UserID)
+		notice = fmt.Sprintf("%s was muted by %s", target, admin.UserID)
+	case "/unmute":
+		err = r.store.ClearSanction(target, sanctionMute)
+		notice = fmt.Sprintf("%s was unmuted by %s", target, admin.UserID)
+	case "/ban":
+		err = r.store.SetSanction(target, sanctionBan, admin.UserID)
+		notice = fmt.Sprintf("%s was banned by %s", target, admin.UserID)
+	case "/unban":
+		err = r.store.ClearSanction(target, sanctionBan)
+	}
+	if err != nil {
+		admin.notify(fmt.Sprintf("%s failed: %v", cmd, err))
+		return
+	}
+	r.store.Audit(admin.UserID, strings.TrimPrefix(cmd, "/"), target, "room "+r.ID)
+	if notice != "" {
+		r.postLocked(RoomChatMsg{Text: notice, Kind: chatSystem})
+	} else {
+		admin.notify(fmt.Sprintf("%s was unbanned", target))
+	}
+
+	if cmd == "/ban" {
+		for _, c := range r.clients {
+			if c.UserID == target {
+				go c.Program.Send(tea.QuitMsg{})
+			}
+		}
+	}
+}
+
+// postLocked broadcasts a public message and keeps it in the room history.
+func (r *Room) postLocked(msg RoomChatMsg) {
+	if msg.Sender != "" {
+		events.Emit(evChat, "scope", "room", "room", r.ID, "user", msg.Sender, "kind", msg.Kind.String(), "text", msg.Text)
+	}
+	r.history = append(r.history, msg)
+	if len(r.history) > chatHistoryLimit {
+		r.history = r.history[len(r.history)-chatHistoryLimit:]
+	}
+	if msg.Sender == "" {
+		r.broadcastLocked(msg)
+		return
+	}
+	ignoredBy := r.store.IgnoredBy(msg.Sender)
+	for _, c := range r.clients {
+		if !ignoredBy[c.UserID] {
+			p := c.Program
+			go p.Send(msg)
+		}
+	}
+}
+
+func (c *Client) notify(text string) {
+	go c.Program.Send(RoomChatMsg{Text: text, Kind: chatSystem})
+}
+
+// formatChat renders a chat message as a single chat log line.
+func formatChat(msg RoomChatMsg) string {
+	switch msg.Kind {
+	case chatEmote:
+		return fmt.Sprintf("* %s %s", msg.Sender, msg.Text)
+	case chatWhisper:
+		return fmt.Sprintf("[%s → %s] %s", msg.Sender, msg.To, msg.Text)
+	case chatSystem:
+		return "! " + msg.Text
+	default:
+		return fmt.Sprintf("%s: %s", msg.Sender, msg.Text)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/log"
+)
+
+func TestChatModeratorFilter(t *testing.T) {
+	m := NewChatModerator(nil, []string{"darn", " heck ", "", "a.b"})
+	tests := []struct {
+		name, text, want string
+	}{
+		{"clean text is unchanged", "good game", "good game"},
+		{"masks a filtered word", "darn it", "**** it"},
+		{"ignores case", "DARN, Heck!", "****, ****!"},
+		{"only masks whole words", "heckle darned", "heckle darned"},
+		{"treats words literally", "a.b axb", "*** axb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.Filter(tt.text); got != tt.want {
+				t.Errorf("Filter(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("an empty word list filters nothing", func(t *testing.T) {
+		if got := NewChatModerator(nil, nil).Filter("darn"); got != "darn" {
+			t.Errorf("got %q, want it unchanged", got)
+		}
+	})
+}
+
+// captureEvents sends the event log to a buffer for the rest of the test.
+func captureEvents(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prev := events
+	events = &EventLog{text: log.New(&buf)}
+	t.Cleanup(func() { events = prev })
+	return &buf
+}
+
+func TestWhispersStayPrivate(t *testing.T) {
+	t.Run("in a room", func(t *testing.T) {
+		logged := captureEvents(t)
+		r := NewRoom("quiet", DefaultRoomSettings(), newTestStore(t), nil)
+		r.Join("s1", "ann", discardProgram(), false)
+		r.Join("s2", "bob", discardProgram(), false)
+
+		r.mu.Lock()
+		r.whisperLocked(r.clients["s1"], "bob", "the secret plan")
+		r.mu.Unlock()
+
+		if !strings.Contains(logged.String(), "to=bob") {
+			t.Errorf("the whisper was not logged: %s", logged)
+		}
+		if strings.Contains(logged.String(), "secret") {
+			t.Errorf("the whisper's text was logged: %s", logged)
+		}
+	})
+
+	t.Run("between sessions", func(t *testing.T) {
+		logged := captureEvents(t)
+		s := newTestShared(t)
+		connect(s, "s1", "ann")
+		connect(s, "s2", "bob")
+
+		if err := s.SendDirect("s1", "bob", "the secret plan"); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(logged.String(), "to=bob") {
+			t.Errorf("the whisper was not logged: %s", logged)
+		}
+		if strings.Contains(logged.String(), "secret") {
+			t.Errorf("the whisper's text was logged: %s", logged)
+		}
+	})
+}
+
+func TestIgnores(t *testing.T) {
+	store := newTestStore(t)
+
+	if got := ignoreCommand(store, "ann", "/ignore", "ann"); got != "you can't ignore yourself" {
+		t.Errorf("ignoring yourself said %q", got)
+	}
+	ignoreCommand(store, "ann", "/ignore", "bob")
+	if !store.Ignoring("ann")["bob"] || !store.IgnoredBy("bob")["ann"] {
+		t.Fatal("ann's ignore of bob was not stored")
+	}
+	if store.IgnoredBy("ann")["bob"] {
+		t.Error("the ignore went both ways")
+	}
+
+	history := []RoomChatMsg{{Sender: "bob", Text: "hi"}, {Sender: "cat", Text: "hey"}, {Text: "bob joined", Kind: chatSystem}}
+	got := withoutIgnored(history, store.Ignoring("ann"))
+	if len(got) != 2 || got[0].Sender != "cat" {
+		t.Errorf("got %v, want bob's message dropped", got)
+	}
+
+	ignoreCommand(store, "ann", "/unignore", "bob")
+	if len(store.IgnoredBy("bob")) != 0 {
+		t.Error("unignoring bob left the ignore in place")
+	}
+}
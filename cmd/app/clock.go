@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jwc20/ssh-ttt/variant"
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Room Settings & Game Clocks
+// ─────────────────────────────────────────────────────────────────────────────
+
+// TimeControl is a Fischer clock: each side starts with Base and gains
+// Increment after every move. The zero value means untimed.
+type TimeControl struct {
+	Base      time.Duration
+	Increment time.Duration
+}
+
+func (tc TimeControl) Timed() bool { return tc.Base > 0 }
+
+func (tc TimeControl) String() string {
+	if !tc.Timed() {
+		return "untimed"
+	}
+	return fmt.Sprintf("%g+%g", tc.Base.Minutes(), tc.Increment.Seconds())
+}
+
+var timeControls = []TimeControl{
+	{},
+	{Base: 1 * time.Minute},
+	{Base: 3 * time.Minute, Increment: 2 * time.Second},
+	{Base: 5 * time.Minute},
+}
+
+// roomVariants lists the variants rooms can be created with, in the order
+// the dialogs cycle them.
+var roomVariants = variant.Names()
+
+// newBoard returns the starting position for a room's settings. Unknown
+// variants, e.g. from rooms saved before variants existed, get classic.
+func newBoard(settings RoomSettings) variant.State {
+	if s, err := variant.NewWith(settings.Variant, settings.Board); err == nil {
+		return s
+	}
+	return variant.NewClassic()
+}
+
+// variantRoutes serves the variants rooms can be created with.
+func variantRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/variants", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, variant.All())
+	})
+}
+
+type RoomSettings struct {
+	Variant string
+	// Board sizes variants that take options; zero means their defaults.
+	Board       variant.Options
+	TimeControl TimeControl
+	// Computer, when set, is the level of the computer player seated as O.
+	Computer computerLevel
+	// Engine names the engine the computer plays with; empty picks one for
+	// the variant.
+	Engine string
+}
+
+func DefaultRoomSettings() RoomSettings {
+	return RoomSettings{Variant: roomVariants[0]}
+}
+
+func (s RoomSettings) String() string {
+	name := s.Variant
+	if s.Board != (variant.Options{}) {
+		name = fmt.Sprintf("%s (%s)", s.Variant, s.Board)
+	}
+	if s.Computer != "" && s.Engine != "" {
+		return fmt.Sprintf("%s, %s, vs %s (%s)", name, s.TimeControl, s.Computer.Name(), s.Engine)
+	}
+	if s.Computer != "" {
+		return fmt.Sprintf("%s, %s, vs %s", name, s.TimeControl, s.Computer.Name())
+	}
+	return fmt.Sprintf("%s, %s", name, s.TimeControl)
+}
+
+type gameClock struct {
+	remaining [2]time.Duration
+	turnStart time.Time
+	timer     *time.Timer
+}
+
+func sideIndex(mark rune) int {
+	if mark == 'O' {
+		return 1
+	}
+	return 0
+}
+
+// startLocked marks the game as started and starts the side to move's
+// clock. Restored games keep the time they had left.
+func (r *Room) startLocked() {
+	r.started = true
+	defer r.scheduleComputerLocked()
+	tc := r.settings.TimeControl
+	if !tc.Timed() {
+		return
+	}
+	if r.game.MoveCount == 0 {
+		r.clock.remaining = [2]time.Duration{tc.Base, tc.Base}
+	}
+	r.clock.turnStart = time.Now()
+	r.armClockLocked()
+}
+
+func (r *Room) armClockLocked() {
+	if r.clock.timer != nil {
+		r.clock.timer.Stop()
+	}
+	if r.game.IsOver() {
+		return
+	}
+	left := r.clocksLocked()[sideIndex(r.game.CurrentTurn())]
+	r.clock.timer = time.AfterFunc(max(left, 0), r.checkFlag)
+}
+
+// clocksLocked returns both clocks with the side to move's running time
+// already deducted.
+func (r *Room) clocksLocked() [2]time.Duration {
+	clocks := r.clock.remaining
+	if r.settings.TimeControl.Timed() && r.started && !r.game.IsOver() {
+		clocks[sideIndex(r.game.CurrentTurn())] -= time.Since(r.clock.turnStart)
+	}
+	return clocks
+}
+
+// flaggedLocked ends the game if the side to move has run out of time.
+func (r *Room) flaggedLocked() bool {
+	if !r.settings.TimeControl.Timed() || !r.started || r.game.IsOver() {
+		return false
+	}
+	if r.clocksLocked()[sideIndex(r.game.CurrentTurn())] > 0 {
+		return false
+	}
+	r.clock.remaining[sideIndex(r.game.CurrentTurn())] = 0
+	r.game.Forfeit(r.game.CurrentTurn())
+	r.recordResult()
+	r.persistLocked()
+	r.broadcastLocked(r.gameSnapshot())
+	return true
+}
+
+// tickClockLocked charges the mover for their move and adds the increment.
+func (r *Room) tickClockLocked(mover rune) {
+	tc := r.settings.TimeControl
+	if !tc.Timed() {
+		return
+	}
+	r.clock.remaining[sideIndex(mover)] -= time.Since(r.clock.turnStart)
+	r.clock.remaining[sideIndex(mover)] += tc.Increment
+	r.clock.turnStart = time.Now()
+	r.armClockLocked()
+}
+
+func (r *Room) checkFlag() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.flaggedLocked() && r.started && !r.game.IsOver() {
+		r.armClockLocked()
+	}
+}
+
+// ── Client-side clock ticking ────────────────────────────────────────────────
+
+type clockTickMsg struct{}
+
+func clockTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return clockTickMsg{} })
+}
+
+func formatClock(d time.Duration) string {
+	d = max(d, 0).Round(time.Second)
+	return fmt.Sprintf("%d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/jwc20/ssh-ttt/engine"
+	"github.com/jwc20/ssh-ttt/variant"
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Computer Player
+// ─────────────────────────────────────────────────────────────────────────────
+
+type computerLevel string
+
+const (
+	computerEasy   computerLevel = "easy"
+	computerMedium computerLevel = "medium"
+	computerHard   computerLevel = "hard"
+)
+
+// computerLevels lists the opponents in the order the create dialog cycles
+// them; the empty level is a human opponent.
+var computerLevels = []computerLevel{"", computerEasy, computerMedium, computerHard}
+
+// computerEngines lists the engines the create dialog offers; the empty
+// name leaves the choice to chooseComputerMove.
+var computerEngines = append([]string{""}, engine.Names()...)
+
+// computerPrefix starts every computer player's name. SSH users can't log
+// in with it, so nobody can pose as the computer in the games table.
+const computerPrefix = "computer-"
+
+// computerDelay keeps the reply from landing before the player has seen
+// their own move.
+const computerDelay = 600 * time.Millisecond
+
+func (l computerLevel) Name() string { return computerPrefix + string(l) }
+
+// blunderRate is how often the level plays a random move instead of the
+// best one; unknown levels play like hard.
+func (l computerLevel) blunderRate() float64 {
+	if rate, ok := engine.BlunderRate(string(l)); ok {
+		return rate
+	}
+	rate, _ := engine.BlunderRate(string(computerHard))
+	return rate
+}
+
+func isComputer(name string) bool { return strings.HasPrefix(name, computerPrefix) }
+
+// scheduleComputerLocked has the computer reply after computerDelay when it
+// is its turn and someone is in the room to see it.
+func (r *Room) scheduleComputerLocked() {
+	if r.settings.Computer == "" || r.computerPending || len(r.clients) == 0 ||
+		!r.started || r.game.IsOver() || r.game.CurrentTurn() != 'O' {
+		return
+	}
+	r.computerPending = true
+	time.AfterFunc(computerDelay, r.computerMove)
+}
+
+// computerMove searches for the computer's reply on a copy of the board
+// so the room stays responsive while it thinks, then plays it only if the
+// game hasn't moved on in the meantime, e.g. through an admin reset.
+func (r *Room) computerMove() {
+	r.mu.Lock()
+	if len(r.clients) == 0 || !r.started || r.game.IsOver() || r.game.CurrentTurn() != 'O' || r.flaggedLocked() {
+		r.computerPending = false
+		r.mu.Unlock()
+		return
+	}
+	board, moves := r.game.Board.Clone(), r.game.MoveCount
+	level, name := r.settings.Computer, r.settings.Engine
+	r.mu.Unlock()
+
+	move := chooseComputerMove(board, level, name)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.computerPending = false
+	if r.game.MoveCount != moves || r.game.CurrentTurn() != 'O' {
+		r.scheduleComputerLocked()
+		return
+	}
+	if len(r.clients) == 0 || !r.started || r.game.IsOver() || r.flaggedLocked() {
+		return
+	}
+	r.playLocked(level.Name(), move)
+}
+
+// chooseComputerMove picks the computer's reply with the named engine. With
+// no engine named, classic games use the library's perfect minimax and
+// larger variants a depth-limited search.
+func chooseComputerMove(board variant.State, level computerLevel, name string) int {
+	if rand.Float64() < level.blunderRate() {
+		return engine.Random{}.Move(board)
+	}
+	if e, err := engine.New(name); err == nil {
+		return e.Move(board)
+	}
+	if _, ok := board.(*variant.Classic); ok {
+		return classicPosition(board).BestMove()
+	}
+	search, _ := engine.New("alphabeta")
+	return search.Move(board)
+}
+
+// reservedNameMiddleware turns away users whose name belongs to the
+// computer player.
+func reservedNameMiddleware() wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(sess ssh.Session) {
+			if isComputer(sess.User()) {
+				wish.Fatalln(sess, "Sorry, names starting with "+computerPrefix+" are reserved for the computer player.")
+				return
+			}
+			next(sess)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+func TestComputerRoom(t *testing.T) {
+	settings := DefaultRoomSettings()
+	settings.Computer = computerHard
+	r := NewRoom("vs-hard", settings, newTestStore(t), nil)
+
+	if role := r.Join("s1", "ann", discardProgram(), false); role != RolePlayerX {
+		t.Errorf("the first player got %v, want X", role)
+	}
+	if role := r.Join("s2", "bob", discardProgram(), false); role != RoleSpectator {
+		t.Errorf("the second player got %v, want to spectate", role)
+	}
+	if players, spectators := r.Counts(); players != 2 || spectators != 1 {
+		t.Errorf("got %d players and %d spectators, want 2 and 1", players, spectators)
+	}
+
+	r.mu.Lock()
+	roster := r.rosterLocked()
+	r.mu.Unlock()
+	if roster.PlayerO != computerHard.Name() {
+		t.Errorf("O is %q, want %q", roster.PlayerO, computerHard.Name())
+	}
+	if _, ok := r.ClaimSeat("s2"); ok {
+		t.Error("a spectator took the computer's seat")
+	}
+}
+
+func TestReservedNames(t *testing.T) {
+	for name, want := range map[string]bool{"computer-hard": true, "computer-": true, "ann": false, "my-computer": false} {
+		if got := isComputer(name); got != want {
+			t.Errorf("isComputer(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestComputerMove(t *testing.T) {
+	settings := DefaultRoomSettings()
+	settings.Computer = computerHard
+	r := NewRoom("vs-hard", settings, newTestStore(t), nil)
+	r.Join("s1", "ann", discardProgram(), false)
+	if !r.HandleMove("s1", 0) {
+		t.Fatal("X could not move")
+	}
+
+	r.computerMove()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.game.MoveCount != 2 || r.game.CurrentTurn() != 'X' {
+		t.Errorf("got %d moves with %c to move, want the computer's reply", r.game.MoveCount, r.game.CurrentTurn())
+	}
+}
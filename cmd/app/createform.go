@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jwc20/ssh-ttt/variant"
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Create Room Form (lobby)
+// ─────────────────────────────────────────────────────────────────────────────
+
+type createField int
+
+const (
+	createName createField = iota
+	createVariant
+	createBoard
+	createGravity
+	createOpponent
+	createEngine
+)
+
+// boardSizes are the boards the form offers for variants that take
+// options; gravity is chosen separately.
+var boardSizes = []variant.Options{
+	{Cols: 7, Rows: 6, InARow: 4},
+	{Cols: 6, Rows: 5, InARow: 4},
+	{Cols: 5, Rows: 4, InARow: 4},
+	{Cols: 4, Rows: 4, InARow: 3},
+	{Cols: 3, Rows: 3, InARow: 3},
+	{Cols: 8, Rows: 8, InARow: 5},
+	{Cols: 10, Rows: 10, InARow: 5},
+}
+
+// createForm holds the options of the create room dialog. The room name
+// itself lives in the lobby's text input.
+type createForm struct {
+	variant  int
+	board    int
+	gravity  bool
+	opponent int
+	engine   int
+	field    createField
+}
+
+func newCreateForm() createForm {
+	return createForm{gravity: true}
+}
+
+func (f createForm) info() variant.Info {
+	info, _ := variant.Lookup(roomVariants[f.variant])
+	return info
+}
+
+// fields lists the rows shown for the selected variant and opponent.
+func (f createForm) fields() []createField {
+	fields := []createField{createName, createVariant}
+	if f.info().Configure != nil {
+		fields = append(fields, createBoard, createGravity)
+	}
+	fields = append(fields, createOpponent)
+	if computerLevels[f.opponent] != "" {
+		fields = append(fields, createEngine)
+	}
+	return fields
+}
+
+func (f createForm) settings() RoomSettings {
+	settings := DefaultRoomSettings()
+	settings.Variant = roomVariants[f.variant]
+	settings.Computer = computerLevels[f.opponent]
+	if settings.Computer != "" {
+		settings.Engine = computerEngines[f.engine]
+	}
+	if f.info().Configure != nil {
+		settings.Board = boardSizes[f.board]
+		settings.Board.Gravity = f.gravity
+	}
+	return settings
+}
+
+// Update moves between rows with ↑/↓ or tab and changes the selected row
+// with ←/→. Keys for the name row are left to the text input.
+func (f createForm) Update(msg tea.KeyMsg) createForm {
+	step := func(i, delta, n int) int { return ((i+delta)%n + n) % n }
+
+	switch msg.String() {
+	case "up", "shift+tab", "down", "tab":
+		delta := 1
+		if s := msg.String(); s == "up" || s == "shift+tab" {
+			delta = -1
+		}
+		fields := f.fields()
+		i := 0
+		for j, field := range fields {
+			if field == f.field {
+				i = j
+			}
+		}
+		f.field = fields[step(i, delta, len(fields))]
+	case "left", "right":
+		delta := 1
+		if msg.String() == "left" {
+			delta = -1
+		}
+		switch f.field {
+		case createVariant:
+			f.variant = step(f.variant, delta, len(roomVariants))
+		case createBoard:
+			f.board = step(f.board, delta, len(boardSizes))
+		case createGravity:
+			f.gravity = !f.gravity
+		case createOpponent:
+			f.opponent = step(f.opponent, delta, len(computerLevels))
+		case createEngine:
+			f.engine = step(f.engine, delta, len(computerEngines))
+		}
+	}
+	return f
+}
+
+// View renders the option rows; the name row is drawn by the lobby.
+func (f createForm) View() string {
+	opponent := "another player"
+	if level := computerLevels[f.opponent]; level != "" {
+		opponent = fmt.Sprintf("the computer (%s)", level)
+	}
+	engineName := computerEngines[f.engine]
+	if engineName == "" {
+		engineName = "automatic"
+	}
+	gravity := "off"
+	if f.gravity {
+		gravity = "on"
+	}
+	values := map[createField]struct{ label, value string }{
+		createVariant:  {"Variant", f.info().Name},
+		createBoard:    {"Board", boardSizes[f.board].String()},
+		createGravity:  {"Gravity", gravity},
+		createOpponent: {"Opponent", opponent},
+		createEngine:   {"Engine", engineName},
+	}
+
+	var b strings.Builder
+	for _, field := range f.fields()[1:] {
+		row := values[field]
+		line := fmt.Sprintf("%-9s ‹ %s ›", row.label, row.value)
+		if field == f.field {
+			b.WriteString(lobbySelectedItem.Render("▸ "+line) + "\n")
+		} else {
+			b.WriteString(lobbyItemStyle.Render("  "+line) + "\n")
+		}
+		if field == createVariant {
+			b.WriteString(lobbyItemStyle.Render("            "+f.info().Description) + "\n")
+		}
+	}
+	return b.String()
+}
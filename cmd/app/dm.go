@@ -0,0 +1,222 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Direct Messages Overlay
+// ─────────────────────────────────────────────────────────────────────────────
+
+// dmModel keeps this session's private conversations. It lives on the root
+// model so messages and unread counts survive moving between lobby and rooms.
+type dmModel struct {
+	shared        *SharedState
+	sessID        string
+	userID        string
+	online        []OnlineUser
+	conversations map[string][]DirectMsg
+	unread        map[string]int
+	peer          string
+	open          bool
+	status        string
+	input         textinput.Model
+}
+
+func newDMModel(shared *SharedState, sessID, userID string) dmModel {
+	ti := textinput.New()
+	ti.Placeholder = "Message..."
+	ti.CharLimit = 200
+	ti.Width = 40
+
+	return dmModel{
+		shared:        shared,
+		sessID:        sessID,
+		userID:        userID,
+		conversations: make(map[string][]DirectMsg),
+		unread:        make(map[string]int),
+		input:         ti,
+	}
+}
+
+func (m dmModel) Update(msg tea.Msg) (dmModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case OnlineUsersMsg:
+		m.online = msg.Users
+		if m.peer == "" {
+			if peers := m.peers(); len(peers) > 0 {
+				m.peer = peers[0]
+			}
+		}
+
+	case DirectMsg:
+		peer := msg.From
+		if peer == m.userID {
+			peer = msg.To
+		}
+		m.conversations[peer] = append(m.conversations[peer], msg)
+		if msg.From != m.userID && !(m.open && m.peer == peer) {
+			m.unread[peer]++
+		}
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+
+	return m, nil
+}
+
+func (m dmModel) handleKey(msg tea.KeyMsg) (dmModel, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+d":
+		m.open = false
+		m.input.Blur()
+		return m, nil
+
+	case "up", "down":
+		peers := m.peers()
+		if len(peers) == 0 {
+			return m, nil
+		}
+		i := max(sort.SearchStrings(peers, m.peer), 0)
+		if msg.String() == "up" {
+			i = max(i-1, 0)
+		} else {
+			i = min(i+1, len(peers)-1)
+		}
+		m.peer = peers[i]
+		delete(m.unread, m.peer)
+		return m, nil
+
+	case "enter":
+		text := strings.TrimSpace(m.input.Value())
+		if text == "" || m.peer == "" {
+			return m, nil
+		}
+		m.status = ""
+		if err := m.shared.SendDirect(m.sessID, m.peer, text); err != nil {
+			m.status = err.Error()
+		}
+		m.input.Reset()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// Open shows the overlay, optionally jumping to a conversation.
+func (m *dmModel) Open(peer string) tea.Cmd {
+	m.open = true
+	if peer != "" {
+		m.peer = peer
+	}
+	delete(m.unread, m.peer)
+	m.input.Focus()
+	return textinput.Blink
+}
+
+// peers lists everyone online plus anyone we have a conversation with,
+// excluding ourselves, sorted by name.
+func (m dmModel) peers() []string {
+	seen := map[string]bool{m.userID: true}
+	var peers []string
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			peers = append(peers, name)
+		}
+	}
+	for _, u := range m.online {
+		add(u.Name)
+	}
+	for name := range m.conversations {
+		add(name)
+	}
+	sort.Strings(peers)
+	return peers
+}
+
+func (m dmModel) isOnline(name string) bool {
+	for _, u := range m.online {
+		if u.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Unread returns the total number of unread direct messages.
+func (m dmModel) Unread() int {
+	total := 0
+	for _, n := range m.unread {
+		total += n
+	}
+	return total
+}
+
+var (
+	dmUnreadStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Bold(true)
+	dmOfflineStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+)
+
+func (m dmModel) View() string {
+	var list strings.Builder
+	list.WriteString(focusLabel.Render("MESSAGES") + "\n")
+	peers := m.peers()
+	if len(peers) == 0 {
+		list.WriteString("nobody else online\n")
+	}
+	for _, p := range peers {
+		line := p
+		if !m.isOnline(p) {
+			line = dmOfflineStyle.Render(p)
+		}
+		if n := m.unread[p]; n > 0 {
+			line += dmUnreadStyle.Render(fmt.Sprintf(" (%d)", n))
+		}
+		if p == m.peer {
+			line = "▸ " + line
+		} else {
+			line = "  " + line
+		}
+		list.WriteString(line + "\n")
+	}
+
+	var convo strings.Builder
+	if m.peer == "" {
+		convo.WriteString("Pick someone to message.\n")
+	} else {
+		convo.WriteString(focusLabel.Render(m.peer) + "\n")
+		msgs := m.conversations[m.peer]
+		for _, d := range msgs[max(0, len(msgs)-12):] {
+			convo.WriteString(fmt.Sprintf("%s %s: %s\n", d.At.Format("15:04"), d.From, d.Text))
+		}
+	}
+	convo.WriteString("\n" + m.input.View())
+	if m.status != "" {
+		convo.WriteString("\n" + dmUnreadStyle.Render(m.status))
+	}
+
+	panels := lipgloss.JoinHorizontal(lipgloss.Top,
+		onlineListStyle.Render(strings.TrimRight(list.String(), "\n")),
+		chatBorder.Width(46).Render(convo.String()),
+	)
+	return lipgloss.JoinVertical(lipgloss.Left, panels,
+		roomHelpText.Render("↑/↓: conversation  enter: send  esc: close"))
+}
+
+// viewUnreadBadge is appended to the lobby and room views.
+func (m dmModel) viewUnreadBadge() string {
+	if n := m.Unread(); n > 0 {
+		return dmUnreadStyle.Render(fmt.Sprintf("✉ %d unread (ctrl+d)", n))
+	}
+	return roomHelpText.Render("ctrl+d: messages")
+}
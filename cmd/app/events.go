@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/charmbracelet/log"
+	"github.com/jwc20/ssh-ttt/config"
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Game Event Log
+// ─────────────────────────────────────────────────────────────────────────────
+
+// Event names. Every event carries the same field names so a room's history
+// can be reconstructed by filtering on "room".
+const (
+	evConnected    = "session.connected"
+	evRejected     = "session.rejected"
+	evDisconnected = "session.disconnected"
+	evRoomCreated  = "room.created"
+	evRoomRemoved  = "room.removed"
+	evJoined       = "room.joined"
+	evLeft         = "room.left"
+	evMove         = "game.move"
+	evFinished     = "game.finished"
+	evChat         = "chat.sent"
+	evAchievement  = "player.achievement"
+
+	evTournamentCreated  = "tournament.created"
+	evTournamentStarted  = "tournament.started"
+	evTournamentResult   = "tournament.result"
+	evTournamentFinished = "tournament.finished"
+)
+
+// EventLog writes game events to the server log and, when configured, as
+// JSON lines to a size-rotated file.
+type EventLog struct {
+	text *log.Logger
+	json *log.Logger
+	file *rotatingFile
+}
+
+// events is the process-wide event log. main replaces it once the
+// configuration is loaded.
+var events = &EventLog{text: log.Default().WithPrefix("event")}
+
+func NewEventLog(cfg config.Events) (*EventLog, error) {
+	e := &EventLog{text: log.Default().WithPrefix("event")}
+	if cfg.Path == "" {
+		return e, nil
+	}
+
+	f, err := openRotatingFile(cfg.Path, int64(cfg.MaxSizeMB)<<20, cfg.MaxBackups)
+	if err != nil {
+		return nil, err
+	}
+	e.file = f
+	e.json = log.NewWithOptions(f, log.Options{
+		ReportTimestamp: true,
+		TimeFormat:      "2006-01-02T15:04:05.000Z07:00",
+		Formatter:       log.JSONFormatter,
+	})
+	return e, nil
+}
+
+// Emit records one event. keyvals are alternating field names and values.
+func (e *EventLog) Emit(event string, keyvals ...any) {
+	e.text.Info(event, keyvals...)
+	if e.json != nil {
+		e.json.Info(event, keyvals...)
+	}
+}
+
+func (e *EventLog) Close() error {
+	if e.file == nil {
+		return nil
+	}
+	return e.file.Close()
+}
+
+// ── Rotating file ────────────────────────────────────────────────────────────
+
+// rotatingFile appends to path and, once it grows past maxSize, renames it
+// to path.1 (shifting older files up to path.<backups>) and starts afresh.
+type rotatingFile struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	backups int
+	f       *os.File
+	size    int64
+}
+
+func openRotatingFile(path string, maxSize int64, backups int) (*rotatingFile, error) {
+	w := &rotatingFile{path: path, maxSize: maxSize, backups: backups}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *rotatingFile) open() error {
+	f, err := os.OpenFile(w.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("problem opening event log %s, %v", w.path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("problem getting file info from file %s, %v", w.path, err)
+	}
+	w.f, w.size = f, info.Size()
+	return nil
+}
+
+func (w *rotatingFile) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.maxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.f.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *rotatingFile) rotate() error {
+	if err := w.f.Close(); err != nil {
+		return err
+	}
+	backup := func(i int) string { return fmt.Sprintf("%s.%d", w.path, i) }
+
+	if w.backups <= 0 {
+		os.Remove(w.path)
+	} else {
+		os.Remove(backup(w.backups))
+		for i := w.backups - 1; i >= 1; i-- {
+			os.Rename(backup(i), backup(i+1))
+		}
+		if err := os.Rename(w.path, backup(1)); err != nil {
+			return err
+		}
+	}
+	return w.open()
+}
+
+func (w *rotatingFile) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.f.Close()
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Leaderboard Queries
+// ─────────────────────────────────────────────────────────────────────────────
+
+type LeaderboardEntry struct {
+	Name   string
+	Wins   int
+	Played int
+	Rating int
+}
+
+func (e LeaderboardEntry) WinRate() float64 {
+	if e.Played == 0 {
+		return 0
+	}
+	return float64(e.Wins) / float64(e.Played)
+}
+
+type leaderboardWindow int
+
+const (
+	windowAllTime leaderboardWindow = iota
+	windowWeek
+	windowToday
+)
+
+func (w leaderboardWindow) String() string {
+	switch w {
+	case windowWeek:
+		return "this week"
+	case windowToday:
+		return "today"
+	default:
+		return "all-time"
+	}
+}
+
+// Since returns the start of the window relative to now, or the zero time
+// for all-time.
+func (w leaderboardWindow) Since(now time.Time) time.Time {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch w {
+	case windowToday:
+		return today
+	case windowWeek:
+		offset := (int(today.Weekday()) + 6) % 7 // weeks start on Monday
+		return today.AddDate(0, 0, -offset)
+	default:
+		return time.Time{}
+	}
+}
+
+const sqliteTimeLayout = "2006-01-02 15:04:05"
+
+// Leaderboard returns per-player totals for games finished since the given
+// time. All-time totals use the players table so wins recorded before game
+// history existed still count. Computer players and games against them are
+// left out, since those games are unrated.
+func (s *SQLiteStore) Leaderboard(since time.Time) ([]LeaderboardEntry, error) {
+	defer storeTimer("leaderboard")()
+
+	computers := computerPrefix + "%"
+	query := `
+		SELECT p.name, p.rating, p.wins, COUNT(g.id)
+		FROM players p
+		LEFT JOIN games g ON (g.player_x = p.name OR g.player_o = p.name)
+			AND g.player_x NOT LIKE ? AND g.player_o NOT LIKE ?
+		WHERE p.name NOT LIKE ?
+		GROUP BY p.name
+	`
+	args := []any{computers, computers, computers}
+
+	if !since.IsZero() {
+		query = `
+			SELECT p.name, p.rating,
+				SUM(CASE
+					WHEN g.winner = 'X' AND g.player_x = p.name THEN 1
+					WHEN g.winner = 'O' AND g.player_o = p.name THEN 1
+					ELSE 0
+				END),
+				COUNT(g.id)
+			FROM players p
+			JOIN games g ON g.player_x = p.name OR g.player_o = p.name
+			WHERE g.finished_at >= ? AND g.player_x NOT LIKE ? AND g.player_o NOT LIKE ?
+			GROUP BY p.name
+		`
+		args = []any{since.UTC().Format(sqliteTimeLayout), computers, computers}
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []LeaderboardEntry
+	for rows.Next() {
+		var e LeaderboardEntry
+		if err := rows.Scan(&e.Name, &e.Rating, &e.Wins, &e.Played); err != nil {
+			return nil, err
+		}
+		e.Played = max(e.Played, e.Wins)
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Leaderboard Model
+// ─────────────────────────────────────────────────────────────────────────────
+
+type leaderboardSort int
+
+const (
+	sortByWins leaderboardSort = iota
+	sortByWinRate
+	sortByRating
+	sortByPlayed
+)
+
+func (s leaderboardSort) String() string {
+	switch s {
+	case sortByWinRate:
+		return "win rate"
+	case sortByRating:
+		return "rating"
+	case sortByPlayed:
+		return "games"
+	default:
+		return "wins"
+	}
+}
+
+func (s leaderboardSort) less(a, b LeaderboardEntry) bool {
+	switch s {
+	case sortByWinRate:
+		if a.WinRate() != b.WinRate() {
+			return a.WinRate() > b.WinRate()
+		}
+	case sortByRating:
+		if a.Rating != b.Rating {
+			return a.Rating > b.Rating
+		}
+	case sortByPlayed:
+		if a.Played != b.Played {
+			return a.Played > b.Played
+		}
+	}
+	if a.Wins != b.Wins {
+		return a.Wins > b.Wins
+	}
+	return a.Name < b.Name
+}
+
+const leaderboardPageSize = 10
+
+type LeaderboardMsg struct {
+	Window  leaderboardWindow
+	Entries []LeaderboardEntry
+}
+
+type leaderboardModel struct {
+	store   *SQLiteStore
+	userID  string
+	entries []LeaderboardEntry
+	sortBy  leaderboardSort
+	window  leaderboardWindow
+	page    int
+}
+
+func newLeaderboardModel(store *SQLiteStore, userID string) leaderboardModel {
+	return leaderboardModel{store: store, userID: userID}
+}
+
+// Load fetches the leaderboard for the current window off the UI goroutine.
+func (m leaderboardModel) Load() tea.Cmd {
+	store, window := m.store, m.window
+	return func() tea.Msg {
+		entries, err := store.Leaderboard(window.Since(time.Now()))
+		if err != nil {
+			log.Error("leaderboard query failed", "error", err)
+		}
+		return LeaderboardMsg{Window: window, Entries: entries}
+	}
+}
+
+func (m leaderboardModel) Update(msg tea.Msg) (leaderboardModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case LeaderboardMsg:
+		if msg.Window != m.window {
+			return m, nil
+		}
+		m.entries = msg.Entries
+		m.sortEntries()
+		m.page = min(m.page, m.pageCount()-1)
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "left", "h":
+			m.page = max(0, m.page-1)
+		case "right", "l":
+			m.page = min(m.pageCount()-1, m.page+1)
+		case "s":
+			m.sortBy = (m.sortBy + 1) % (sortByPlayed + 1)
+			m.sortEntries()
+			m.page = 0
+		case "w":
+			m.window = (m.window + 1) % (windowToday + 1)
+			m.page = 0
+			return m, m.Load()
+		case "r":
+			return m, m.Load()
+		case "m":
+			m.page = m.ownPage()
+		}
+	}
+
+	return m, nil
+}
+
+func (m *leaderboardModel) sortEntries() {
+	sort.SliceStable(m.entries, func(i, j int) bool {
+		return m.sortBy.less(m.entries[i], m.entries[j])
+	})
+}
+
+func (m leaderboardModel) pageCount() int {
+	return max(1, (len(m.entries)+leaderboardPageSize-1)/leaderboardPageSize)
+}
+
+func (m leaderboardModel) ownPage() int {
+	for i, e := range m.entries {
+		if e.Name == m.userID {
+			return i / leaderboardPageSize
+		}
+	}
+	return m.page
+}
+
+// ── Leaderboard Styles ───────────────────────────────────────────────────────
+
+var (
+	leaderboardHeader = lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Bold(true)
+	leaderboardOwnRow = lipgloss.NewStyle().Foreground(lipgloss.Color("170")).Bold(true)
+)
+
+func (m leaderboardModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("  %s · sorted by %s\n\n", m.window, m.sortBy))
+
+	if len(m.entries) == 0 {
+		b.WriteString("  No games recorded yet.\n")
+	} else {
+		header := fmt.Sprintf("  %4s  %-16s %5s %6s %6s %6s", "#", "Player", "Wins", "Games", "Win%", "Rating")
+		b.WriteString(leaderboardHeader.Render(header) + "\n")
+
+		start := m.page * leaderboardPageSize
+		end := min(start+leaderboardPageSize, len(m.entries))
+		for i, e := range m.entries[start:end] {
+			line := fmt.Sprintf("  %4d  %-16s %5d %6d %5.0f%% %6d",
+				start+i+1, e.Name, e.Wins, e.Played, e.WinRate()*100, e.Rating)
+			if e.Name == m.userID {
+				line = leaderboardOwnRow.Render(line)
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+
+	b.WriteString(fmt.Sprintf("\n  page %d/%d\n", m.page+1, m.pageCount()))
+	return b.String()
+}
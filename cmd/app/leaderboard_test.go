@@ -0,0 +1,86 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestLeaderboardWindowSince(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	day := func(d, h int) time.Time { return time.Date(2024, time.May, d, h, 0, 0, 0, loc) }
+
+	tests := []struct {
+		name   string
+		window leaderboardWindow
+		now    time.Time
+		want   time.Time
+	}{
+		{"all-time has no start", windowAllTime, day(15, 10), time.Time{}},
+		{"today starts at midnight", windowToday, day(15, 10), day(15, 0)},
+		{"the week starts on Monday", windowWeek, day(15, 10), day(13, 0)},
+		{"on a Monday the week starts today", windowWeek, day(13, 23), day(13, 0)},
+		{"on a Sunday the week started six days ago", windowWeek, day(19, 1), day(13, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.window.Since(tt.now); !got.Equal(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLeaderboardWindows(t *testing.T) {
+	store := newTestStore(t)
+	store.RecordGame(GameResult{Room: "r", PlayerX: "ann", PlayerO: "bob", Winner: "X"})
+	store.RecordGame(GameResult{Room: "r", PlayerX: "bob", PlayerO: "ann", Winner: "X"})
+	store.RecordGame(GameResult{Room: "r", PlayerX: "ann", PlayerO: computerEasy.Name(), Winner: "X"})
+	old := time.Now().AddDate(0, 0, -10).UTC().Format(sqliteTimeLayout)
+	if _, err := store.db.Exec("UPDATE games SET finished_at = ? WHERE id = (SELECT MIN(id) FROM games)", old); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		since time.Time
+		want  map[string]LeaderboardEntry
+	}{
+		{"all-time counts every rated game", time.Time{}, map[string]LeaderboardEntry{
+			"ann": {Wins: 1, Played: 2},
+			"bob": {Wins: 1, Played: 2},
+		}},
+		{"a window leaves out older games", time.Now().Add(-time.Hour), map[string]LeaderboardEntry{
+			"ann": {Wins: 0, Played: 1},
+			"bob": {Wins: 1, Played: 1},
+		}},
+		{"an empty window has no entries", time.Now().Add(time.Hour), map[string]LeaderboardEntry{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := store.Leaderboard(tt.since)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != len(tt.want) {
+				t.Fatalf("got %d entries, want %d", len(entries), len(tt.want))
+			}
+			for _, e := range entries {
+				want := tt.want[e.Name]
+				if e.Wins != want.Wins || e.Played != want.Played {
+					t.Errorf("%s: got %d wins in %d games, want %d in %d", e.Name, e.Wins, e.Played, want.Wins, want.Played)
+				}
+			}
+		})
+	}
+}
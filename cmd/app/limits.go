@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/jwc20/ssh-ttt/config"
+	"github.com/jwc20/ssh-ttt/limits"
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Connection, Idle & Action Limits
+// ─────────────────────────────────────────────────────────────────────────────
+
+// sessionLimits bundles the per-server limiters kept on SharedState.
+type sessionLimits struct {
+	conns   *limits.ConnLimiter
+	actions *limits.RateLimiter
+	idle    *limits.IdleTracker
+	// challenges is keyed by user name, not session, so reconnecting
+	// doesn't refill it.
+	challenges *limits.RateLimiter
+}
+
+func newSessionLimits(cfg config.Limits) sessionLimits {
+	return sessionLimits{
+		conns: limits.NewConnLimiter(limits.ConnLimits{
+			MaxSessions: cfg.MaxSessions,
+			MaxPerIP:    cfg.MaxSessionsPerIP,
+			MaxPerUser:  cfg.MaxSessionsPerUser,
+		}),
+		actions:    limits.NewRateLimiter(cfg.ActionBurst, cfg.ActionInterval.D()),
+		idle:       limits.NewIdleTracker(cfg.IdleTimeout.D()),
+		challenges: limits.NewRateLimiter(challengeRateBurst, challengeRateInterval),
+	}
+}
+
+// connLimitMiddleware turns sessions away, with an explanation, once the
+// server, their address or their user name is at its session cap.
+func connLimitMiddleware(shared *SharedState) wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(sess ssh.Session) {
+			ip := sess.RemoteAddr().String()
+			if host, _, err := net.SplitHostPort(ip); err == nil {
+				ip = host
+			}
+
+			release, err := shared.limits.conns.Acquire(ip, sess.User())
+			if err != nil {
+				events.Emit(evRejected, "user", sess.User(), "addr", ip, "reason", err.Error())
+				wish.Fatalln(sess, fmt.Sprintf("Sorry, %s. Please try again later.", err))
+				return
+			}
+			defer release()
+			next(sess)
+		}
+	}
+}
+
+// AllowAction spends one of a session's action tokens.
+func (s *SharedState) AllowAction(sessID string) bool {
+	s.limits.idle.Touch(sessID)
+	return s.limits.actions.Allow(sessID)
+}
+
+// expireIdle disconnects sessions that have not pressed a key for the idle
+// timeout. They are told why before the program quits.
+func (s *SharedState) expireIdle() {
+	expired := s.limits.idle.Expired()
+	if len(expired) == 0 {
+		return
+	}
+
+	msg := IdleTimeoutMsg{After: s.limits.idle.Timeout()}
+	s.sessMu.RLock()
+	defer s.sessMu.RUnlock()
+	for _, id := range expired {
+		if sess, ok := s.sessions[id]; ok {
+			p := sess.Program
+			go p.Send(msg)
+		}
+	}
+}
+
+func (s *SharedState) forgetLimits(sessID string) {
+	s.limits.idle.Forget(sessID)
+	s.limits.actions.Forget(sessID)
+}
+
+// ── TUI messaging ────────────────────────────────────────────────────────────
+
+type IdleTimeoutMsg struct{ After time.Duration }
+
+// throttleNoticeTTL is how long the "slow down" banner stays up.
+const throttleNoticeTTL = 3 * time.Second
+
+// idleGoodbye is how long the idle notice is shown before disconnecting.
+const idleGoodbye = 3 * time.Second
+
+var limitNoticeStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("0")).
+	Background(lipgloss.Color("214")).
+	Padding(0, 1)
+
+func viewThrottleNotice() string {
+	return limitNoticeStyle.Render("⏳ Easy there! You're sending keys too fast, some were ignored.")
+}
+
+func viewIdleNotice(after time.Duration) string {
+	return challengeModal.Render(fmt.Sprintf(
+		"You've been idle for %s, so we're disconnecting you.\n\nThanks for playing. Come back any time!", after))
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Online Users, Lobby Chat & Direct Messages
+// ─────────────────────────────────────────────────────────────────────────────
+
+type OnlineUser struct {
+	Name string
+	Room string // empty while in the lobby
+}
+
+type onlineSession struct {
+	Program     *tea.Program
+	UserID      string
+	Room        string
+	Addr        string
+	ConnectedAt time.Time
+}
+
+type (
+	OnlineUsersMsg      struct{ Users []OnlineUser }
+	LobbyChatMsg        RoomChatMsg
+	LobbyChatHistoryMsg struct{ Messages []RoomChatMsg }
+	DirectMsg           struct {
+		From, To, Text string
+		At             time.Time
+	}
+)
+
+var errUserOffline = errors.New("user is not online")
+
+// Connect registers a session as online for the whole lifetime of its SSH
+// connection, wherever it is.
+func (s *SharedState) Connect(sessID, userID, addr string, p *tea.Program) {
+	s.sessMu.Lock()
+	s.sessions[sessID] = &onlineSession{Program: p, UserID: userID, Addr: addr, ConnectedAt: time.Now()}
+	s.sessMu.Unlock()
+	events.Emit(evConnected, "user", userID, "session", sessID, "addr", addr)
+	s.limits.idle.Touch(sessID)
+
+	ignoring := s.Store.Ignoring(userID)
+	s.lobbyMu.RLock()
+	history := withoutIgnored(s.lobbyHistory, ignoring)
+	s.lobbyMu.RUnlock()
+	if len(history) > 0 {
+		go p.Send(LobbyChatHistoryMsg{Messages: history})
+	}
+
+	s.broadcastOnline()
+}
+
+func (s *SharedState) disconnect(sessID string) {
+	s.sessMu.Lock()
+	delete(s.sessions, sessID)
+	s.sessMu.Unlock()
+	s.broadcastOnline()
+}
+
+// SetLocation records which room a session is in; empty means the lobby.
+func (s *SharedState) SetLocation(sessID, roomID string) {
+	s.sessMu.Lock()
+	if sess, ok := s.sessions[sessID]; ok {
+		sess.Room = roomID
+	}
+	s.sessMu.Unlock()
+	s.broadcastOnline()
+}
+
+// OnlineUsers returns one entry per session, sorted by name.
+func (s *SharedState) OnlineUsers() []OnlineUser {
+	s.sessMu.RLock()
+	defer s.sessMu.RUnlock()
+
+	users := make([]OnlineUser, 0, len(s.sessions))
+	for _, sess := range s.sessions {
+		users = append(users, OnlineUser{Name: sess.UserID, Room: sess.Room})
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].Name != users[j].Name {
+			return users[i].Name < users[j].Name
+		}
+		return users[i].Room < users[j].Room
+	})
+	return users
+}
+
+func (s *SharedState) broadcastOnline() {
+	msg := OnlineUsersMsg{Users: s.OnlineUsers()}
+	s.sessMu.RLock()
+	defer s.sessMu.RUnlock()
+	for _, sess := range s.sessions {
+		p := sess.Program
+		go p.Send(msg)
+	}
+}
+
+// LobbyChat posts a lobby-wide message from a session. /me and /whisper work
+// as they do in rooms; whispers become direct messages.
+func (s *SharedState) LobbyChat(sessID, text string) {
+	s.sessMu.RLock()
+	sess, ok := s.sessions[sessID]
+	s.sessMu.RUnlock()
+	if !ok {
+		return
+	}
+
+	notify := func(text string) {
+		go sess.Program.Send(LobbyChatMsg{Text: text, Kind: chatSystem})
+	}
+
+	msg := RoomChatMsg{Sender: sess.UserID, Text: text}
+	if strings.HasPrefix(text, "/") {
+		cmd, rest, _ := strings.Cut(text, " ")
+		rest = strings.TrimSpace(rest)
+		switch cmd {
+		case "/me":
+			msg.Kind, msg.Text = chatEmote, rest
+		case "/whisper", "/w":
+			to, body, _ := strings.Cut(rest, " ")
+			if err := s.SendDirect(sessID, to, strings.TrimSpace(body)); err != nil {
+				notify(err.Error())
+			}
+			return
+		case "/ignore", "/unignore":
+			notify(ignoreCommand(s.Store, sess.UserID, cmd, rest))
+			return
+		case "/help":
+			notify("commands: /me <action>  /whisper <user> <text>  /ignore /unignore <user>  /help")
+			return
+		default:
+			notify(fmt.Sprintf("unknown command %s, try /help", cmd))
+			return
+		}
+	}
+
+	if msg.Text == "" {
+		return
+	}
+	if err := s.moderator.Allow(sess.UserID); err != nil {
+		notify(err.Error())
+		return
+	}
+	msg.Text = s.moderator.Filter(msg.Text)
+	events.Emit(evChat, "scope", "lobby", "user", sess.UserID, "kind", msg.Kind.String(), "text", msg.Text)
+
+	s.lobbyMu.Lock()
+	s.lobbyHistory = append(s.lobbyHistory, msg)
+	if len(s.lobbyHistory) > chatHistoryLimit {
+		s.lobbyHistory = s.lobbyHistory[len(s.lobbyHistory)-chatHistoryLimit:]
+	}
+	s.lobbyMu.Unlock()
+
+	// Sessions in rooms get lobby chat too so the pane is current when
+	// they come back.
+	ignoredBy := s.Store.IgnoredBy(msg.Sender)
+	s.sessMu.RLock()
+	defer s.sessMu.RUnlock()
+	for _, sess := range s.sessions {
+		if ignoredBy[sess.UserID] {
+			continue
+		}
+		p := sess.Program
+		go p.Send(LobbyChatMsg(msg))
+	}
+}
+
+var errDirectMessagesOff = errors.New("direct messages are disabled on this server")
+
+// SendDirect delivers a private message to every session of the recipient
+// and echoes it to the sender's sessions.
+func (s *SharedState) SendDirect(sessID, to, text string) error {
+	if !s.Config.Features.DirectMessages {
+		return errDirectMessagesOff
+	}
+	if to == "" || text == "" {
+		return errors.New("usage: /whisper <user> <text>")
+	}
+
+	s.sessMu.RLock()
+	defer s.sessMu.RUnlock()
+
+	from, ok := s.sessions[sessID]
+	if !ok {
+		return errUserOffline
+	}
+	if err := s.moderator.Allow(from.UserID); err != nil {
+		return err
+	}
+
+	msg := DirectMsg{From: from.UserID, To: to, Text: s.moderator.Filter(text), At: time.Now()}
+
+	delivered := false
+	for _, sess := range s.sessions {
+		if sess.UserID == to {
+			delivered = true
+		}
+	}
+	if !delivered {
+		return fmt.Errorf("%s: %w", to, errUserOffline)
+	}
+
+	events.Emit(evChat, "scope", "direct", "user", from.UserID, "to", to, "kind", chatWhisper.String())
+	// As with whispers, the sender isn't told they are ignored.
+	ignored := s.Store.IgnoredBy(from.UserID)[to]
+	for _, sess := range s.sessions {
+		if (sess.UserID == to && !ignored) || sess.UserID == from.UserID {
+			p := sess.Program
+			go p.Send(msg)
+		}
+	}
+	return nil
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Lobby Chat Pane
+// ─────────────────────────────────────────────────────────────────────────────
+
+type lobbyChatModel struct {
+	shared   *SharedState
+	sessID   string
+	userID   string
+	users    []OnlineUser
+	log      []string
+	viewport viewport.Model
+	input    textinput.Model
+}
+
+func newLobbyChatModel(shared *SharedState, sessID, userID string) lobbyChatModel {
+	vp := viewport.New(40, 12)
+
+	ti := textinput.New()
+	ti.Placeholder = "Say something to the lobby..."
+	ti.CharLimit = 200
+	ti.Width = 38
+
+	return lobbyChatModel{shared: shared, sessID: sessID, userID: userID, viewport: vp, input: ti}
+}
+
+func (m lobbyChatModel) Update(msg tea.Msg) (lobbyChatModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case OnlineUsersMsg:
+		m.users = msg.Users
+
+	case LobbyChatMsg:
+		m.append(formatChat(RoomChatMsg(msg)))
+
+	case LobbyChatHistoryMsg:
+		for _, c := range msg.Messages {
+			m.append(formatChat(c))
+		}
+
+	case tea.KeyMsg:
+		if msg.String() == "enter" {
+			text := strings.TrimSpace(m.input.Value())
+			if text != "" {
+				m.shared.LobbyChat(m.sessID, text)
+				m.input.Reset()
+			}
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+
+	return m, nil
+}
+
+func (m *lobbyChatModel) append(line string) {
+	m.log = append(m.log, line)
+	if len(m.log) > chatHistoryLimit {
+		m.log = m.log[len(m.log)-chatHistoryLimit:]
+	}
+	m.viewport.SetContent(strings.Join(m.log, "\n"))
+	m.viewport.GotoBottom()
+}
+
+func (m *lobbyChatModel) Focus() tea.Cmd {
+	m.input.Focus()
+	return textinput.Blink
+}
+
+func (m *lobbyChatModel) Blur() {
+	m.input.Blur()
+}
+
+var onlineListStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(lipgloss.Color("62")).
+	Padding(0, 1).
+	Width(22)
+
+func (m lobbyChatModel) View() string {
+	chat := chatBorder.Render(m.viewport.View() + "\n" + m.input.View())
+	return lipgloss.JoinHorizontal(lipgloss.Top, chat, onlineListStyle.Render(viewOnlineUsers(m.users)))
+}
+
+func viewOnlineUsers(users []OnlineUser) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Online (%d)\n", len(users)))
+	for _, u := range users {
+		where := "lobby"
+		if u.Room != "" {
+			where = "in " + u.Room
+		}
+		b.WriteString(fmt.Sprintf("%s %s\n", u.Name, lobbyHelpStyle.UnsetMarginTop().Render(where)))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/jwc20/ssh-ttt/config"
+	"github.com/jwc20/ssh-ttt/metrics"
+	"github.com/jwc20/ssh-ttt/tournament"
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Metrics
+// ─────────────────────────────────────────────────────────────────────────────
+
+var (
+	movesTotal = metrics.Default.NewCounter("ttt_moves_total",
+		"Moves played in all rooms.")
+	gamesFinished = metrics.Default.NewCounterVec("ttt_games_finished_total",
+		"Games finished, by result (x, o or draw).", "result")
+)
+
+// storeTimer is deferred at the top of every SQLiteStore method.
+func storeTimer(op string) func() {
+	start := time.Now()
+	return func() { metrics.StoreDuration.With("sqlite", op).ObserveSince(start) }
+}
+
+func gameResultLabel(winner string) string {
+	if winner == "" {
+		return "draw"
+	}
+	return strings.ToLower(winner)
+}
+
+// registerMetrics exposes the live session and room counts. The values are
+// read at scrape time, so nothing has to be kept in sync.
+func (s *SharedState) registerMetrics(r *metrics.Registry) {
+	for _, result := range []string{"x", "o", "draw"} {
+		gamesFinished.With(result)
+	}
+	r.NewGaugeFunc("ttt_sessions_connected", "Connected SSH sessions.", func() float64 {
+		s.sessMu.RLock()
+		defer s.sessMu.RUnlock()
+		return float64(len(s.sessions))
+	})
+	r.NewGaugeFunc("ttt_lobby_sessions", "Sessions in the lobby.", func() float64 {
+		s.lobbyMu.RLock()
+		defer s.lobbyMu.RUnlock()
+		return float64(len(s.lobby))
+	})
+	r.NewGaugeVecFunc("ttt_rooms", "Open rooms by status.", []string{"status"}, func(set func(float64, ...string)) {
+		byStatus := map[string]int{"waiting": 0, "playing": 0, "finished": 0}
+		for _, room := range s.Rooms.List() {
+			byStatus[room.Status]++
+		}
+		for status, n := range byStatus {
+			set(float64(n), status)
+		}
+	})
+	r.NewGaugeFunc("ttt_challenges_pending", "Challenges waiting for an answer.", func() float64 {
+		s.challengeMu.Lock()
+		defer s.challengeMu.Unlock()
+		return float64(len(s.challenges))
+	})
+	r.NewGaugeVecFunc("ttt_tournaments", "Tournaments by status.", []string{"status"}, func(set func(float64, ...string)) {
+		byStatus := map[tournament.Status]int{tournament.Registering: 0, tournament.Running: 0, tournament.Finished: 0}
+		for _, t := range s.Tournaments.List() {
+			byStatus[t.Status]++
+		}
+		for status, n := range byStatus {
+			set(float64(n), string(status))
+		}
+	})
+}
+
+// serveHTTP serves /metrics on cfg.Metrics.Addr and the JSON API on
+// cfg.API.Addr until ctx is cancelled. An empty address turns that part
+// off; when both are the same, one server handles both.
+func serveHTTP(ctx context.Context, cfg config.Config, shared *SharedState) {
+	muxes := map[string]*http.ServeMux{}
+	muxFor := func(addr string) *http.ServeMux {
+		if muxes[addr] == nil {
+			muxes[addr] = http.NewServeMux()
+		}
+		return muxes[addr]
+	}
+
+	if cfg.Metrics.Addr != "" {
+		muxFor(cfg.Metrics.Addr).Handle("/metrics", metrics.Handler())
+	}
+	if cfg.API.Addr != "" {
+		mux := muxFor(cfg.API.Addr)
+		shared.Tournaments.routes(mux)
+		profileRoutes(mux, shared.Store)
+		replayRoutes(mux, shared.Store)
+		positionRoutes(mux)
+		variantRoutes(mux)
+	}
+
+	for addr, mux := range muxes {
+		go listenHTTP(ctx, addr, mux)
+	}
+}
+
+func listenHTTP(ctx context.Context, addr string, mux *http.ServeMux) {
+	srv := &http.Server{Addr: addr, Handler: metrics.Instrument(mux), ReadHeaderTimeout: 5 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+
+	log.Info("Serving HTTP", "addr", addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Error("HTTP server error", "error", err)
+	}
+}
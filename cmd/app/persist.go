@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Room Snapshots (survive restarts)
+// ─────────────────────────────────────────────────────────────────────────────
+
+type roomSnapshot struct {
+	ID        string
+	CreatedAt time.Time
+	Settings  RoomSettings
+	Cells     string
+	Turn      string
+	MoveCount int
+	Sequence  []int
+	Seats     [2]string
+	Clocks    [2]time.Duration
+	Chat      []RoomChatMsg
+	Hinted    bool
+}
+
+func (s *SQLiteStore) SaveRoomSnapshot(id string, data []byte) error {
+	defer storeTimer("save_room_snapshot")()
+
+	_, err := s.db.Exec(`
+		INSERT INTO room_snapshots (id, data, updated_at) VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(id) DO UPDATE SET data = excluded.data, updated_at = excluded.updated_at
+	`, id, data)
+	return err
+}
+
+func (s *SQLiteStore) DeleteRoomSnapshot(id string) error {
+	defer storeTimer("delete_room_snapshot")()
+
+	_, err := s.db.Exec("DELETE FROM room_snapshots WHERE id = ?", id)
+	return err
+}
+
+func (s *SQLiteStore) LoadRoomSnapshots() ([][]byte, error) {
+	defer storeTimer("load_room_snapshots")()
+
+	rows, err := s.db.Query("SELECT data FROM room_snapshots")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out [][]byte
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		out = append(out, data)
+	}
+	return out, rows.Err()
+}
+
+func (r *Room) snapshotLocked() roomSnapshot {
+	seats := r.reserved
+	for _, c := range r.clients {
+		switch c.Role {
+		case RolePlayerX:
+			seats[0] = c.UserID
+		case RolePlayerO:
+			seats[1] = c.UserID
+		}
+	}
+
+	return roomSnapshot{
+		ID:        r.ID,
+		CreatedAt: r.CreatedAt,
+		Settings:  r.settings,
+		Cells:     string(r.game.Cells()),
+		Turn:      r.game.CurrentPlayerString(),
+		MoveCount: r.game.MoveCount,
+		Sequence:  append([]int(nil), r.game.Sequence...),
+		Seats:     seats,
+		Clocks:    r.clocksLocked(),
+		Chat:      append([]RoomChatMsg(nil), r.history...),
+		Hinted:    r.hinted,
+	}
+}
+
+// persistLocked saves an in-progress game, or drops the snapshot once the
+// game is over and its result has been recorded.
+func (r *Room) persistLocked() {
+	if r.game.IsOver() || r.game.MoveCount == 0 {
+		if err := r.store.DeleteRoomSnapshot(r.ID); err != nil {
+			log.Error("delete room snapshot", "room", r.ID, "error", err)
+		}
+		return
+	}
+
+	data, err := json.Marshal(r.snapshotLocked())
+	if err != nil {
+		log.Error("encode room snapshot", "room", r.ID, "error", err)
+		return
+	}
+	if err := r.store.SaveRoomSnapshot(r.ID, data); err != nil {
+		log.Error("save room snapshot", "room", r.ID, "error", err)
+	}
+}
+
+// restoreRoom rebuilds a room from a snapshot. The game stays paused with
+// both seats reserved until the original players rejoin, and the room is
+// kept for the grace period even while empty.
+func restoreRoom(snap roomSnapshot, grace time.Duration, store *SQLiteStore, moderator *ChatModerator) *Room {
+	r := NewRoom(snap.ID, snap.Settings, store, moderator)
+	r.CreatedAt = snap.CreatedAt
+	r.reserved = snap.Seats
+	r.history = snap.Chat
+	r.keepUntil = time.Now().Add(grace)
+	r.clock.remaining = snap.Clocks
+	r.hinted = snap.Hinted
+
+	for _, m := range snap.Sequence {
+		if err := r.game.MakeMove(m); err != nil {
+			log.Error("restore room snapshot", "room", snap.ID, "move", m, "error", err)
+			break
+		}
+	}
+
+	return r
+}
+
+// SnapshotAll persists every room, e.g. on graceful shutdown.
+func (rm *RoomManager) SnapshotAll() {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	for _, r := range rm.rooms {
+		r.mu.Lock()
+		r.persistLocked()
+		r.mu.Unlock()
+	}
+}
+
+// Restore loads the rooms saved by a previous run.
+func (rm *RoomManager) Restore() error {
+	blobs, err := rm.store.LoadRoomSnapshots()
+	if err != nil {
+		return err
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	for _, data := range blobs {
+		var snap roomSnapshot
+		if err := json.Unmarshal(data, &snap); err != nil {
+			log.Error("decode room snapshot", "error", err)
+			continue
+		}
+		room := restoreRoom(snap, rm.limits.RestoreGrace.D(), rm.store, rm.moderator)
+		rm.rooms[snap.ID] = room
+		events.Emit(evRoomCreated, "room", snap.ID, "variant", room.settings.Variant,
+			"clock", room.settings.TimeControl.String(), "restored", true, "moves", snap.MoveCount)
+	}
+	return nil
+}
+
+// ReservedFor lists rooms holding a seat for userID in a game that has
+// moves on the board and is not finished.
+func (rm *RoomManager) ReservedFor(userID string) []string {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	var ids []string
+	for id, r := range rm.rooms {
+		r.mu.RLock()
+		if (r.reserved[0] == userID || r.reserved[1] == userID) && r.game.MoveCount > 0 && !r.game.IsOver() {
+			ids = append(ids, id)
+		}
+		r.mu.RUnlock()
+	}
+	return ids
+}
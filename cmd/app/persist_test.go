@@ -0,0 +1,131 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jwc20/ssh-ttt/config"
+)
+
+func snapshotCount(t *testing.T, store *SQLiteStore) int {
+	t.Helper()
+	blobs, err := store.LoadRoomSnapshots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(blobs)
+}
+
+func TestRoomSnapshots(t *testing.T) {
+	t.Run("a game lost on time drops its snapshot", func(t *testing.T) {
+		store := newTestStore(t)
+		settings := DefaultRoomSettings()
+		settings.TimeControl = TimeControl{Base: time.Minute}
+		r := NewRoom("blitz", settings, store, nil)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.started = true
+		r.clock.remaining = [2]time.Duration{time.Minute, 0}
+		r.clock.turnStart = time.Now()
+		if err := r.game.MakeMove(4); err != nil {
+			t.Fatal(err)
+		}
+		r.persistLocked()
+		if got := snapshotCount(t, store); got != 1 {
+			t.Fatalf("got %d snapshots before the flag, want 1", got)
+		}
+
+		if !r.flaggedLocked() {
+			t.Fatal("O has no time left but was not flagged")
+		}
+		if got := snapshotCount(t, store); got != 0 {
+			t.Errorf("got %d snapshots after the flag, want 0", got)
+		}
+	})
+	t.Run("archiving a finished room drops its snapshot", func(t *testing.T) {
+		store := newTestStore(t)
+		rm := NewRoomManager(store, nil, config.Default().Rooms)
+		r, err := rm.Create("done", DefaultRoomSettings(), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.SaveRoomSnapshot(r.ID, []byte(`{"ID":"done"}`)); err != nil {
+			t.Fatal(err)
+		}
+		r.mu.Lock()
+		r.finishedAt = time.Now().Add(-time.Hour)
+		r.mu.Unlock()
+
+		rm.ArchiveFinished()
+		if rm.Get(r.ID) != nil {
+			t.Error("the finished room was not archived")
+		}
+		if got := snapshotCount(t, store); got != 0 {
+			t.Errorf("got %d snapshots after archiving, want 0", got)
+		}
+	})
+	t.Run("an unfinished game survives a restart", func(t *testing.T) {
+		store := newTestStore(t)
+		settings := DefaultRoomSettings()
+		settings.TimeControl = TimeControl{Base: time.Minute, Increment: time.Second}
+		rm := NewRoomManager(store, nil, config.Default().Rooms)
+		r, err := rm.Create("saved", settings, "ann")
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Reserve("ann", "bob")
+		r.mu.Lock()
+		for _, m := range []int{4, 0, 8} {
+			if err := r.game.MakeMove(m); err != nil {
+				t.Fatal(err)
+			}
+		}
+		r.clock.remaining = [2]time.Duration{40 * time.Second, 50 * time.Second}
+		r.history = []RoomChatMsg{{Sender: "ann", Text: "gl"}}
+		r.mu.Unlock()
+		rm.SnapshotAll()
+
+		restored := NewRoomManager(store, nil, config.Default().Rooms)
+		if err := restored.Restore(); err != nil {
+			t.Fatal(err)
+		}
+		got := restored.rooms["saved"]
+		if got == nil {
+			t.Fatal("the room was not restored")
+		}
+		got.mu.RLock()
+		defer got.mu.RUnlock()
+		if !slices.Equal(got.game.Sequence, []int{4, 0, 8}) || got.game.CurrentTurn() != 'O' {
+			t.Errorf("got moves %v with %c to move, want [4 0 8] with O to move", got.game.Sequence, got.game.CurrentTurn())
+		}
+		if got.reserved != [2]string{"ann", "bob"} {
+			t.Errorf("got seats %v, want ann and bob", got.reserved)
+		}
+		if got.settings != settings {
+			t.Errorf("got settings %+v, want %+v", got.settings, settings)
+		}
+		if got.clock.remaining != [2]time.Duration{40 * time.Second, 50 * time.Second} {
+			t.Errorf("got clocks %v, want 40s and 50s", got.clock.remaining)
+		}
+		if len(got.history) != 1 || got.history[0].Text != "gl" {
+			t.Errorf("got chat %v, want the one message", got.history)
+		}
+		if got.started {
+			t.Error("a restored game should wait for its players")
+		}
+	})
+
+	t.Run("an empty game is not saved", func(t *testing.T) {
+		store := newTestStore(t)
+		rm := NewRoomManager(store, nil, config.Default().Rooms)
+		if _, err := rm.Create("empty", DefaultRoomSettings(), "ann"); err != nil {
+			t.Fatal(err)
+		}
+		rm.SnapshotAll()
+		if got := snapshotCount(t, store); got != 0 {
+			t.Errorf("got %d snapshots, want 0", got)
+		}
+	})
+}
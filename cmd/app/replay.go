@@ -0,0 +1,499 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+	ttt "github.com/jwc20/ssh-ttt"
+	"github.com/jwc20/ssh-ttt/variant"
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Game Replays
+// ─────────────────────────────────────────────────────────────────────────────
+
+var errNoSuchGame = errors.New("no such game")
+
+const (
+	// replayListSize is how many recent games the replay tab lists.
+	replayListSize = 20
+	// maxNotationBytes caps a game sent to the HTTP API or pasted in.
+	maxNotationBytes = 64 << 10
+)
+
+// gameSummary is one finished game in the replay tab's list.
+type gameSummary struct {
+	ID               int64
+	PlayerX, PlayerO string
+	Winner           string
+	Variant          string
+	Moves            int
+	FinishedAt       time.Time
+}
+
+// RecentGames returns the last limit games finished, newest first.
+func (s *SQLiteStore) RecentGames(limit int) ([]gameSummary, error) {
+	defer storeTimer("recent_games")()
+
+	rows, err := s.db.Query(`
+		SELECT id, player_x, player_o, winner, variant, moves, finished_at
+		FROM games
+		ORDER BY id DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var games []gameSummary
+	for rows.Next() {
+		var g gameSummary
+		if err := rows.Scan(&g.ID, &g.PlayerX, &g.PlayerO, &g.Winner, &g.Variant, &g.Moves, &g.FinishedAt); err != nil {
+			return nil, err
+		}
+		games = append(games, g)
+	}
+	return games, rows.Err()
+}
+
+// LoadGame returns a finished game as the library's notation records it.
+// The room and how the game ended go in extra tags.
+func (s *SQLiteStore) LoadGame(id int64) (ttt.GameRecord, error) {
+	defer storeTimer("load_game")()
+
+	var (
+		rec                                     ttt.GameRecord
+		room, winner, options, sequence, reason string
+		finishedAt                              time.Time
+	)
+	err := s.db.QueryRow(`
+		SELECT room, player_x, player_o, winner, variant, options, sequence, reason, finished_at
+		FROM games
+		WHERE id = ?
+	`, id).Scan(&room, &rec.PlayerX, &rec.PlayerO, &winner, &rec.Variant, &options, &sequence, &reason, &finishedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return rec, errNoSuchGame
+	}
+	if err != nil {
+		return rec, err
+	}
+
+	if options != "" {
+		if rec.Board, err = variant.ParseOptions(options); err != nil {
+			return rec, err
+		}
+	}
+	rec.Date = finishedAt.UTC().Format(ttt.DateFormat)
+	rec.Moves = decodeSequence(sequence)
+	switch winner {
+	case "X":
+		rec.Result = ttt.ResultXWins
+	case "O":
+		rec.Result = ttt.ResultOWins
+	default:
+		rec.Result = ttt.ResultDraw
+	}
+	rec.Tags = []ttt.Tag{{Name: "Room", Value: room}}
+	if reason != "" {
+		rec.Tags = append(rec.Tags, ttt.Tag{Name: "Termination", Value: reason})
+	}
+	return rec, nil
+}
+
+// ── HTTP API ─────────────────────────────────────────────────────────────────
+
+// notationResult is what the API makes of a game in notation: the record,
+// each move written out and the position the moves reach.
+type notationResult struct {
+	Variant  string   `json:"variant"`
+	Board    string   `json:"board,omitempty"`
+	Setup    string   `json:"setup,omitempty"`
+	Date     string   `json:"date,omitempty"`
+	PlayerX  string   `json:"player_x,omitempty"`
+	PlayerO  string   `json:"player_o,omitempty"`
+	Result   string   `json:"result"`
+	Moves    []int    `json:"moves"`
+	Notation []string `json:"notation"`
+	Cells    string   `json:"cells"`
+	Over     bool     `json:"over"`
+}
+
+func newNotationResult(rec ttt.GameRecord) (notationResult, error) {
+	s, err := rec.NewState()
+	if err != nil {
+		return notationResult{}, err
+	}
+	res := notationResult{
+		Variant: rec.Variant, Date: rec.Date, PlayerX: rec.PlayerX, PlayerO: rec.PlayerO,
+		Result: rec.Result, Moves: rec.Moves, Notation: make([]string, len(rec.Moves)),
+	}
+	if rec.Board != (variant.Options{}) {
+		res.Board = rec.Board.String()
+	}
+	if rec.Setup != nil {
+		res.Setup = rec.Setup.Notation()
+	}
+	for i, m := range rec.Moves {
+		res.Notation[i] = ttt.FormatMove(s, m)
+		if err := s.Play(m); err != nil {
+			return notationResult{}, err
+		}
+	}
+	res.Cells, res.Over = string(s.Cells()), s.Over()
+	return res, nil
+}
+
+// replayRoutes serves finished games in notation and checks notation sent
+// in. GET /api/games/{id} answers with the notation itself, or JSON when
+// asked for with ?format=json; POST /api/notation answers with JSON.
+func replayRoutes(mux *http.ServeMux, store *SQLiteStore) {
+	mux.HandleFunc("GET /api/games/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, errNoSuchGame.Error(), http.StatusNotFound)
+			return
+		}
+		rec, err := store.LoadGame(id)
+		switch {
+		case errors.Is(err, errNoSuchGame):
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		case err != nil:
+			log.Error("load game", "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+
+		if r.URL.Query().Get("format") == "json" {
+			res, err := newNotationResult(rec)
+			if err != nil {
+				log.Error("replay game", "id", id, "error", err)
+				http.Error(w, "internal error", http.StatusInternalServerError)
+				return
+			}
+			writeJSON(w, res)
+			return
+		}
+		text, err := rec.MarshalText()
+		if err != nil {
+			log.Error("write notation", "id", id, "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("content-type", "text/plain; charset=utf-8")
+		w.Write(text)
+	})
+
+	mux.HandleFunc("POST /api/notation", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxNotationBytes))
+		if err != nil {
+			http.Error(w, "game too long", http.StatusRequestEntityTooLarge)
+			return
+		}
+		rec, err := ttt.ParseGame(string(body))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		res, err := newNotationResult(rec)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, res)
+	})
+}
+
+// ── Replay Model ─────────────────────────────────────────────────────────────
+
+type (
+	// ReplayListMsg carries the recent games for the replay tab.
+	ReplayListMsg struct {
+		Games []gameSummary
+		Err   error
+	}
+	// ReplayGameMsg carries the game picked from the list.
+	ReplayGameMsg struct {
+		ID     int64
+		Record ttt.GameRecord
+		Err    error
+	}
+)
+
+type replayMode int
+
+const (
+	replayBrowse replayMode = iota
+	replayWatch
+	replayImport
+)
+
+// replayModel lists recent games, steps through one move at a time and
+// reads games pasted in notation.
+type replayModel struct {
+	store  *SQLiteStore
+	games  []gameSummary
+	cursor int
+	mode   replayMode
+	err    error
+
+	record ttt.GameRecord
+	// positions[i] is the board after i moves and cells[i] the cell move
+	// i+1 went on.
+	positions []variant.State
+	cells     []int
+	step      int
+	notation  bool
+
+	paste textarea.Model
+}
+
+func newReplayModel(store *SQLiteStore) replayModel {
+	ta := textarea.New()
+	ta.Placeholder = "Paste a game in notation..."
+	ta.CharLimit = maxNotationBytes
+	ta.SetWidth(72)
+	ta.SetHeight(10)
+	return replayModel{store: store, paste: ta}
+}
+
+// Load fetches the recent games off the UI goroutine.
+func (m replayModel) Load() tea.Cmd {
+	store := m.store
+	return func() tea.Msg {
+		games, err := store.RecentGames(replayListSize)
+		return ReplayListMsg{Games: games, Err: err}
+	}
+}
+
+// Importing reports whether the paste box has the keyboard.
+func (m replayModel) Importing() bool { return m.mode == replayImport }
+
+// watch replays rec's moves so they can be stepped through.
+func (m replayModel) watch(rec ttt.GameRecord) (replayModel, error) {
+	s, err := rec.NewState()
+	if err != nil {
+		return m, err
+	}
+	positions, cells := []variant.State{s.Clone()}, make([]int, 0, len(rec.Moves))
+	for _, mv := range rec.Moves {
+		cells = append(cells, moveCell(s, mv))
+		if err := s.Play(mv); err != nil {
+			return m, err
+		}
+		positions = append(positions, s.Clone())
+	}
+	m.record, m.positions, m.cells = rec, positions, cells
+	m.mode, m.step, m.notation, m.err = replayWatch, 0, false, nil
+	return m, nil
+}
+
+func (m replayModel) Update(msg tea.Msg) (replayModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case ReplayListMsg:
+		m.games, m.err = msg.Games, msg.Err
+		m.cursor = min(m.cursor, max(0, len(m.games)-1))
+
+	case ReplayGameMsg:
+		if m.mode != replayBrowse || m.cursor >= len(m.games) || m.games[m.cursor].ID != msg.ID {
+			return m, nil
+		}
+		if msg.Err != nil {
+			m.err = msg.Err
+			return m, nil
+		}
+		var err error
+		if m, err = m.watch(msg.Record); err != nil {
+			m.err = err
+		}
+
+	case tea.KeyMsg:
+		switch m.mode {
+		case replayImport:
+			return m.handleImportInput(msg)
+		case replayWatch:
+			return m.handleWatchInput(msg), nil
+		}
+		return m.handleBrowseInput(msg)
+	}
+	return m, nil
+}
+
+func (m replayModel) handleBrowseInput(msg tea.KeyMsg) (replayModel, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		m.cursor = max(0, m.cursor-1)
+	case "down", "j":
+		m.cursor = min(max(0, len(m.games)-1), m.cursor+1)
+	case "enter":
+		if m.cursor >= len(m.games) {
+			return m, nil
+		}
+		store, id := m.store, m.games[m.cursor].ID
+		return m, func() tea.Msg {
+			rec, err := store.LoadGame(id)
+			return ReplayGameMsg{ID: id, Record: rec, Err: err}
+		}
+	case "i":
+		m.mode, m.err = replayImport, nil
+		m.paste.Reset()
+		return m, m.paste.Focus()
+	case "r":
+		return m, m.Load()
+	}
+	return m, nil
+}
+
+func (m replayModel) handleWatchInput(msg tea.KeyMsg) replayModel {
+	switch msg.String() {
+	case "left", "h":
+		m.step = max(0, m.step-1)
+	case "right", "l":
+		m.step = min(len(m.cells), m.step+1)
+	case "home":
+		m.step = 0
+	case "end":
+		m.step = len(m.cells)
+	case "x":
+		m.notation = !m.notation
+	case "esc":
+		m.mode, m.positions, m.cells = replayBrowse, nil, nil
+	}
+	return m
+}
+
+// handleImportInput reads the paste box with ctrl+d and leaves it with esc.
+func (m replayModel) handleImportInput(msg tea.KeyMsg) (replayModel, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+d":
+		rec, err := ttt.ParseGame(m.paste.Value())
+		if err == nil {
+			m, err = m.watch(rec)
+		}
+		if err != nil {
+			m.err = err
+			return m, nil
+		}
+		m.paste.Blur()
+		return m, nil
+	case "esc":
+		m.mode, m.err = replayBrowse, nil
+		m.paste.Blur()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.paste, cmd = m.paste.Update(msg)
+	return m, cmd
+}
+
+// Help is the key help for the replay tab's current screen.
+func (m replayModel) Help() string {
+	switch m.mode {
+	case replayImport:
+		return "  ctrl+d: load  esc: cancel  "
+	case replayWatch:
+		return "  ←/→: step  home/end: jump  x: notation  esc: back  "
+	}
+	return "  ↑/↓: navigate  enter: watch  i: import  r: refresh  "
+}
+
+func (m replayModel) View() string {
+	switch m.mode {
+	case replayImport:
+		return m.viewImport()
+	case replayWatch:
+		return m.viewWatch()
+	}
+
+	var b strings.Builder
+	switch {
+	case m.err != nil:
+		b.WriteString("  Couldn't load the games, try again later.\n")
+	case len(m.games) == 0:
+		b.WriteString("  No games recorded yet.\n")
+	}
+	for i, g := range m.games {
+		result := "draw"
+		switch g.Winner {
+		case "X":
+			result = g.PlayerX + " won"
+		case "O":
+			result = g.PlayerO + " won"
+		}
+		line := fmt.Sprintf("%s  %-12s %s vs %s, %s in %d moves", g.FinishedAt.Local().Format("01-02 15:04"),
+			g.Variant, g.PlayerX, g.PlayerO, result, g.Moves)
+		if i == m.cursor {
+			b.WriteString(lobbySelectedItem.Render("▸ "+line) + "\n")
+		} else {
+			b.WriteString(lobbyItemStyle.Render("  "+line) + "\n")
+		}
+	}
+	return b.String()
+}
+
+func (m replayModel) viewImport() string {
+	var b strings.Builder
+	b.WriteString("  Paste a game, e.g.\n\n")
+	b.WriteString("    [Variant \"classic\"]  1. b2 a3 2. c1 *\n\n")
+	for _, line := range strings.Split(m.paste.View(), "\n") {
+		b.WriteString("  " + line + "\n")
+	}
+	if m.err != nil {
+		b.WriteString("\n  " + focusLabel.Render(m.err.Error()) + "\n")
+	}
+	return b.String()
+}
+
+func (m replayModel) viewWatch() string {
+	rec := m.record
+	var b strings.Builder
+	header := fmt.Sprintf("%s vs %s", orUnknown(rec.PlayerX), orUnknown(rec.PlayerO))
+	b.WriteString(fmt.Sprintf("  %s · %s · %s · %s\n\n", bracketHeader.Render(header), rec.Variant,
+		orUnknown(rec.Date), rec.Result))
+
+	if m.notation {
+		text, err := rec.MarshalText()
+		if err != nil {
+			return b.String() + "  " + err.Error() + "\n"
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(string(text), "\n"), "\n") {
+			if line != "" {
+				line = "  " + line
+			}
+			b.WriteString(line + "\n")
+		}
+		return b.String()
+	}
+
+	v := roomModel{board: m.positions[m.step], focus: paneChat, hint: -1}
+	if m.step > 0 {
+		v.hint = m.cells[m.step-1]
+	}
+	b.WriteString(v.viewBoard() + "\n")
+	if m.step == 0 {
+		b.WriteString(fmt.Sprintf("  Start, %d moves to go\n", len(m.cells)))
+	} else {
+		before := m.positions[m.step-1]
+		b.WriteString(fmt.Sprintf("  Move %d of %d: %c %s\n", m.step, len(m.cells),
+			before.Turn(), ttt.FormatMove(before, rec.Moves[m.step-1])))
+	}
+	return b.String()
+}
+
+// orUnknown shows an unknown name or date as a question mark.
+func orUnknown(name string) string {
+	if name == "" {
+		return "?"
+	}
+	return name
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Room List Ordering & Filtering
+// ─────────────────────────────────────────────────────────────────────────────
+
+type roomSort int
+
+const (
+	roomSortCreated roomSort = iota
+	roomSortStatus
+	roomSortPlayers
+)
+
+func (s roomSort) String() string {
+	switch s {
+	case roomSortStatus:
+		return "status"
+	case roomSortPlayers:
+		return "players"
+	default:
+		return "created"
+	}
+}
+
+type roomFilter int
+
+const (
+	roomFilterAll roomFilter = iota
+	roomFilterWaiting
+	roomFilterPlaying
+	roomFilterFinished
+)
+
+func (f roomFilter) String() string {
+	switch f {
+	case roomFilterWaiting:
+		return "waiting"
+	case roomFilterPlaying:
+		return "playing"
+	case roomFilterFinished:
+		return "finished"
+	default:
+		return "all"
+	}
+}
+
+func (f roomFilter) match(status string) bool {
+	return f == roomFilterAll || f.String() == status
+}
+
+var statusRank = map[string]int{"waiting": 0, "playing": 1, "finished": 2}
+
+// sortRooms orders rooms in place. Creation time and then ID are always the
+// tie-breakers so the order is stable across updates.
+func sortRooms(rooms []RoomInfo, by roomSort) {
+	sort.SliceStable(rooms, func(i, j int) bool {
+		a, b := rooms[i], rooms[j]
+		switch by {
+		case roomSortStatus:
+			if statusRank[a.Status] != statusRank[b.Status] {
+				return statusRank[a.Status] < statusRank[b.Status]
+			}
+		case roomSortPlayers:
+			if a.Players != b.Players {
+				return a.Players > b.Players
+			}
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+}
+
+// filterRooms returns the rooms matching both the status filter and the
+// fuzzy search query, in their original order.
+func filterRooms(rooms []RoomInfo, filter roomFilter, query string) []RoomInfo {
+	out := make([]RoomInfo, 0, len(rooms))
+	for _, r := range rooms {
+		if filter.match(r.Status) && fuzzyMatch(r.ID, query) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// fuzzyMatch reports whether every rune of query appears in s in order,
+// ignoring case and whitespace in the query.
+func fuzzyMatch(s, query string) bool {
+	want := []rune(strings.ToLower(strings.Join(strings.Fields(query), "")))
+	if len(want) == 0 {
+		return true
+	}
+	for _, r := range strings.ToLower(s) {
+		if r == want[0] {
+			want = want[1:]
+			if len(want) == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func roomIDs(rooms []RoomInfo) []string {
+	ids := make([]string, len(rooms))
+	for i, r := range rooms {
+		ids[i] = r.ID
+	}
+	return ids
+}
+
+func TestSortRooms(t *testing.T) {
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	rooms := []RoomInfo{
+		{ID: "c", Players: 1, Status: "finished", CreatedAt: base},
+		{ID: "a", Players: 2, Status: "playing", CreatedAt: base.Add(time.Minute)},
+		{ID: "b", Players: 1, Status: "waiting", CreatedAt: base.Add(time.Minute)},
+		{ID: "d", Players: 0, Status: "waiting", CreatedAt: base.Add(-time.Minute)},
+	}
+	tests := []struct {
+		by   roomSort
+		want []string
+	}{
+		{roomSortCreated, []string{"d", "c", "a", "b"}},
+		{roomSortStatus, []string{"d", "b", "a", "c"}},
+		{roomSortPlayers, []string{"a", "c", "b", "d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.by.String(), func(t *testing.T) {
+			got := slices.Clone(rooms)
+			sortRooms(got, tt.by)
+			if ids := roomIDs(got); !slices.Equal(ids, tt.want) {
+				t.Errorf("got %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterRooms(t *testing.T) {
+	rooms := []RoomInfo{
+		{ID: "Blitz Night", Status: "playing"},
+		{ID: "beginners", Status: "waiting"},
+		{ID: "old-game", Status: "finished"},
+		{ID: "bnw", Status: "waiting"},
+	}
+	tests := []struct {
+		name   string
+		filter roomFilter
+		query  string
+		want   []string
+	}{
+		{"everything", roomFilterAll, "", []string{"Blitz Night", "beginners", "old-game", "bnw"}},
+		{"by status", roomFilterWaiting, "", []string{"beginners", "bnw"}},
+		{"by query", roomFilterAll, "bn", []string{"Blitz Night", "beginners", "bnw"}},
+		{"by status and query", roomFilterPlaying, "bn", []string{"Blitz Night"}},
+		{"nothing matches", roomFilterFinished, "bn", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := roomIDs(filterRooms(rooms, tt.filter, tt.query)); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		s, query string
+		want     bool
+	}{
+		{"anything", "", true},
+		{"anything", "   ", true},
+		{"Blitz Night", "bn", true},
+		{"Blitz Night", "BLITZ", true},
+		{"Blitz Night", "bl ni", true},
+		{"Blitz Night", "nb", false},
+		{"Blitz Night", "blitzz", false},
+		{"", "a", false},
+	}
+	for _, tt := range tests {
+		if got := fuzzyMatch(tt.s, tt.query); got != tt.want {
+			t.Errorf("fuzzyMatch(%q, %q) = %v, want %v", tt.s, tt.query, got, tt.want)
+		}
+	}
+}
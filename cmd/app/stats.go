@@ -0,0 +1,483 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Player Statistics
+// ─────────────────────────────────────────────────────────────────────────────
+
+var errNoSuchPlayer = errors.New("no such player")
+
+// gameRecord is one row of the games table, as seen by the stats.
+type gameRecord struct {
+	PlayerX, PlayerO string
+	Winner           string
+	Variant          string
+	Moves            int
+	Sequence         string
+	FinishedAt       time.Time
+}
+
+// PlayerGames returns every game name played, oldest first.
+func (s *SQLiteStore) PlayerGames(name string) ([]gameRecord, error) {
+	defer storeTimer("player_games")()
+
+	rows, err := s.db.Query(`
+		SELECT player_x, player_o, winner, variant, moves, sequence, finished_at
+		FROM games
+		WHERE player_x = ? OR player_o = ?
+		ORDER BY id
+	`, name, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var games []gameRecord
+	for rows.Next() {
+		var g gameRecord
+		if err := rows.Scan(&g.PlayerX, &g.PlayerO, &g.Winner, &g.Variant, &g.Moves, &g.Sequence, &g.FinishedAt); err != nil {
+			return nil, err
+		}
+		games = append(games, g)
+	}
+	return games, rows.Err()
+}
+
+type SideStats struct {
+	Played int `json:"played"`
+	Wins   int `json:"wins"`
+	Draws  int `json:"draws"`
+	Losses int `json:"losses"`
+}
+
+func (s *SideStats) add(result int) {
+	s.Played++
+	switch {
+	case result > 0:
+		s.Wins++
+	case result < 0:
+		s.Losses++
+	default:
+		s.Draws++
+	}
+}
+
+type PlayerStats struct {
+	Player string `json:"player"`
+	Rating int    `json:"rating"`
+	SideStats
+	AsX SideStats `json:"as_x"`
+	AsO SideStats `json:"as_o"`
+	// CurrentStreak counts the wins since the player's last draw or loss.
+	CurrentStreak int     `json:"current_streak"`
+	BestStreak    int     `json:"best_streak"`
+	AverageMoves  float64 `json:"average_moves"`
+	// FavouriteOpening is the cell the player opens with most often as X,
+	// or -1 if they have never opened a game.
+	FavouriteOpening int `json:"favourite_opening"`
+	Openings         int `json:"openings"`
+}
+
+// computeStats sums up games, oldest first, from name's point of view.
+func computeStats(name string, games []gameRecord) PlayerStats {
+	st := PlayerStats{Player: name, FavouriteOpening: -1}
+	var openings [9]int
+	totalMoves := 0
+
+	for _, g := range games {
+		mark, side := "O", &st.AsO
+		if g.PlayerX == name {
+			mark, side = "X", &st.AsX
+		}
+
+		result := 0
+		switch g.Winner {
+		case "":
+		case mark:
+			result = 1
+		default:
+			result = -1
+		}
+		st.add(result)
+		side.add(result)
+		totalMoves += g.Moves
+
+		// Streaks only follow rated games, so beating the computer can't
+		// build one.
+		switch {
+		case isComputer(g.PlayerX) || isComputer(g.PlayerO):
+		case result > 0:
+			st.CurrentStreak++
+			st.BestStreak = max(st.BestStreak, st.CurrentStreak)
+		default:
+			st.CurrentStreak = 0
+		}
+
+		if mark == "X" && g.Variant == "classic" {
+			if moves := decodeSequence(g.Sequence); len(moves) > 0 && moves[0] < len(openings) {
+				openings[moves[0]]++
+			}
+		}
+	}
+
+	if st.Played > 0 {
+		st.AverageMoves = float64(totalMoves) / float64(st.Played)
+	}
+	for cell, n := range openings {
+		if n > st.Openings {
+			st.FavouriteOpening, st.Openings = cell, n
+		}
+	}
+	return st
+}
+
+// encodeSequence stores the moves of a game in play order, separated by
+// spaces.
+func encodeSequence(moves []int) string {
+	fields := make([]string, len(moves))
+	for i, m := range moves {
+		fields[i] = strconv.Itoa(m)
+	}
+	return strings.Join(fields, " ")
+}
+
+// decodeSequence reads a sequence written by encodeSequence.
+func decodeSequence(s string) []int {
+	fields := strings.Fields(s)
+	moves := make([]int, 0, len(fields))
+	for _, f := range fields {
+		m, err := strconv.Atoi(f)
+		if err != nil || m < 0 {
+			return nil
+		}
+		moves = append(moves, m)
+	}
+	return moves
+}
+
+var squareNames = [9]string{
+	"top left", "top", "top right",
+	"left", "centre", "right",
+	"bottom left", "bottom", "bottom right",
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Achievements
+// ─────────────────────────────────────────────────────────────────────────────
+
+type Achievement struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// earned reports whether the winner of result, with stats including
+	// that game, has earned the achievement.
+	earned func(result GameResult, stats PlayerStats) bool
+}
+
+type EarnedAchievement struct {
+	Achievement
+	Player   string    `json:"-"`
+	EarnedAt time.Time `json:"earned_at"`
+}
+
+// achievements are all won, never lost, so only winners are checked.
+var achievements = []Achievement{
+	{
+		ID: "first-win", Title: "First Blood", Description: "Win your first game",
+		earned: func(GameResult, PlayerStats) bool { return true },
+	},
+	{
+		ID: "streak-10", Title: "Unstoppable", Description: "Win 10 games in a row",
+		earned: func(_ GameResult, st PlayerStats) bool { return st.CurrentStreak >= 10 },
+	},
+	{
+		ID: "beat-hard", Title: "Machine Breaker", Description: "Beat the computer on hard",
+		earned: func(g GameResult, _ PlayerStats) bool { return g.PlayerO == computerHard.Name() },
+	},
+	{
+		ID: "fastest-win", Title: "Speedrun", Description: "Win with only three of your marks on the board",
+		earned: func(g GameResult, _ PlayerStats) bool {
+			return g.Variant == "classic" && g.Reason == "line" && strings.Count(g.Board, g.Winner) == 3
+		},
+	},
+}
+
+func achievementByID(id string) (Achievement, bool) {
+	for _, a := range achievements {
+		if a.ID == id {
+			return a, true
+		}
+	}
+	return Achievement{}, false
+}
+
+// AwardAchievements grants the winner of a game the achievements it earned
+// them and returns the new ones. Games played with hints earn nothing.
+func (s *SQLiteStore) AwardAchievements(g GameResult) []EarnedAchievement {
+	winner := g.PlayerX
+	if g.Winner == "O" {
+		winner = g.PlayerO
+	}
+	if g.Winner == "" || winner == "" || isComputer(winner) || g.Hinted {
+		return nil
+	}
+
+	games, err := s.PlayerGames(winner)
+	if err != nil {
+		log.Error("award achievements", "player", winner, "error", err)
+		return nil
+	}
+	stats := computeStats(winner, games)
+
+	defer storeTimer("award_achievements")()
+	var earned []EarnedAchievement
+	for _, a := range achievements {
+		if !a.earned(g, stats) {
+			continue
+		}
+		res, err := s.db.Exec("INSERT INTO achievements (player, achievement) VALUES (?, ?) ON CONFLICT DO NOTHING", winner, a.ID)
+		if err != nil {
+			log.Error("award achievement", "player", winner, "achievement", a.ID, "error", err)
+			continue
+		}
+		if n, _ := res.RowsAffected(); n == 1 {
+			earned = append(earned, EarnedAchievement{Achievement: a, Player: winner, EarnedAt: time.Now()})
+			events.Emit(evAchievement, "user", winner, "achievement", a.ID, "room", g.Room)
+		}
+	}
+	return earned
+}
+
+// PlayerAchievements lists what name has earned, oldest first.
+func (s *SQLiteStore) PlayerAchievements(name string) ([]EarnedAchievement, error) {
+	defer storeTimer("player_achievements")()
+
+	rows, err := s.db.Query("SELECT achievement, earned_at FROM achievements WHERE player = ? ORDER BY earned_at, rowid", name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	earned := []EarnedAchievement{}
+	for rows.Next() {
+		var (
+			id string
+			at time.Time
+		)
+		if err := rows.Scan(&id, &at); err != nil {
+			return nil, err
+		}
+		if a, ok := achievementByID(id); ok {
+			earned = append(earned, EarnedAchievement{Achievement: a, Player: name, EarnedAt: at})
+		}
+	}
+	return earned, rows.Err()
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Profiles
+// ─────────────────────────────────────────────────────────────────────────────
+
+type PlayerProfile struct {
+	Stats  PlayerStats         `json:"stats"`
+	Earned []EarnedAchievement `json:"achievements"`
+	Locked []Achievement       `json:"locked_achievements"`
+}
+
+// LoadProfile gathers a player's stats and achievements. Players who have
+// never finished a game don't have one.
+func (s *SQLiteStore) LoadProfile(name string) (PlayerProfile, error) {
+	var p PlayerProfile
+	err := s.db.QueryRow("SELECT rating FROM players WHERE name = ?", name).Scan(&p.Stats.Rating)
+	if errors.Is(err, sql.ErrNoRows) {
+		return p, errNoSuchPlayer
+	}
+	if err != nil {
+		return p, err
+	}
+
+	games, err := s.PlayerGames(name)
+	if err != nil {
+		return p, err
+	}
+	rating := p.Stats.Rating
+	p.Stats = computeStats(name, games)
+	p.Stats.Rating = rating
+
+	if p.Earned, err = s.PlayerAchievements(name); err != nil {
+		return p, err
+	}
+	p.Locked = []Achievement{}
+	have := make(map[string]bool, len(p.Earned))
+	for _, e := range p.Earned {
+		have[e.ID] = true
+	}
+	for _, a := range achievements {
+		if !have[a.ID] {
+			p.Locked = append(p.Locked, a)
+		}
+	}
+	return p, nil
+}
+
+// profileRoutes serves a player's stats and achievements as JSON.
+func profileRoutes(mux *http.ServeMux, store *SQLiteStore) {
+	mux.HandleFunc("GET /api/players/{name}/stats", func(w http.ResponseWriter, r *http.Request) {
+		p, err := store.LoadProfile(r.PathValue("name"))
+		switch {
+		case errors.Is(err, errNoSuchPlayer):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case err != nil:
+			log.Error("load profile", "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+		default:
+			writeJSON(w, p)
+		}
+	})
+}
+
+// ── Profile Model ────────────────────────────────────────────────────────────
+
+type ProfileMsg struct {
+	Player  string
+	Profile PlayerProfile
+	Err     error
+}
+
+type profileModel struct {
+	store   *SQLiteStore
+	userID  string
+	player  string
+	profile PlayerProfile
+	err     error
+	lookup  textinput.Model
+	finding bool
+}
+
+func newProfileModel(store *SQLiteStore, userID string) profileModel {
+	ti := textinput.New()
+	ti.Prompt = "player: "
+	ti.CharLimit = 32
+	ti.Width = 20
+	return profileModel{store: store, userID: userID, player: userID, lookup: ti}
+}
+
+// Load fetches the profile being shown off the UI goroutine.
+func (m profileModel) Load() tea.Cmd {
+	store, player := m.store, m.player
+	return func() tea.Msg {
+		p, err := store.LoadProfile(player)
+		return ProfileMsg{Player: player, Profile: p, Err: err}
+	}
+}
+
+// Finding reports whether the player name prompt has the keyboard.
+func (m profileModel) Finding() bool { return m.finding }
+
+func (m profileModel) Update(msg tea.Msg) (profileModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case ProfileMsg:
+		if msg.Player == m.player {
+			m.profile, m.err = msg.Profile, msg.Err
+		}
+
+	case tea.KeyMsg:
+		if m.finding {
+			switch msg.String() {
+			case "enter":
+				m.finding = false
+				m.lookup.Blur()
+				if name := strings.TrimSpace(m.lookup.Value()); name != "" {
+					m.player = name
+					return m, m.Load()
+				}
+				return m, nil
+			case "esc":
+				m.finding = false
+				m.lookup.Blur()
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.lookup, cmd = m.lookup.Update(msg)
+			return m, cmd
+		}
+
+		switch msg.String() {
+		case "/":
+			m.finding = true
+			m.lookup.Reset()
+			m.lookup.Focus()
+			return m, textinput.Blink
+		case "m":
+			m.player = m.userID
+			return m, m.Load()
+		case "r":
+			return m, m.Load()
+		}
+	}
+	return m, nil
+}
+
+var (
+	achievementEarned = lipgloss.NewStyle().Foreground(lipgloss.Color("3")).Bold(true)
+	achievementLocked = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+)
+
+func (m profileModel) View() string {
+	var b strings.Builder
+	if m.finding {
+		b.WriteString("  " + m.lookup.View() + "\n\n")
+	}
+
+	switch {
+	case errors.Is(m.err, errNoSuchPlayer):
+		b.WriteString(fmt.Sprintf("  %s hasn't finished a game yet.\n", m.player))
+		return b.String()
+	case m.err != nil:
+		b.WriteString("  Couldn't load the profile, try again later.\n")
+		return b.String()
+	}
+
+	st := m.profile.Stats
+	b.WriteString(fmt.Sprintf("  %s · rating %d\n\n", bracketHeader.Render(m.player), st.Rating))
+	b.WriteString(fmt.Sprintf("  %-8s %s\n", "Overall", viewSideStats(st.SideStats)))
+	b.WriteString(fmt.Sprintf("  %-8s %s\n", "As X", viewSideStats(st.AsX)))
+	b.WriteString(fmt.Sprintf("  %-8s %s\n\n", "As O", viewSideStats(st.AsO)))
+	b.WriteString(fmt.Sprintf("  Win streak: %d now, %d best\n", st.CurrentStreak, st.BestStreak))
+	b.WriteString(fmt.Sprintf("  Average game: %.1f moves\n", st.AverageMoves))
+	if st.FavouriteOpening >= 0 {
+		b.WriteString(fmt.Sprintf("  Favourite opening: %s (%d times)\n", squareNames[st.FavouriteOpening], st.Openings))
+	}
+
+	b.WriteString(fmt.Sprintf("\n  Achievements %d/%d\n", len(m.profile.Earned), len(achievements)))
+	for _, a := range m.profile.Earned {
+		line := fmt.Sprintf("  ★ %-16s %-48s %s", a.Title, a.Description, a.EarnedAt.Local().Format("2006-01-02"))
+		b.WriteString(achievementEarned.Render(line) + "\n")
+	}
+	for _, a := range m.profile.Locked {
+		b.WriteString(achievementLocked.Render(fmt.Sprintf("  ☆ %-16s %s", a.Title, a.Description)) + "\n")
+	}
+	return b.String()
+}
+
+func viewSideStats(s SideStats) string {
+	rate := 0.0
+	if s.Played > 0 {
+		rate = float64(s.Wins) / float64(s.Played) * 100
+	}
+	return fmt.Sprintf("%3d games  %3d W  %3d D  %3d L  %3.0f%%", s.Played, s.Wins, s.Draws, s.Losses, rate)
+}
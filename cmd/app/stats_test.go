@@ -0,0 +1,139 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSequenceEncoding(t *testing.T) {
+	tests := []struct {
+		name  string
+		moves []int
+	}{
+		{"an empty game", nil},
+		{"a single move", []int{4}},
+		{"a single move past the ninth cell", []int{40}},
+		{"moves on a large board", []int{0, 40, 9, 63}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := decodeSequence(encodeSequence(tt.moves))
+			if !slices.Equal(got, tt.moves) {
+				t.Errorf("round trip of %v gave %v", tt.moves, got)
+			}
+		})
+	}
+
+	t.Run("rejects a malformed sequence", func(t *testing.T) {
+		if got := decodeSequence("4 x 2"); got != nil {
+			t.Errorf("got %v, want nil", got)
+		}
+	})
+}
+
+func TestComputeStats(t *testing.T) {
+	win := func(x, o, winner string) gameRecord {
+		return gameRecord{PlayerX: x, PlayerO: o, Winner: winner, Variant: "classic", Moves: 7, Sequence: "4 0 8 2 6 1 7"}
+	}
+
+	t.Run("no games", func(t *testing.T) {
+		st := computeStats("ann", nil)
+		if st.Played != 0 || st.AverageMoves != 0 || st.FavouriteOpening != -1 {
+			t.Errorf("got %+v, want empty stats with no favourite opening", st)
+		}
+	})
+
+	t.Run("counts results from each side", func(t *testing.T) {
+		st := computeStats("ann", []gameRecord{
+			win("ann", "bob", "X"),
+			win("bob", "ann", "X"),
+			win("bob", "ann", "O"),
+			win("ann", "bob", ""),
+		})
+		want := SideStats{Played: 4, Wins: 2, Draws: 1, Losses: 1}
+		if st.SideStats != want {
+			t.Errorf("got %+v, want %+v", st.SideStats, want)
+		}
+		if want := (SideStats{Played: 2, Wins: 1, Draws: 1}); st.AsX != want {
+			t.Errorf("as X got %+v, want %+v", st.AsX, want)
+		}
+		if want := (SideStats{Played: 2, Wins: 1, Losses: 1}); st.AsO != want {
+			t.Errorf("as O got %+v, want %+v", st.AsO, want)
+		}
+		if st.AverageMoves != 7 {
+			t.Errorf("got %v average moves, want 7", st.AverageMoves)
+		}
+	})
+
+	t.Run("streaks end on a draw or loss", func(t *testing.T) {
+		tests := []struct {
+			name          string
+			winners       string
+			current, best int
+		}{
+			{"all wins", "XXX", 3, 3},
+			{"broken by a loss", "XXOX", 1, 2},
+			{"broken by a draw", "XX-", 0, 2},
+			{"longest streak last", "XOXXX", 3, 3},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var games []gameRecord
+				for _, w := range tt.winners {
+					winner := string(w)
+					if w == '-' {
+						winner = ""
+					}
+					games = append(games, win("ann", "bob", winner))
+				}
+				st := computeStats("ann", games)
+				if st.CurrentStreak != tt.current || st.BestStreak != tt.best {
+					t.Errorf("got current %d best %d, want %d and %d", st.CurrentStreak, st.BestStreak, tt.current, tt.best)
+				}
+			})
+		}
+	})
+
+	t.Run("games against the computer leave streaks alone", func(t *testing.T) {
+		games := []gameRecord{win("ann", "bob", "X"), win("ann", computerEasy.Name(), "X"), win("ann", computerEasy.Name(), "O"), win("ann", "bob", "X")}
+		st := computeStats("ann", games)
+		if st.CurrentStreak != 2 || st.BestStreak != 2 {
+			t.Errorf("got current %d best %d, want 2 and 2", st.CurrentStreak, st.BestStreak)
+		}
+		if st.Played != 4 {
+			t.Errorf("got %d games played, want 4", st.Played)
+		}
+	})
+
+	t.Run("favourite opening counts classic games as X", func(t *testing.T) {
+		corner := win("ann", "bob", "X")
+		corner.Sequence = "0 4 8"
+		other := win("ann", "bob", "X")
+		other.Variant = "ultimate"
+		other.Sequence = "0 9 1"
+		st := computeStats("ann", []gameRecord{win("ann", "bob", "X"), corner, corner, other, win("bob", "ann", "X")})
+		if st.FavouriteOpening != 0 || st.Openings != 2 {
+			t.Errorf("got opening %d played %d times, want 0 played 2 times", st.FavouriteOpening, st.Openings)
+		}
+	})
+}
+
+func TestAwardAchievements(t *testing.T) {
+	t.Run("a first win earns first blood", func(t *testing.T) {
+		store := newTestStore(t)
+		g := GameResult{Room: "r", PlayerX: "ann", PlayerO: "bob", Winner: "X", Variant: "classic"}
+		store.RecordGame(g)
+		earned := store.AwardAchievements(g)
+		if len(earned) != 1 || earned[0].ID != "first-win" {
+			t.Errorf("got %v, want first-win", earned)
+		}
+	})
+	t.Run("a game played with hints earns nothing", func(t *testing.T) {
+		store := newTestStore(t)
+		g := GameResult{Room: "r", PlayerX: "ann", PlayerO: computerHard.Name(), Winner: "X", Variant: "classic", Hinted: true}
+		store.RecordGame(g)
+		if earned := store.AwardAchievements(g); len(earned) != 0 {
+			t.Errorf("got %v, want nothing", earned)
+		}
+	})
+}
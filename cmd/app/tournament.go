@@ -0,0 +1,780 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+	"github.com/jwc20/ssh-ttt/tournament"
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Tournaments
+// ─────────────────────────────────────────────────────────────────────────────
+
+const (
+	maxTournamentNameLen = 32
+	maxTournamentPlayers = 32
+	// maxFinishedTournaments is how many finished events are kept for the
+	// lobby and the API before the oldest are dropped.
+	maxFinishedTournaments = 20
+	// matchStartDelay gives players who just finished a game a moment to see
+	// the final board before they are moved to their next match.
+	matchStartDelay = 5 * time.Second
+)
+
+var (
+	errNoSuchTournament = errors.New("no such tournament")
+	errNotOrganizer     = errors.New("only the organizer can do that")
+	errTournamentFull   = fmt.Errorf("the tournament is full, %d players at most", maxTournamentPlayers)
+	errOpenTournament   = errors.New("you already organize a tournament that hasn't finished")
+)
+
+type TournamentUpdateMsg struct{}
+
+// TournamentInfo is the lobby's one-line summary of a tournament.
+type TournamentInfo struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Organizer string            `json:"organizer"`
+	Format    tournament.Format `json:"format"`
+	Status    tournament.Status `json:"status"`
+	Players   int               `json:"players"`
+	Champion  string            `json:"champion,omitempty"`
+}
+
+// TournamentManager runs the server's tournaments. Ready matches get a room
+// with both seats reserved, and results reported by those rooms advance the
+// bracket. Tournaments live in memory and do not survive a restart.
+type TournamentManager struct {
+	mu          sync.Mutex
+	tournaments map[string]*tournament.Tournament
+	seq         int
+	shared      *SharedState
+}
+
+func NewTournamentManager(shared *SharedState) *TournamentManager {
+	return &TournamentManager{tournaments: make(map[string]*tournament.Tournament), shared: shared}
+}
+
+func (tm *TournamentManager) Create(organizer, name string, format tournament.Format) (TournamentInfo, error) {
+	name = strings.TrimSpace(name)
+	if err := validateTournamentName(name); err != nil {
+		return TournamentInfo{}, err
+	}
+
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	for _, t := range tm.tournaments {
+		if t.Organizer == organizer && t.Status != tournament.Finished {
+			return TournamentInfo{}, errOpenTournament
+		}
+	}
+
+	tm.seq++
+	t, err := tournament.New(fmt.Sprintf("t%d", tm.seq), name, organizer, format)
+	if err != nil {
+		return TournamentInfo{}, err
+	}
+	tm.tournaments[t.ID] = t
+	tm.pruneLocked()
+	events.Emit(evTournamentCreated, "tournament", t.ID, "name", name, "organizer", organizer, "format", string(format))
+	tm.broadcast()
+	return tournamentInfo(t), nil
+}
+
+func (tm *TournamentManager) Register(id, user string) error {
+	return tm.update(id, func(t *tournament.Tournament) error {
+		if len(t.Players) >= maxTournamentPlayers && t.Status == tournament.Registering {
+			return errTournamentFull
+		}
+		return t.Register(user)
+	})
+}
+
+func (tm *TournamentManager) Unregister(id, user string) error {
+	return tm.update(id, func(t *tournament.Tournament) error {
+		return t.Unregister(user)
+	})
+}
+
+// Start closes registration, builds the bracket and opens rooms for the
+// first matches. Only the organizer can start a tournament.
+func (tm *TournamentManager) Start(id, user string) error {
+	return tm.update(id, func(t *tournament.Tournament) error {
+		if t.Organizer != user {
+			return errNotOrganizer
+		}
+		if err := t.Start(); err != nil {
+			return err
+		}
+		events.Emit(evTournamentStarted, "tournament", t.ID, "players", len(t.Players), "matches", len(t.Matches))
+		tm.launchLocked(t)
+		tm.finishedLocked(t)
+		return nil
+	})
+}
+
+// Walkover lets the organizer decide a match, e.g. when a player never
+// turns up. Its room, if it has one, is closed.
+func (tm *TournamentManager) Walkover(id, user string, matchID int, winner string) error {
+	return tm.update(id, func(t *tournament.Tournament) error {
+		if t.Organizer != user {
+			return errNotOrganizer
+		}
+		m := t.Match(matchID)
+		if m == nil {
+			return tournament.ErrNoSuchMatch
+		}
+		room := m.Room
+		if err := t.Report(matchID, winner); err != nil {
+			return err
+		}
+		if r := tm.shared.Rooms.Get(room); r != nil {
+			r.Close("the match was decided by the organizer")
+			tm.shared.Rooms.Remove(room)
+		}
+		events.Emit(evTournamentResult, "tournament", t.ID, "match", matchID, "winner", winner, "reason", "walkover")
+		tm.launchLocked(t)
+		tm.finishedLocked(t)
+		return nil
+	})
+}
+
+// update applies fn to a tournament and tells the lobby when it succeeds.
+func (tm *TournamentManager) update(id string, fn func(*tournament.Tournament) error) error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	t, ok := tm.tournaments[id]
+	if !ok {
+		return errNoSuchTournament
+	}
+	if err := fn(t); err != nil {
+		return err
+	}
+	tm.broadcast()
+	return nil
+}
+
+func (tm *TournamentManager) List() []TournamentInfo {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	list := make([]TournamentInfo, 0, len(tm.tournaments))
+	for _, t := range tm.tournaments {
+		list = append(list, tournamentInfo(t))
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if (a.Status == tournament.Finished) != (b.Status == tournament.Finished) {
+			return b.Status == tournament.Finished
+		}
+		return tournamentSeq(a.ID) > tournamentSeq(b.ID)
+	})
+	return list
+}
+
+// Get returns a copy of a tournament that is safe to read without the lock.
+func (tm *TournamentManager) Get(id string) *tournament.Tournament {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	t, ok := tm.tournaments[id]
+	if !ok {
+		return nil
+	}
+	return t.Clone()
+}
+
+// LaunchReady opens rooms for ready matches that don't have one, e.g.
+// because the room limit was reached or an admin removed the room.
+func (tm *TournamentManager) LaunchReady() {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	for _, t := range tm.tournaments {
+		if t.Status == tournament.Running {
+			tm.launchLocked(t)
+		}
+	}
+}
+
+func (tm *TournamentManager) launchLocked(t *tournament.Tournament) {
+	launched := false
+	for _, m := range t.Ready() {
+		if m.Room != "" && tm.shared.Rooms.Get(m.Room) != nil {
+			continue
+		}
+
+		room, err := tm.shared.Rooms.CreateUnique(fmt.Sprintf("%s-m%d", t.ID, m.ID), DefaultRoomSettings())
+		if err != nil {
+			log.Error("tournament room", "tournament", t.ID, "match", m.ID, "error", err)
+			_ = t.Assign(m.ID, "")
+			continue
+		}
+		room.Reserve(m.X(), m.O())
+		tid, matchID := t.ID, m.ID
+		room.OnFinish(func(result GameResult) { tm.recordResult(tid, matchID, result) })
+		_ = t.Assign(m.ID, room.ID)
+
+		tm.callPlayers(t, m.X(), m.O(), room.ID)
+		tm.callPlayers(t, m.O(), m.X(), room.ID)
+		launched = true
+	}
+	if launched {
+		tm.shared.BroadcastLobby()
+	}
+}
+
+// callPlayers tells player their match is ready. Sessions in the lobby are
+// moved straight in, and those in another room of the same tournament after
+// matchStartDelay.
+func (tm *TournamentManager) callPlayers(t *tournament.Tournament, player, opponent, roomID string) {
+	notice := AnnouncementMsg{
+		From: t.Name,
+		Text: fmt.Sprintf("your match against %s is ready in room %s", opponent, roomID),
+		At:   time.Now(),
+	}
+	join := JoinRoomMsg{RoomID: roomID}
+
+	s := tm.shared
+	s.sessMu.RLock()
+	defer s.sessMu.RUnlock()
+	for _, sess := range s.sessions {
+		if sess.UserID != player {
+			continue
+		}
+		p := sess.Program
+		go p.Send(notice)
+		switch {
+		case sess.Room == "":
+			go p.Send(join)
+		case strings.HasPrefix(sess.Room, t.ID+"-m"):
+			time.AfterFunc(matchStartDelay, func() { p.Send(join) })
+		}
+	}
+}
+
+// recordResult reports the game played in a match's room.
+func (tm *TournamentManager) recordResult(id string, matchID int, result GameResult) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	t, ok := tm.tournaments[id]
+	if !ok {
+		return
+	}
+	m := t.Match(matchID)
+	if m == nil || m.Room != result.Room {
+		return
+	}
+
+	winner := ""
+	switch result.Winner {
+	case "X":
+		winner = m.X()
+	case "O":
+		winner = m.O()
+	}
+	if err := t.Report(matchID, winner); err != nil {
+		log.Error("tournament result", "tournament", id, "match", matchID, "error", err)
+		return
+	}
+	events.Emit(evTournamentResult, "tournament", id, "match", matchID, "room", result.Room,
+		"winner", winner, "replay", !m.Done)
+
+	tm.launchLocked(t)
+	tm.finishedLocked(t)
+	tm.broadcast()
+}
+
+// finishedLocked congratulates the players once t has a champion.
+func (tm *TournamentManager) finishedLocked(t *tournament.Tournament) {
+	if t.Status != tournament.Finished {
+		return
+	}
+	events.Emit(evTournamentFinished, "tournament", t.ID, "champion", t.Champion)
+
+	msg := AnnouncementMsg{From: t.Name, Text: t.Champion + " won the tournament! 🏆", At: time.Now()}
+	s := tm.shared
+	s.sessMu.RLock()
+	defer s.sessMu.RUnlock()
+	for _, sess := range s.sessions {
+		for _, player := range t.Players {
+			if sess.UserID == player {
+				p := sess.Program
+				go p.Send(msg)
+				break
+			}
+		}
+	}
+}
+
+// pruneLocked drops the oldest finished tournaments beyond the limit.
+func (tm *TournamentManager) pruneLocked() {
+	var finished []string
+	for id, t := range tm.tournaments {
+		if t.Status == tournament.Finished {
+			finished = append(finished, id)
+		}
+	}
+	if len(finished) <= maxFinishedTournaments {
+		return
+	}
+	sort.Slice(finished, func(i, j int) bool { return tournamentSeq(finished[i]) < tournamentSeq(finished[j]) })
+	for _, id := range finished[:len(finished)-maxFinishedTournaments] {
+		delete(tm.tournaments, id)
+	}
+}
+
+func (tm *TournamentManager) broadcast() {
+	s := tm.shared
+	s.sessMu.RLock()
+	defer s.sessMu.RUnlock()
+	for _, sess := range s.sessions {
+		p := sess.Program
+		go p.Send(TournamentUpdateMsg{})
+	}
+}
+
+func tournamentInfo(t *tournament.Tournament) TournamentInfo {
+	return TournamentInfo{
+		ID:        t.ID,
+		Name:      t.Name,
+		Organizer: t.Organizer,
+		Format:    t.Format,
+		Status:    t.Status,
+		Players:   len(t.Players),
+		Champion:  t.Champion,
+	}
+}
+
+// tournamentSeq orders tournament IDs ("t1", "t2", ...) by creation.
+func tournamentSeq(id string) int {
+	var n int
+	fmt.Sscanf(id, "t%d", &n)
+	return n
+}
+
+func validateTournamentName(name string) error {
+	switch n := len([]rune(name)); {
+	case n == 0:
+		return errors.New("tournament name can't be empty")
+	case n > maxTournamentNameLen:
+		return fmt.Errorf("tournament name can be at most %d characters", maxTournamentNameLen)
+	}
+	for _, r := range name {
+		if !unicode.IsPrint(r) {
+			return fmt.Errorf("tournament name can't contain %q", r)
+		}
+	}
+	return nil
+}
+
+// ── HTTP API ─────────────────────────────────────────────────────────────────
+
+type tournamentDetail struct {
+	*tournament.Tournament
+	Standings []tournament.Standing `json:"standings"`
+}
+
+// routes serves the tournament list and each tournament's bracket and
+// standings as JSON.
+func (tm *TournamentManager) routes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/tournaments", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, tm.List())
+	})
+	mux.HandleFunc("GET /api/tournaments/{id}", func(w http.ResponseWriter, r *http.Request) {
+		t := tm.Get(r.PathValue("id"))
+		if t == nil {
+			http.Error(w, errNoSuchTournament.Error(), http.StatusNotFound)
+			return
+		}
+		writeJSON(w, tournamentDetail{Tournament: t, Standings: t.Standings()})
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error("write json", "error", err)
+	}
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Tournaments Tab (lobby)
+// ─────────────────────────────────────────────────────────────────────────────
+
+type tournamentsMode int
+
+const (
+	tournamentsBrowse tournamentsMode = iota
+	tournamentsCreate
+	tournamentsDetail
+)
+
+type tournamentsModel struct {
+	shared *SharedState
+	userID string
+	list   []TournamentInfo
+	cursor int
+	mode   tournamentsMode
+	// detail is the tournament being viewed and match the selected row in
+	// its match list.
+	detail *tournament.Tournament
+	match  int
+	name   textinput.Model
+	format int
+	notice string
+}
+
+func newTournamentsModel(shared *SharedState, userID string) tournamentsModel {
+	ti := textinput.New()
+	ti.Placeholder = "Tournament name..."
+	ti.CharLimit = maxTournamentNameLen
+	ti.Width = maxTournamentNameLen
+	return tournamentsModel{shared: shared, userID: userID, name: ti}
+}
+
+// Load refreshes the list and the tournament being viewed.
+func (m *tournamentsModel) Load() {
+	m.list = m.shared.Tournaments.List()
+	m.cursor = min(m.cursor, max(len(m.list)-1, 0))
+	if m.detail != nil {
+		if t := m.shared.Tournaments.Get(m.detail.ID); t != nil {
+			m.detail = t
+			m.match = min(m.match, max(len(t.Matches)-1, 0))
+		} else {
+			m.detail, m.mode = nil, tournamentsBrowse
+		}
+	}
+}
+
+// selected is the ID of the tournament under the cursor or being viewed.
+func (m tournamentsModel) selected() string {
+	if m.detail != nil {
+		return m.detail.ID
+	}
+	if len(m.list) == 0 {
+		return ""
+	}
+	return m.list[m.cursor].ID
+}
+
+func (m tournamentsModel) Update(msg tea.Msg) (tournamentsModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case TournamentUpdateMsg:
+		m.Load()
+		return m, nil
+
+	case tea.KeyMsg:
+		switch m.mode {
+		case tournamentsCreate:
+			return m.handleCreateInput(msg)
+		case tournamentsDetail:
+			return m.handleDetailInput(msg)
+		}
+		return m.handleBrowseInput(msg)
+	}
+	return m, nil
+}
+
+func (m tournamentsModel) handleBrowseInput(msg tea.KeyMsg) (tournamentsModel, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		m.cursor = max(m.cursor-1, 0)
+	case "down", "j":
+		m.cursor = min(m.cursor+1, max(len(m.list)-1, 0))
+	case "enter":
+		if id := m.selected(); id != "" {
+			m.detail = m.shared.Tournaments.Get(id)
+			m.match = 0
+			m.mode = tournamentsDetail
+			m.notice = ""
+		}
+	case "n":
+		m.mode = tournamentsCreate
+		m.notice = ""
+		m.name.Reset()
+		m.name.Focus()
+		return m, textinput.Blink
+	default:
+		m.handleCommon(msg)
+	}
+	return m, nil
+}
+
+func (m tournamentsModel) handleDetailInput(msg tea.KeyMsg) (tournamentsModel, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.detail, m.mode = nil, tournamentsBrowse
+		m.notice = ""
+	case "up", "k":
+		m.match = max(m.match-1, 0)
+	case "down", "j":
+		m.match = min(m.match+1, max(len(m.detail.Matches)-1, 0))
+	case "enter":
+		if m.match < len(m.detail.Matches) {
+			if room := m.detail.Matches[m.match].Room; room != "" {
+				return m, func() tea.Msg { return JoinRoomMsg{RoomID: room} }
+			}
+		}
+	case "x", "o":
+		if m.match >= len(m.detail.Matches) {
+			break
+		}
+		match := m.detail.Matches[m.match]
+		winner := match.X()
+		if msg.String() == "o" {
+			winner = match.O()
+		}
+		m.setNotice(m.shared.Tournaments.Walkover(m.detail.ID, m.userID, match.ID, winner),
+			fmt.Sprintf("Match %d awarded to %s.", match.ID, winner))
+	default:
+		m.handleCommon(msg)
+	}
+	return m, nil
+}
+
+// handleCommon runs the register, unregister and start keys shared by the
+// list and the detail view.
+func (m *tournamentsModel) handleCommon(msg tea.KeyMsg) {
+	id := m.selected()
+	if id == "" {
+		return
+	}
+	switch msg.String() {
+	case "r":
+		m.setNotice(m.shared.Tournaments.Register(id, m.userID), "You're registered, good luck!")
+	case "u":
+		m.setNotice(m.shared.Tournaments.Unregister(id, m.userID), "You've left the tournament.")
+	case "s":
+		m.setNotice(m.shared.Tournaments.Start(id, m.userID), "Tournament started.")
+	}
+}
+
+func (m *tournamentsModel) setNotice(err error, ok string) {
+	m.notice = ok
+	if err != nil {
+		m.notice = err.Error()
+	}
+}
+
+func (m tournamentsModel) handleCreateInput(msg tea.KeyMsg) (tournamentsModel, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = tournamentsBrowse
+		m.name.Blur()
+		return m, nil
+
+	case "left", "right":
+		delta := 1
+		if msg.String() == "left" {
+			delta = -1
+		}
+		n := len(tournament.Formats)
+		m.format = ((m.format+delta)%n + n) % n
+		return m, nil
+
+	case "enter":
+		info, err := m.shared.Tournaments.Create(m.userID, m.name.Value(), tournament.Formats[m.format])
+		if err != nil {
+			m.notice = err.Error()
+			return m, nil
+		}
+		m.name.Blur()
+		m.Load()
+		m.detail = m.shared.Tournaments.Get(info.ID)
+		m.match = 0
+		m.mode = tournamentsDetail
+		m.notice = "Tournament created. Players can register now, press s to start."
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.name, cmd = m.name.Update(msg)
+	return m, cmd
+}
+
+// ── Tournament Styles ────────────────────────────────────────────────────────
+
+var (
+	bracketHeader = lipgloss.NewStyle().Foreground(lipgloss.Color("170")).Bold(true)
+	matchDone     = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	matchLive     = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+)
+
+func (m tournamentsModel) View() string {
+	var b strings.Builder
+
+	switch m.mode {
+	case tournamentsCreate:
+		b.WriteString("  New tournament:\n\n")
+		b.WriteString("  " + m.name.View() + "\n\n")
+		b.WriteString(fmt.Sprintf("  Format: ◂ %s ▸\n\n", tournament.Formats[m.format]))
+	case tournamentsDetail:
+		b.WriteString(m.viewDetail())
+	default:
+		b.WriteString(m.viewList())
+	}
+
+	if m.notice != "" {
+		b.WriteString("\n  " + focusLabel.Render(m.notice) + "\n")
+	}
+	return b.String()
+}
+
+// Help is the key help for the current mode.
+func (m tournamentsModel) Help() string {
+	switch m.mode {
+	case tournamentsCreate:
+		return "  type a name  ←/→: format  enter: create  esc: cancel  "
+	case tournamentsDetail:
+		help := "  ↑/↓: match  enter: watch  "
+		switch {
+		case m.detail.Status == tournament.Registering:
+			help += "r: register  u: leave  "
+			if m.detail.Organizer == m.userID {
+				help += "s: start  "
+			}
+		case m.detail.Status == tournament.Running && m.detail.Organizer == m.userID:
+			help += "x/o: award to X/O  "
+		}
+		return help + "esc: back  "
+	default:
+		return "  ↑/↓: navigate  enter: view  n: new  r: register  u: leave  s: start  "
+	}
+}
+
+func (m tournamentsModel) viewList() string {
+	if len(m.list) == 0 {
+		return "  No tournaments yet. Press 'n' to organize one.\n"
+	}
+
+	var b strings.Builder
+	header := fmt.Sprintf("    %-4s %-24s %-20s %-12s %7s  %s", "ID", "Name", "Format", "Organizer", "Players", "Status")
+	b.WriteString(leaderboardHeader.Render(header) + "\n")
+	for i, t := range m.list {
+		cursor, style := "  ", lobbyItemStyle
+		if i == m.cursor {
+			cursor, style = "▸ ", lobbySelectedItem
+		}
+		status := string(t.Status)
+		if t.Champion != "" {
+			status += ", won by " + t.Champion
+		}
+		line := fmt.Sprintf("%s%-4s %-24s %-20s %-12s %7d  %s",
+			cursor, t.ID, truncate(t.Name, 24), t.Format, truncate(t.Organizer, 12), t.Players, status)
+		b.WriteString(style.Render(line) + "\n")
+	}
+	return b.String()
+}
+
+func (m tournamentsModel) viewDetail() string {
+	t := m.detail
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("  %s · %s · organized by %s · %s\n",
+		bracketHeader.Render(t.Name), t.Format, t.Organizer, t.Status))
+	if t.Champion != "" {
+		b.WriteString(fmt.Sprintf("  🏆 %s\n", t.Champion))
+	}
+	b.WriteString(fmt.Sprintf("  Players (%d): %s\n", len(t.Players), strings.Join(t.Players, ", ")))
+
+	var bracket tournament.Bracket = "-"
+	for i, match := range t.Matches {
+		if match.Bracket != bracket {
+			bracket = match.Bracket
+			b.WriteString("\n  " + bracketHeader.Render(bracketTitle(bracket)) + "\n")
+		}
+		cursor := "  "
+		if i == m.match {
+			cursor = "▸ "
+		}
+		line := fmt.Sprintf("%sR%-2d #%-3d %-12s vs %-12s %s", cursor, match.Round, match.ID,
+			truncate(slotName(match.Slots[0]), 12), truncate(slotName(match.Slots[1]), 12), matchStatus(match))
+		switch {
+		case match.Done:
+			line = matchDone.Render(line)
+		case match.Room != "":
+			line = matchLive.Render(line)
+		}
+		b.WriteString("  " + line + "\n")
+	}
+
+	if t.Status != tournament.Registering {
+		b.WriteString("\n  " + bracketHeader.Render("Standings") + "\n")
+		header := fmt.Sprintf("  %4s  %-16s %4s %4s %4s %4s %4s", "#", "Player", "P", "W", "D", "L", "Pts")
+		b.WriteString(leaderboardHeader.Render(header) + "\n")
+		for i, s := range t.Standings() {
+			line := fmt.Sprintf("  %4d  %-16s %4d %4d %4d %4d %4d",
+				i+1, truncate(s.Player, 16), s.Played, s.Wins, s.Draws, s.Losses, s.Points)
+			if s.Eliminated {
+				line += "  out"
+			}
+			if s.Player == m.userID {
+				line = leaderboardOwnRow.Render(line)
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+	return b.String()
+}
+
+func bracketTitle(b tournament.Bracket) string {
+	switch b {
+	case tournament.Winners:
+		return "Winners bracket"
+	case tournament.Losers:
+		return "Losers bracket"
+	case tournament.GrandFinal:
+		return "Grand final"
+	default:
+		return "Matches"
+	}
+}
+
+func slotName(s tournament.Slot) string {
+	switch {
+	case !s.Filled:
+		return "TBD"
+	case s.Player == "":
+		return "(bye)"
+	default:
+		return s.Player
+	}
+}
+
+func matchStatus(m *tournament.Match) string {
+	switch {
+	case m.Bye:
+		return "bye"
+	case m.Draw:
+		return "draw"
+	case m.Done:
+		return m.Winner + " won"
+	case m.Room != "":
+		status := "playing in " + m.Room
+		if m.Replays > 0 {
+			status += fmt.Sprintf(" (replay %d)", m.Replays)
+		}
+		return status
+	default:
+		return ""
+	}
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -4,14 +4,20 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"flag"
 	"fmt"
+	"math"
 	"net"
 	"os"
 	"os/signal"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
 	"time"
+	"unicode"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
@@ -23,25 +29,63 @@ import (
 	"github.com/charmbracelet/wish/activeterm"
 	"github.com/charmbracelet/wish/bubbletea"
 	"github.com/charmbracelet/wish/logging"
+	"github.com/jwc20/ssh-ttt/config"
+	"github.com/jwc20/ssh-ttt/metrics"
+	"github.com/jwc20/ssh-ttt/variant"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/muesli/termenv"
-)
-
-const (
-	host = "localhost"
-	port = "2222"
+	gossh "golang.org/x/crypto/ssh"
 )
 
 func main() {
-	store, err := NewSQLiteStore("tictactoe.db")
+	cfg, opts, err := config.Load("app", os.Args[1:], os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatal("invalid configuration", "error", err)
+	}
+	if opts.PrintConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Fatal("printing configuration", "error", err)
+		}
+		return
+	}
+
+	store, err := NewSQLiteStore(cfg.Database.Path)
 	if err != nil {
 		log.Fatal("database error", "error", err)
 	}
 	defer store.Close()
 
-	shared := NewSharedState(store)
+	words, err := loadWordList(cfg.Chat.WordFilterPath)
+	if err != nil {
+		log.Fatal("word filter error", "error", err)
+	}
+
+	events, err = NewEventLog(cfg.Events)
+	if err != nil {
+		log.Fatal("event log error", "error", err)
+	}
+	defer events.Close()
+
+	shared := NewSharedState(cfg, store, NewChatModerator(store, words))
+
+	shared.Admins, err = loadAuthorizedKeys(cfg.SSH.AdminKeysPath)
+	if err != nil {
+		log.Fatal("admin keys error", "error", err)
+	}
+
+	if err := shared.Rooms.Restore(); err != nil {
+		log.Error("restoring rooms failed", "error", err)
+	}
 
-	go shared.StartCleanupLoop(30 * time.Second)
+	go shared.StartCleanupLoop(cfg.Rooms.CleanupInterval.D())
+
+	shared.registerMetrics(metrics.Default)
+	httpCtx, stopHTTP := context.WithCancel(context.Background())
+	defer stopHTTP()
+	serveHTTP(httpCtx, cfg, shared)
 
 	handler := func(sess ssh.Session) *tea.Program {
 		userID := sess.User()
@@ -49,6 +93,7 @@ func main() {
 		pty, _, _ := sess.Pty()
 
 		model := NewRootModel(shared, userID, sessID)
+		model.admin = shared.IsAdmin(sess.PublicKey())
 
 		opts := bubbletea.MakeOptions(sess)
 		opts = append(opts, tea.WithAltScreen())
@@ -56,6 +101,7 @@ func main() {
 
 		model.program = p
 		shared.AddToLobby(sessID, userID, p)
+		shared.Connect(sessID, userID, sess.RemoteAddr().String(), p)
 
 		go func() {
 			<-sess.Context().Done()
@@ -72,11 +118,16 @@ func main() {
 	}
 
 	s, err := wish.NewServer(
-		wish.WithAddress(net.JoinHostPort(host, port)),
-		wish.WithHostKeyPath(".ssh/id_ed25519"),
+		wish.WithAddress(net.JoinHostPort(cfg.SSH.Host, strconv.Itoa(cfg.SSH.Port))),
+		wish.WithHostKeyPath(cfg.SSH.HostKeyPath),
+		wish.WithPublicKeyAuth(func(ssh.Context, ssh.PublicKey) bool { return true }),
+		wish.WithKeyboardInteractiveAuth(func(ssh.Context, gossh.KeyboardInteractiveChallenge) bool { return true }),
 		wish.WithMiddleware(
 			bubbletea.MiddlewareWithProgramHandler(handler, termenv.ANSI256),
 			activeterm.Middleware(),
+			banMiddleware(store),
+			reservedNameMiddleware(),
+			connLimitMiddleware(shared),
 			logging.Middleware(),
 		),
 	)
@@ -87,7 +138,7 @@ func main() {
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 
-	log.Info("Starting SSH server", "host", host, "port", port)
+	log.Info("Starting SSH server", "host", cfg.SSH.Host, "port", cfg.SSH.Port)
 	go func() {
 		if err := s.ListenAndServe(); err != nil {
 			log.Error("server error", "error", err)
@@ -97,7 +148,8 @@ func main() {
 
 	<-done
 	log.Info("Stopping SSH server")
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	shared.Rooms.SnapshotAll()
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.SSH.ShutdownTimeout.D())
 	defer cancel()
 	if err := s.Shutdown(ctx); err != nil {
 		log.Error("shutdown error", "error", err)
@@ -128,17 +180,266 @@ func NewSQLiteStore(path string) (*SQLiteStore, error) {
 		return nil, err
 	}
 
+	if err := addColumn(db, "players", "rating", "INTEGER DEFAULT 1200"); err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS admin_audit (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			admin TEXT NOT NULL,
+			action TEXT NOT NULL,
+			target TEXT NOT NULL,
+			detail TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS room_snapshots (
+			id TEXT PRIMARY KEY,
+			data BLOB NOT NULL,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS sanctions (
+			name TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			issued_by TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (name, kind)
+		)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS ignores (
+			name TEXT NOT NULL,
+			ignored TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (name, ignored)
+		)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS user_keys (
+			name TEXT NOT NULL,
+			fingerprint TEXT NOT NULL,
+			last_seen DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (name, fingerprint)
+		)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS key_bans (
+			fingerprint TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			issued_by TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS achievements (
+			player TEXT NOT NULL,
+			achievement TEXT NOT NULL,
+			earned_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (player, achievement)
+		)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS games (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			room TEXT NOT NULL,
+			player_x TEXT NOT NULL,
+			player_o TEXT NOT NULL,
+			winner TEXT NOT NULL,
+			finished_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, col := range []struct{ name, decl string }{
+		{"variant", "TEXT NOT NULL DEFAULT 'classic'"},
+		{"board", "TEXT NOT NULL DEFAULT ''"},
+		{"moves", "INTEGER NOT NULL DEFAULT 0"},
+		{"sequence", "TEXT NOT NULL DEFAULT ''"},
+		{"reason", "TEXT NOT NULL DEFAULT ''"},
+		{"options", "TEXT NOT NULL DEFAULT ''"},
+	} {
+		if err := addColumn(db, "games", col.name, col.decl); err != nil {
+			return nil, err
+		}
+	}
+
 	return &SQLiteStore{db: db}, nil
 }
 
+// addColumn adds a column to an existing table unless it is already there,
+// so databases created by older builds pick up new fields on startup.
+func addColumn(db *sql.DB, table, column, decl string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid, notNull, pk int
+			name, typ        string
+			dflt             sql.NullString
+		)
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &dflt, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, decl))
+	return err
+}
+
 func (s *SQLiteStore) RecordWin(name string) {
+	defer storeTimer("record_win")()
+
 	_, _ = s.db.Exec(`
 		INSERT INTO players (name, wins) VALUES (?, 1)
 		ON CONFLICT(name) DO UPDATE SET wins = wins + 1
 	`, name)
 }
 
+// GameResult describes a finished game. Winner is "X", "O" or empty for a
+// draw; Board is the final position, one character per cell, and Sequence
+// the moves in the order they were played, separated by spaces. Reason is
+// how the game ended: "line", "timeout" or "draw". Options is the board the
+// variant was played on, as variant.Options prints it, for variants that
+// take one.
+type GameResult struct {
+	Room     string
+	PlayerX  string
+	PlayerO  string
+	Winner   string
+	Variant  string
+	Options  string
+	Board    string
+	Moves    int
+	Sequence string
+	Reason   string
+	// Hinted is set when a player was shown a hint during the game.
+	Hinted bool
+}
+
+// Rated reports whether the game counts towards wins and ratings, which
+// only games between two people do.
+func (g GameResult) Rated() bool {
+	return !isComputer(g.PlayerX) && !isComputer(g.PlayerO)
+}
+
+// RecordGame stores a finished game, bumps the winner's win counter and
+// updates both players' Elo ratings. Games against the computer are kept
+// for history and stats but are unrated: they touch neither side's wins
+// nor rating.
+func (s *SQLiteStore) RecordGame(g GameResult) {
+	defer storeTimer("record_game")()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		log.Error("record game", "error", err)
+		return
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		"INSERT INTO games (room, player_x, player_o, winner, variant, options, board, moves, sequence, reason) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		g.Room, g.PlayerX, g.PlayerO, g.Winner, g.Variant, g.Options, g.Board, g.Moves, g.Sequence, g.Reason,
+	)
+	if err != nil {
+		log.Error("record game", "error", err)
+		return
+	}
+
+	for _, name := range []string{g.PlayerX, g.PlayerO} {
+		if name == "" || isComputer(name) {
+			continue
+		}
+		_, _ = tx.Exec("INSERT INTO players (name) VALUES (?) ON CONFLICT(name) DO NOTHING", name)
+	}
+
+	switch {
+	case !g.Rated():
+	case g.Winner == "X":
+		_, _ = tx.Exec("UPDATE players SET wins = wins + 1 WHERE name = ?", g.PlayerX)
+	case g.Winner == "O":
+		_, _ = tx.Exec("UPDATE players SET wins = wins + 1 WHERE name = ?", g.PlayerO)
+	}
+
+	if g.Rated() && g.PlayerX != "" && g.PlayerO != "" && g.PlayerX != g.PlayerO {
+		var rx, ro int
+		_ = tx.QueryRow("SELECT rating FROM players WHERE name = ?", g.PlayerX).Scan(&rx)
+		_ = tx.QueryRow("SELECT rating FROM players WHERE name = ?", g.PlayerO).Scan(&ro)
+
+		score := 0.5
+		switch g.Winner {
+		case "X":
+			score = 1
+		case "O":
+			score = 0
+		}
+		rx, ro = eloUpdate(rx, ro, score)
+
+		_, _ = tx.Exec("UPDATE players SET rating = ? WHERE name = ?", rx, g.PlayerX)
+		_, _ = tx.Exec("UPDATE players SET rating = ? WHERE name = ?", ro, g.PlayerO)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error("record game", "error", err)
+	}
+}
+
+const eloK = 32
+
+// eloUpdate returns the new ratings for a and b after a game where a scored
+// score (1 win, 0.5 draw, 0 loss).
+func eloUpdate(a, b int, score float64) (int, int) {
+	expected := 1 / (1 + math.Pow(10, float64(b-a)/400))
+	delta := int(math.Round(eloK * (score - expected)))
+	return a + delta, b - delta
+}
+
 func (s *SQLiteStore) GetPlayerScore(name string) int {
+	defer storeTimer("get_player_score")()
+
 	var wins int
 	_ = s.db.QueryRow("SELECT wins FROM players WHERE name = ?", name).Scan(&wins)
 	return wins
@@ -150,73 +451,57 @@ func (s *SQLiteStore) Close() error { return s.db.Close() }
 // Game Logic
 // ─────────────────────────────────────────────────────────────────────────────
 
+// GameState is a room's game: the variant's board plus what the room
+// tracks on top of the rules.
 type GameState struct {
-	Cells       [9]rune
-	CurrentTurn rune
-	MoveCount   int
+	Board     variant.State
+	MoveCount int
+	// Sequence holds the moves in the order they were played.
+	Sequence []int
+	forfeit  rune
 }
 
-func NewGameState() *GameState {
-	var cells [9]rune
-	for i := range cells {
-		cells[i] = ' '
-	}
-	return &GameState{Cells: cells, CurrentTurn: 'X'}
+func NewGameState(settings RoomSettings) *GameState {
+	return &GameState{Board: newBoard(settings)}
 }
 
+func (g *GameState) Cells() []rune     { return g.Board.Cells() }
+func (g *GameState) CurrentTurn() rune { return g.Board.Turn() }
+
 func (g *GameState) MakeMove(position int) error {
-	if position < 0 || position > 8 {
-		return errors.New("position out of range")
-	}
-	if g.Cells[position] != ' ' {
-		return errors.New("square already taken")
+	if g.forfeit != 0 {
+		return variant.ErrGameOver
 	}
-	if g.IsOver() {
-		return errors.New("game is over")
+	if err := g.Board.Play(position); err != nil {
+		return err
 	}
-
-	g.Cells[position] = g.CurrentTurn
 	g.MoveCount++
-
-	if g.CurrentTurn == 'X' {
-		g.CurrentTurn = 'O'
-	} else {
-		g.CurrentTurn = 'X'
-	}
-
+	g.Sequence = append(g.Sequence, position)
 	return nil
 }
 
+// Forfeit ends the game as a loss for the given side, e.g. on time.
+func (g *GameState) Forfeit(loser rune) {
+	g.forfeit = variant.Other(loser)
+}
+
 func (g *GameState) Winner() rune {
-	lines := [8][3]int{
-		{0, 1, 2},
-		{3, 4, 5},
-		{6, 7, 8},
-		{0, 3, 6},
-		{1, 4, 7},
-		{2, 5, 8},
-		{0, 4, 8},
-		{2, 4, 6},
-	}
-	for _, line := range lines {
-		a, b, c := g.Cells[line[0]], g.Cells[line[1]], g.Cells[line[2]]
-		if a != ' ' && a == b && b == c {
-			return a
-		}
+	if g.forfeit != 0 {
+		return g.forfeit
 	}
-	return ' '
+	return g.Board.Winner()
 }
 
 func (g *GameState) IsDraw() bool {
-	return g.MoveCount == 9 && g.Winner() == ' '
+	return g.IsOver() && g.Winner() == ' '
 }
 
 func (g *GameState) IsOver() bool {
-	return g.Winner() != ' ' || g.MoveCount == 9
+	return g.forfeit != 0 || g.Board.Over()
 }
 
 func (g *GameState) CurrentPlayerString() string {
-	return string(g.CurrentTurn)
+	return string(g.CurrentTurn())
 }
 
 // ─────────────────────────────────────────────────────────────────────────────
@@ -258,27 +543,43 @@ func (r PlayerRole) Mark() rune {
 // ─────────────────────────────────────────────────────────────────────────────
 
 type RoomInfo struct {
-	ID      string
-	Players int
-	Status  string
+	ID         string
+	Players    int
+	Spectators int
+	Status     string
+	CreatedAt  time.Time
 }
 
 type (
 	RoomListUpdateMsg struct{ Rooms []RoomInfo }
 	JoinRoomMsg       struct{ RoomID string }
 	LeaveRoomMsg      struct{}
-	RoomChatMsg       struct{ Sender, Text string }
-	PlayerJoinedMsg   struct {
+	RoomChatMsg       struct {
+		Sender, To, Text string
+		Kind             chatKind
+	}
+	ChatHistoryMsg  struct{ Messages []RoomChatMsg }
+	PlayerJoinedMsg struct {
 		Name string
 		Role PlayerRole
 	}
 	PlayerLeftMsg   struct{ Name string }
 	RoleAssignedMsg struct{ Role PlayerRole }
-	GameUpdateMsg   struct {
-		Cells       [9]rune
+	RosterMsg       struct {
+		PlayerX, PlayerO string
+		Spectators       []string
+	}
+	GameUpdateMsg struct {
+		// Board is a copy of the room's board for the session to render.
+		Board variant.State
+		// Sequence is the moves played so far, in order.
+		Sequence    []int
 		CurrentTurn string
 		IsOver      bool
 		Winner      string
+		Timed       bool
+		Clocks      [2]time.Duration
+		At          time.Time
 	}
 )
 
@@ -290,6 +591,7 @@ type Client struct {
 	Program *tea.Program
 	Role    PlayerRole
 	UserID  string
+	Admin   bool
 }
 
 // ─────────────────────────────────────────────────────────────────────────────
@@ -297,47 +599,73 @@ type Client struct {
 // ─────────────────────────────────────────────────────────────────────────────
 
 type Room struct {
-	mu      sync.RWMutex
-	ID      string
-	clients map[string]*Client
-	game    *GameState
-	started bool
-	store   *SQLiteStore
-}
-
-func NewRoom(id string, store *SQLiteStore) *Room {
+	mu        sync.RWMutex
+	ID        string
+	CreatedAt time.Time
+	clients   map[string]*Client
+	game      *GameState
+	started   bool
+	settings  RoomSettings
+	clock     gameClock
+	reserved  [2]string
+	keepUntil time.Time
+	// Owner is the user who created the room; rooms opened by the server
+	// for challenges and restores have none.
+	Owner      string
+	finishedAt time.Time
+	onFinish   func(GameResult)
+	// computerPending is set while the computer player's reply is queued.
+	computerPending bool
+	store           *SQLiteStore
+	moderator       *ChatModerator
+	history         []RoomChatMsg
+	// hinted is set once a player has asked for a hint in the current game.
+	hinted bool
+}
+
+func NewRoom(id string, settings RoomSettings, store *SQLiteStore, moderator *ChatModerator) *Room {
 	return &Room{
-		ID:      id,
-		clients: make(map[string]*Client),
-		game:    NewGameState(),
-		store:   store,
+		ID:        id,
+		CreatedAt: time.Now(),
+		settings:  settings,
+		clients:   make(map[string]*Client),
+		game:      NewGameState(settings),
+		store:     store,
+		moderator: moderator,
 	}
 }
 
-func (r *Room) Join(sessID, userID string, p *tea.Program) PlayerRole {
+func (r *Room) Join(sessID, userID string, p *tea.Program, admin bool) PlayerRole {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	role := r.assignRole()
+	role := r.assignRole(userID)
 
-	r.clients[sessID] = &Client{Program: p, Role: role, UserID: userID}
+	r.clients[sessID] = &Client{Program: p, Role: role, UserID: userID, Admin: admin}
+	events.Emit(evJoined, "room", r.ID, "user", userID, "session", sessID, "role", role.String())
+
+	if history := withoutIgnored(r.history, r.store.Ignoring(userID)); len(history) > 0 {
+		go p.Send(ChatHistoryMsg{Messages: history})
+	}
 
-	if role == RolePlayerO && !r.started {
-		r.started = true
+	if role != RoleSpectator && r.seatTakenLocked(RolePlayerX) && r.seatTakenLocked(RolePlayerO) {
+		r.startLocked()
 	}
 
 	r.broadcastLocked(PlayerJoinedMsg{Name: userID, Role: role})
+	r.broadcastLocked(r.rosterLocked())
 	go p.Send(RoleAssignedMsg{Role: role})
 
 	if r.started {
 		go p.Send(r.gameSnapshot())
 	}
+	r.scheduleComputerLocked()
 
 	return role
 }
 
-func (r *Room) assignRole() PlayerRole {
-	hasX, hasO := false, false
+func (r *Room) assignRole(userID string) PlayerRole {
+	hasX, hasO := false, r.settings.Computer != ""
 	for _, c := range r.clients {
 		if c.Role == RolePlayerX {
 			hasX = true
@@ -347,16 +675,42 @@ func (r *Room) assignRole() PlayerRole {
 		}
 	}
 
+	canSit := func(seat string) bool { return seat == "" || seat == userID }
+
 	switch {
-	case !hasX:
+	case !hasX && r.reserved[0] == userID:
+		return RolePlayerX
+	case !hasO && r.reserved[1] == userID:
+		return RolePlayerO
+	case !hasX && canSit(r.reserved[0]):
 		return RolePlayerX
-	case !hasO:
+	case !hasO && canSit(r.reserved[1]):
 		return RolePlayerO
 	default:
 		return RoleSpectator
 	}
 }
 
+// Reserve holds the X and O seats for the given users, e.g. after a
+// challenge is accepted.
+func (r *Room) Reserve(x, o string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reserved = [2]string{x, o}
+}
+
+// OnFinish registers fn to be called with the result of every game
+// finished in the room. It runs on its own goroutine, outside the room lock.
+func (r *Room) OnFinish(fn func(GameResult)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onFinish = fn
+}
+
+func (r *Room) Settings() RoomSettings {
+	return r.settings
+}
+
 func (r *Room) Leave(sessID string) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -367,7 +721,82 @@ func (r *Room) Leave(sessID string) {
 	}
 
 	delete(r.clients, sessID)
+	events.Emit(evLeft, "room", r.ID, "user", client.UserID, "session", sessID, "role", client.Role.String())
+
+	// A game nobody has moved in yet is not under way, so reopen the seat
+	// for spectators instead of holding it for a player who walked away.
+	if client.Role != RoleSpectator && r.started && r.game.MoveCount == 0 {
+		if r.clock.timer != nil {
+			r.clock.timer.Stop()
+		}
+		r.started = false
+	}
 	r.broadcastLocked(PlayerLeftMsg{Name: client.UserID})
+	r.broadcastLocked(r.rosterLocked())
+}
+
+// ClaimSeat moves a spectator into a free X or O seat. Seats can only be
+// claimed before the game starts, which includes a player leaving before
+// the first move; taking the last free seat starts it.
+func (r *Room) ClaimSeat(sessID string) (PlayerRole, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	client, ok := r.clients[sessID]
+	if !ok || client.Role != RoleSpectator || r.started {
+		return RoleSpectator, false
+	}
+
+	role := r.assignRole(client.UserID)
+	if role == RoleSpectator {
+		return RoleSpectator, false
+	}
+	client.Role = role
+
+	if r.seatTakenLocked(RolePlayerX) && r.seatTakenLocked(RolePlayerO) {
+		r.startLocked()
+	}
+
+	r.broadcastLocked(PlayerJoinedMsg{Name: client.UserID, Role: role})
+	r.broadcastLocked(r.rosterLocked())
+	go client.Program.Send(RoleAssignedMsg{Role: role})
+
+	if r.started {
+		r.broadcastLocked(r.gameSnapshot())
+	}
+
+	return role, true
+}
+
+func (r *Room) seatTakenLocked(role PlayerRole) bool {
+	if role == RolePlayerO && r.settings.Computer != "" {
+		return true
+	}
+	for _, c := range r.clients {
+		if c.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Room) rosterLocked() RosterMsg {
+	var msg RosterMsg
+	if r.settings.Computer != "" {
+		msg.PlayerO = r.settings.Computer.Name()
+	}
+	for _, c := range r.clients {
+		switch c.Role {
+		case RolePlayerX:
+			msg.PlayerX = c.UserID
+		case RolePlayerO:
+			msg.PlayerO = c.UserID
+		default:
+			msg.Spectators = append(msg.Spectators, c.UserID)
+		}
+	}
+	sort.Strings(msg.Spectators)
+	return msg
 }
 
 func (r *Room) HandleMove(sessID string, position int) bool {
@@ -379,33 +808,87 @@ func (r *Room) HandleMove(sessID string, position int) bool {
 		return false
 	}
 
-	if string(r.game.CurrentTurn) != client.Role.String() {
+	if r.game.CurrentPlayerString() != client.Role.String() {
+		return false
+	}
+
+	if r.flaggedLocked() {
 		return false
 	}
 
+	if !r.playLocked(client.UserID, position) {
+		return false
+	}
+	r.scheduleComputerLocked()
+	return true
+}
+
+// playLocked makes a move for the side to move and tells everyone.
+func (r *Room) playLocked(userID string, position int) bool {
+	mover := r.game.CurrentTurn()
 	if err := r.game.MakeMove(position); err != nil {
 		return false
 	}
+	movesTotal.Inc()
+	events.Emit(evMove, "room", r.ID, "user", userID, "mark", string(mover),
+		"cell", position, "move", r.game.MoveCount)
+	r.tickClockLocked(mover)
 
 	if r.game.IsOver() {
 		r.recordResult()
 	}
+	r.persistLocked()
 
 	r.broadcastLocked(r.gameSnapshot())
 	return true
 }
 
 func (r *Room) recordResult() {
-	winner := r.game.Winner()
-	if winner == ' ' {
-		return
+	r.finishedAt = time.Now()
+	result := GameResult{
+		Room:     r.ID,
+		Variant:  r.settings.Variant,
+		Board:    string(r.game.Cells()),
+		Moves:    r.game.MoveCount,
+		Sequence: encodeSequence(r.game.Sequence),
+		Reason:   "line",
+		Hinted:   r.hinted,
+	}
+	if r.settings.Board != (variant.Options{}) {
+		result.Options = r.settings.Board.String()
+	}
+	if w := r.game.Winner(); w != ' ' {
+		result.Winner = string(w)
+	}
+	switch {
+	case r.game.forfeit != 0:
+		result.Reason = "timeout"
+	case result.Winner == "":
+		result.Reason = "draw"
 	}
 	for _, c := range r.clients {
-		if c.Role.Mark() == winner {
-			r.store.RecordWin(c.UserID)
-			break
+		switch c.Role {
+		case RolePlayerX:
+			result.PlayerX = c.UserID
+		case RolePlayerO:
+			result.PlayerO = c.UserID
 		}
 	}
+	if r.settings.Computer != "" {
+		result.PlayerO = r.settings.Computer.Name()
+	}
+	r.store.RecordGame(result)
+	gamesFinished.With(gameResultLabel(result.Winner)).Inc()
+	events.Emit(evFinished, "room", r.ID, "x", result.PlayerX, "o", result.PlayerO,
+		"result", gameResultLabel(result.Winner), "reason", result.Reason, "moves", r.game.MoveCount)
+
+	for _, a := range r.store.AwardAchievements(result) {
+		r.postLocked(RoomChatMsg{Text: fmt.Sprintf("🏆 %s earned %q: %s", a.Player, a.Title, a.Description), Kind: chatSystem})
+	}
+
+	if r.onFinish != nil {
+		go r.onFinish(result)
+	}
 }
 
 func (r *Room) gameSnapshot() GameUpdateMsg {
@@ -415,19 +898,17 @@ func (r *Room) gameSnapshot() GameUpdateMsg {
 	}
 
 	return GameUpdateMsg{
-		Cells:       r.game.Cells,
+		Board:       r.game.Board.Clone(),
+		Sequence:    slices.Clone(r.game.Sequence),
 		CurrentTurn: r.game.CurrentPlayerString(),
 		IsOver:      r.game.IsOver(),
 		Winner:      winnerStr,
+		Timed:       r.settings.TimeControl.Timed(),
+		Clocks:      r.clocksLocked(),
+		At:          time.Now(),
 	}
 }
 
-func (r *Room) BroadcastChat(sender, text string) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	r.broadcastLocked(RoomChatMsg{Sender: sender, Text: text})
-}
-
 func (r *Room) broadcastLocked(msg tea.Msg) {
 	for _, c := range r.clients {
 		p := c.Program
@@ -435,12 +916,30 @@ func (r *Room) broadcastLocked(msg tea.Msg) {
 	}
 }
 
-func (r *Room) PlayerCount() int {
+// ClientCount returns everyone in the room, players and spectators alike.
+func (r *Room) ClientCount() int {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	return len(r.clients)
 }
 
+// Counts returns the number of seated players and spectators.
+func (r *Room) Counts() (players, spectators int) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, c := range r.clients {
+		if c.Role == RoleSpectator {
+			spectators++
+		} else {
+			players++
+		}
+	}
+	if r.settings.Computer != "" {
+		players++
+	}
+	return players, spectators
+}
+
 func (r *Room) Status() string {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -456,7 +955,14 @@ func (r *Room) Status() string {
 }
 
 func (r *Room) Info() RoomInfo {
-	return RoomInfo{ID: r.ID, Players: r.PlayerCount(), Status: r.Status()}
+	players, spectators := r.Counts()
+	return RoomInfo{
+		ID:         r.ID,
+		Players:    players,
+		Spectators: spectators,
+		Status:     r.Status(),
+		CreatedAt:  r.CreatedAt,
+	}
 }
 
 // ─────────────────────────────────────────────────────────────────────────────
@@ -464,32 +970,135 @@ func (r *Room) Info() RoomInfo {
 // ─────────────────────────────────────────────────────────────────────────────
 
 type RoomManager struct {
-	mu    sync.RWMutex
-	rooms map[string]*Room
-	store *SQLiteStore
+	mu        sync.RWMutex
+	rooms     map[string]*Room
+	store     *SQLiteStore
+	moderator *ChatModerator
+	limits    config.Rooms
 }
 
-func NewRoomManager(store *SQLiteStore) *RoomManager {
-	return &RoomManager{rooms: make(map[string]*Room), store: store}
+func NewRoomManager(store *SQLiteStore, moderator *ChatModerator, limits config.Rooms) *RoomManager {
+	return &RoomManager{rooms: make(map[string]*Room), store: store, moderator: moderator, limits: limits}
 }
 
-func (rm *RoomManager) Create(id string) *Room {
+var (
+	errTooManyRooms = errors.New("the server has reached its room limit, try again later")
+	errRoomExists   = errors.New("a room with that name already exists")
+)
+
+// Create opens a new room owned by owner. Names are validated and must not
+// be in use.
+func (rm *RoomManager) Create(id string, settings RoomSettings, owner string) (*Room, error) {
+	if err := validateRoomName(id); err != nil {
+		return nil, err
+	}
+
 	rm.mu.Lock()
 	defer rm.mu.Unlock()
-	room := NewRoom(id, rm.store)
+	if rm.rooms[id] != nil {
+		return nil, errRoomExists
+	}
+	return rm.createLocked(id, settings, owner)
+}
+
+func (rm *RoomManager) createLocked(id string, settings RoomSettings, owner string) (*Room, error) {
+	if len(rm.rooms) >= rm.limits.MaxRooms {
+		return nil, errTooManyRooms
+	}
+	if owner != "" && rm.limits.MaxPerUser > 0 && rm.ownedLocked(owner) >= rm.limits.MaxPerUser {
+		return nil, fmt.Errorf("you already have %d open rooms, finish or leave one first", rm.limits.MaxPerUser)
+	}
+	room := NewRoom(id, settings, rm.store, rm.moderator)
+	room.Owner = owner
 	rm.rooms[id] = room
-	return room
+	events.Emit(evRoomCreated, "room", id, "variant", settings.Variant, "clock", settings.TimeControl.String())
+	return room, nil
+}
+
+// CreateUnique creates a room named base, or base-2, base-3... if that name
+// is taken.
+func (rm *RoomManager) CreateUnique(base string, settings RoomSettings) (*Room, error) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	id := base
+	for n := 2; rm.rooms[id] != nil; n++ {
+		id = fmt.Sprintf("%s-%d", base, n)
+	}
+	return rm.createLocked(id, settings, "")
 }
 
-func (rm *RoomManager) GetOrCreate(id string) *Room {
+func (rm *RoomManager) GetOrCreate(id, owner string) (*Room, error) {
 	rm.mu.Lock()
 	defer rm.mu.Unlock()
 	if r, ok := rm.rooms[id]; ok {
-		return r
+		return r, nil
+	}
+	if err := validateRoomName(id); err != nil {
+		return nil, err
+	}
+	return rm.createLocked(id, DefaultRoomSettings(), owner)
+}
+
+func (rm *RoomManager) ownedLocked(owner string) int {
+	n := 0
+	for _, r := range rm.rooms {
+		if r.Owner == owner {
+			n++
+		}
+	}
+	return n
+}
+
+const maxRoomNameLen = 24
+
+// validateRoomName accepts 1 to maxRoomNameLen letters, digits, spaces and
+// - _ . characters, starting with a letter or digit.
+func validateRoomName(name string) error {
+	runes := []rune(name)
+	switch {
+	case len(runes) == 0:
+		return errors.New("room name can't be empty")
+	case len(runes) > maxRoomNameLen:
+		return fmt.Errorf("room name can be at most %d characters", maxRoomNameLen)
+	case !unicode.IsLetter(runes[0]) && !unicode.IsDigit(runes[0]):
+		return errors.New("room name must start with a letter or digit")
+	}
+	for _, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune(" -_.", r) {
+			return fmt.Errorf("room name can't contain %q", r)
+		}
+	}
+	return nil
+}
+
+// ArchiveFinished closes rooms whose game ended more than the archive
+// timeout ago. Results are already in the games table, so nothing is lost.
+func (rm *RoomManager) ArchiveFinished() {
+	after := rm.limits.ArchiveAfter.D()
+	if after <= 0 {
+		return
+	}
+
+	rm.mu.Lock()
+	var archived []*Room
+	for id, r := range rm.rooms {
+		r.mu.RLock()
+		done := !r.finishedAt.IsZero() && time.Since(r.finishedAt) >= after
+		r.mu.RUnlock()
+		if done {
+			delete(rm.rooms, id)
+			archived = append(archived, r)
+		}
+	}
+	rm.mu.Unlock()
+
+	for _, r := range archived {
+		r.Close("the game is over and the room was archived")
+		if err := rm.store.DeleteRoomSnapshot(r.ID); err != nil {
+			log.Error("delete room snapshot", "room", r.ID, "error", err)
+		}
+		events.Emit(evRoomRemoved, "room", r.ID, "reason", "archived")
 	}
-	room := NewRoom(id, rm.store)
-	rm.rooms[id] = room
-	return room
 }
 
 func (rm *RoomManager) List() []RoomInfo {
@@ -499,15 +1108,21 @@ func (rm *RoomManager) List() []RoomInfo {
 	for _, r := range rm.rooms {
 		list = append(list, r.Info())
 	}
+	sortRooms(list, roomSortCreated)
 	return list
 }
 
 func (rm *RoomManager) CleanupEmpty() {
 	rm.mu.Lock()
 	defer rm.mu.Unlock()
+	now := time.Now()
 	for id, r := range rm.rooms {
-		if r.PlayerCount() == 0 {
+		if r.ClientCount() == 0 && now.After(r.keepUntil) {
 			delete(rm.rooms, id)
+			if err := rm.store.DeleteRoomSnapshot(id); err != nil {
+				log.Error("delete room snapshot", "room", id, "error", err)
+			}
+			events.Emit(evRoomRemoved, "room", id, "reason", "empty")
 		}
 	}
 }
@@ -522,16 +1137,41 @@ type LobbyPlayer struct {
 }
 
 type SharedState struct {
-	Rooms   *RoomManager
-	lobbyMu sync.RWMutex
-	lobby   map[string]*LobbyPlayer
+	Config      config.Config
+	Rooms       *RoomManager
+	Tournaments *TournamentManager
+	Store       *SQLiteStore
+	Admins      []ssh.PublicKey
+	StartedAt   time.Time
+	moderator   *ChatModerator
+
+	lobbyMu      sync.RWMutex
+	lobby        map[string]*LobbyPlayer
+	lobbyHistory []RoomChatMsg
+
+	sessMu   sync.RWMutex
+	sessions map[string]*onlineSession
+
+	challengeMu sync.Mutex
+	challenges  map[string]*Challenge
+
+	limits sessionLimits
 }
 
-func NewSharedState(store *SQLiteStore) *SharedState {
-	return &SharedState{
-		Rooms: NewRoomManager(store),
-		lobby: make(map[string]*LobbyPlayer),
+func NewSharedState(cfg config.Config, store *SQLiteStore, moderator *ChatModerator) *SharedState {
+	s := &SharedState{
+		Config:     cfg,
+		Rooms:      NewRoomManager(store, moderator, cfg.Rooms),
+		Store:      store,
+		StartedAt:  time.Now(),
+		moderator:  moderator,
+		lobby:      make(map[string]*LobbyPlayer),
+		sessions:   make(map[string]*onlineSession),
+		challenges: make(map[string]*Challenge),
+		limits:     newSessionLimits(cfg.Limits),
 	}
+	s.Tournaments = NewTournamentManager(s)
+	return s
 }
 
 func (s *SharedState) AddToLobby(sessID, userID string, p *tea.Program) {
@@ -557,7 +1197,17 @@ func (s *SharedState) BroadcastLobby() {
 }
 
 func (s *SharedState) HandleDisconnect(sessID string) {
+	s.sessMu.RLock()
+	if sess, ok := s.sessions[sessID]; ok {
+		events.Emit(evDisconnected, "user", sess.UserID, "session", sessID, "addr", sess.Addr,
+			"duration", time.Since(sess.ConnectedAt).Round(time.Second).String())
+	}
+	s.sessMu.RUnlock()
+
 	s.RemoveFromLobby(sessID)
+	s.dropChallenges(sessID)
+	s.disconnect(sessID)
+	s.forgetLimits(sessID)
 
 	s.Rooms.mu.RLock()
 	rooms := make([]*Room, 0, len(s.Rooms.rooms))
@@ -578,6 +1228,9 @@ func (s *SharedState) StartCleanupLoop(interval time.Duration) {
 	defer ticker.Stop()
 	for range ticker.C {
 		s.Rooms.CleanupEmpty()
+		s.Rooms.ArchiveFinished()
+		s.Tournaments.LaunchReady()
+		s.expireIdle()
 		s.BroadcastLobby()
 	}
 }
@@ -591,24 +1244,37 @@ type viewState int
 const (
 	viewLobby viewState = iota
 	viewRoom
+	viewAdmin
 )
 
 type rootModel struct {
 	state   viewState
 	lobby   lobbyModel
 	room    *roomModel
-	shared  *SharedState
-	program *tea.Program
-	userID  string
-	sessID  string
-	width   int
-	height  int
+	dm      dmModel
+	invites []Challenge
+	// ticking is set while the invite countdown is being redrawn.
+	ticking bool
+	admin   bool
+	console adminModel
+	notice  AnnouncementMsg
+	// throttled is when keys were last dropped by the action limit; idle is
+	// set once the session is being disconnected for inactivity.
+	throttled time.Time
+	idle      time.Duration
+	shared    *SharedState
+	program   *tea.Program
+	userID    string
+	sessID    string
+	width     int
+	height    int
 }
 
 func NewRootModel(shared *SharedState, userID, sessID string) *rootModel {
 	return &rootModel{
 		state:  viewLobby,
-		lobby:  newLobbyModel(shared, userID),
+		lobby:  newLobbyModel(shared, sessID, userID),
+		dm:     newDMModel(shared, sessID, userID),
 		shared: shared,
 		userID: userID,
 		sessID: sessID,
@@ -632,6 +1298,101 @@ func (m rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.String() == "ctrl+c" {
 			return m, tea.Quit
 		}
+		if m.idle > 0 {
+			return m, nil
+		}
+		if !m.shared.AllowAction(m.sessID) {
+			m.throttled = time.Now()
+			return m, nil
+		}
+		if len(m.invites) > 0 {
+			switch msg.String() {
+			case "ctrl+y", "ctrl+n":
+				invite := m.invites[0]
+				m.invites = m.invites[1:]
+				_ = m.shared.RespondChallenge(invite.ID, m.sessID, msg.String() == "ctrl+y")
+				return m, nil
+			case "esc":
+				// Dismissing only hides the invite; it still expires on
+				// the challenger's side.
+				m.invites = m.invites[1:]
+				return m, nil
+			}
+		}
+		if m.dm.open {
+			var cmd tea.Cmd
+			m.dm, cmd = m.dm.Update(msg)
+			return m, cmd
+		}
+		if msg.String() == "ctrl+d" && m.shared.Config.Features.DirectMessages {
+			return m, m.dm.Open("")
+		}
+		if msg.String() == "ctrl+a" && m.admin && m.state != viewAdmin {
+			m.console = newAdminModel(m.shared, m.userID)
+			m.state = viewAdmin
+			return m, m.console.Init()
+		}
+
+	case IdleTimeoutMsg:
+		m.idle = msg.After
+		return m, tea.Tick(idleGoodbye, func(time.Time) tea.Msg { return tea.QuitMsg{} })
+
+	case closeAdminMsg:
+		m.state = viewLobby
+		if m.room != nil {
+			m.state = viewRoom
+		}
+		return m, nil
+
+	case AnnouncementMsg:
+		m.notice = msg
+		return m, nil
+
+	case RoomClosedMsg:
+		if m.room == nil {
+			return m, nil
+		}
+		m.lobby.notice = "Room closed: " + msg.Reason
+		return m.leaveRoom()
+
+	case OnlineUsersMsg:
+		m.dm, _ = m.dm.Update(msg)
+		m.lobby, _ = m.lobby.Update(msg)
+		return m, nil
+
+	case LobbyChatMsg, LobbyChatHistoryMsg, TournamentUpdateMsg, ProfileMsg:
+		var cmd tea.Cmd
+		m.lobby, cmd = m.lobby.Update(msg)
+		return m, cmd
+
+	case DirectMsg:
+		m.dm, _ = m.dm.Update(msg)
+		return m, nil
+
+	case ChallengeMsg:
+		m.invites = append(m.invites, msg.Challenge)
+		if m.ticking {
+			return m, nil
+		}
+		m.ticking = true
+		return m, challengeTick()
+
+	case challengeTickMsg:
+		if len(m.invites) == 0 {
+			m.ticking = false
+			return m, nil
+		}
+		return m, challengeTick()
+
+	case ChallengeClosedMsg:
+		for i, c := range m.invites {
+			if c.ID == msg.ID {
+				m.invites = append(m.invites[:i:i], m.invites[i+1:]...)
+				break
+			}
+		}
+		m.lobby, _ = m.lobby.Update(msg)
+		return m, nil
 
 	case JoinRoomMsg:
 		return m.joinRoom(msg.RoomID)
@@ -652,20 +1413,41 @@ func (m rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			*m.room, cmd = m.room.Update(msg)
 			return m, cmd
 		}
+
+	case viewAdmin:
+		var cmd tea.Cmd
+		m.console, cmd = m.console.Update(msg)
+		return m, cmd
 	}
 
 	return m, nil
 }
 
 func (m rootModel) joinRoom(roomID string) (tea.Model, tea.Cmd) {
-	room := m.shared.Rooms.GetOrCreate(roomID)
+	if m.room != nil {
+		if m.room.room.ID == roomID {
+			return m, nil
+		}
+		m.room.room.Leave(m.sessID)
+		m.room = nil
+	}
+
+	room, err := m.shared.Rooms.GetOrCreate(roomID, m.userID)
+	if err != nil {
+		m.state = viewLobby
+		m.lobby.notice = err.Error()
+		m.shared.AddToLobby(m.sessID, m.userID, m.program)
+		m.shared.SetLocation(m.sessID, "")
+		return m, nil
+	}
 	m.shared.RemoveFromLobby(m.sessID)
 
-	role := room.Join(m.sessID, m.userID, m.program)
+	role := room.Join(m.sessID, m.userID, m.program, m.admin)
 	rm := newRoomModel(room, m.sessID, m.userID, role, m.shared, m.width, m.height)
 
 	m.room = &rm
 	m.state = viewRoom
+	m.shared.SetLocation(m.sessID, roomID)
 	m.shared.BroadcastLobby()
 
 	return m, m.room.Init()
@@ -679,19 +1461,57 @@ func (m rootModel) leaveRoom() (tea.Model, tea.Cmd) {
 
 	m.state = viewLobby
 	m.shared.AddToLobby(m.sessID, m.userID, m.program)
-	m.lobby.rooms = m.shared.Rooms.List()
+	m.shared.SetLocation(m.sessID, "")
+	m.lobby.setRooms(m.shared.Rooms.List())
 	m.shared.BroadcastLobby()
 
 	return m, nil
 }
 
 func (m rootModel) View() string {
-	if m.state == viewRoom && m.room != nil {
-		return m.room.View()
+	if m.idle > 0 {
+		return lipgloss.Place(max(m.width, 40), max(m.height, 12),
+			lipgloss.Center, lipgloss.Center, viewIdleNotice(m.idle))
+	}
+	if m.dm.open {
+		return m.dm.View()
 	}
-	return m.lobby.View()
+
+	view := m.lobby.View()
+	switch {
+	case m.state == viewAdmin:
+		view = m.console.View()
+	case m.state == viewRoom && m.room != nil:
+		view = m.room.View()
+	}
+	if m.shared.Config.Features.DirectMessages {
+		view += "\n" + m.dm.viewUnreadBadge()
+	}
+	if m.admin && m.state != viewAdmin {
+		view += roomHelpText.Render("  ctrl+a: admin")
+	}
+
+	if m.notice.Text != "" && time.Since(m.notice.At) < announcementTTL {
+		banner := announcementStyle.Render(fmt.Sprintf("📣 %s: %s", m.notice.From, m.notice.Text))
+		view = banner + "\n" + view
+	}
+	if len(m.invites) > 0 {
+		view = viewChallengeBanner(m.invites[0], len(m.invites)-1) + "\n" + view
+	}
+	if time.Since(m.throttled) < throttleNoticeTTL {
+		view = viewThrottleNotice() + "\n" + view
+	}
+	return view
 }
 
+const announcementTTL = time.Minute
+
+var announcementStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("0")).
+	Background(lipgloss.Color("3")).
+	Bold(true).
+	Padding(0, 1)
+
 // ─────────────────────────────────────────────────────────────────────────────
 // Lobby Model
 // ─────────────────────────────────────────────────────────────────────────────
@@ -701,31 +1521,110 @@ type lobbyMode int
 const (
 	lobbyBrowse lobbyMode = iota
 	lobbyCreate
+	lobbySearch
+	lobbyChallenge
 )
 
-type lobbyModel struct {
-	rooms  []RoomInfo
-	cursor int
-	mode   lobbyMode
-	input  textinput.Model
-	shared *SharedState
-	userID string
-	width  int
-	height int
-}
+type lobbyTab int
+
+const (
+	tabRooms lobbyTab = iota
+	tabLeaderboard
+	tabChat
+	tabTournaments
+	tabReplays
+	tabProfile
+)
 
-func newLobbyModel(shared *SharedState, userID string) lobbyModel {
+type lobbyModel struct {
+	rooms       []RoomInfo
+	visible     []RoomInfo
+	cursor      int
+	selected    string
+	roomSort    roomSort
+	roomFilter  roomFilter
+	mode        lobbyMode
+	tab         lobbyTab
+	input       textinput.Model
+	search      textinput.Model
+	leaderboard leaderboardModel
+	chat        lobbyChatModel
+	tournaments tournamentsModel
+	replays     replayModel
+	profile     profileModel
+	challenge   challengeForm
+	create      createForm
+	notice      string
+	createErr   string
+	shared      *SharedState
+	userID      string
+	width       int
+	height      int
+}
+
+func newLobbyModel(shared *SharedState, sessID, userID string) lobbyModel {
 	ti := textinput.New()
 	ti.Placeholder = "Room name..."
-	ti.CharLimit = 20
-	ti.Width = 20
+	ti.CharLimit = maxRoomNameLen
+	ti.Width = maxRoomNameLen
+
+	si := textinput.New()
+	si.Prompt = "/"
+	si.Placeholder = "search rooms"
+	si.CharLimit = 20
+	si.Width = 20
+
+	m := lobbyModel{
+		shared:      shared,
+		userID:      userID,
+		input:       ti,
+		search:      si,
+		leaderboard: newLeaderboardModel(shared.Store, userID),
+		chat:        newLobbyChatModel(shared, sessID, userID),
+		tournaments: newTournamentsModel(shared, userID),
+		replays:     newReplayModel(shared.Store),
+		profile:     newProfileModel(shared.Store, userID),
+		create:      newCreateForm(),
+	}
+	m.setRooms(shared.Rooms.List())
+	if ids := shared.Rooms.ReservedFor(userID); len(ids) > 0 {
+		m.notice = fmt.Sprintf("You have a game in progress in %s, join it to resume.", strings.Join(ids, ", "))
+	}
+	return m
+}
+
+// setRooms replaces the room list and recomputes the visible rows.
+func (m *lobbyModel) setRooms(rooms []RoomInfo) {
+	m.rooms = rooms
+	m.refreshVisible()
+}
+
+// refreshVisible re-sorts and re-filters the rooms, keeping the cursor on the
+// previously selected room ID when it is still visible.
+func (m *lobbyModel) refreshVisible() {
+	sorted := append([]RoomInfo(nil), m.rooms...)
+	sortRooms(sorted, m.roomSort)
+	m.visible = filterRooms(sorted, m.roomFilter, m.search.Value())
+
+	m.cursor = min(m.cursor, max(len(m.visible)-1, 0))
+	for i, r := range m.visible {
+		if r.ID == m.selected {
+			m.cursor = i
+			break
+		}
+	}
+	m.selected = ""
+	if len(m.visible) > 0 {
+		m.selected = m.visible[m.cursor].ID
+	}
+}
 
-	return lobbyModel{
-		rooms:  shared.Rooms.List(),
-		shared: shared,
-		userID: userID,
-		input:  ti,
+func (m *lobbyModel) moveCursor(delta int) {
+	if len(m.visible) == 0 {
+		return
 	}
+	m.cursor = min(max(m.cursor+delta, 0), len(m.visible)-1)
+	m.selected = m.visible[m.cursor].ID
 }
 
 func (m lobbyModel) Init() tea.Cmd { return nil }
@@ -733,15 +1632,76 @@ func (m lobbyModel) Init() tea.Cmd { return nil }
 func (m lobbyModel) Update(msg tea.Msg) (lobbyModel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case RoomListUpdateMsg:
-		m.rooms = msg.Rooms
-		if m.cursor >= len(m.rooms) && len(m.rooms) > 0 {
-			m.cursor = len(m.rooms) - 1
-		}
+		m.setRooms(msg.Rooms)
+		return m, nil
+
+	case LeaderboardMsg:
+		var cmd tea.Cmd
+		m.leaderboard, cmd = m.leaderboard.Update(msg)
+		return m, cmd
+
+	case OnlineUsersMsg:
+		m.challenge.users = msg.Users
+		var cmd tea.Cmd
+		m.chat, cmd = m.chat.Update(msg)
+		return m, cmd
+
+	case LobbyChatMsg, LobbyChatHistoryMsg:
+		var cmd tea.Cmd
+		m.chat, cmd = m.chat.Update(msg)
+		return m, cmd
+
+	case ChallengeClosedMsg:
+		m.notice = "Challenge " + msg.Reason
+		return m, nil
+
+	case TournamentUpdateMsg:
+		m.tournaments.Load()
 		return m, nil
 
+	case ReplayListMsg, ReplayGameMsg:
+		var cmd tea.Cmd
+		m.replays, cmd = m.replays.Update(msg)
+		return m, cmd
+
+	case ProfileMsg:
+		var cmd tea.Cmd
+		m.profile, cmd = m.profile.Update(msg)
+		return m, cmd
+
 	case tea.KeyMsg:
-		if m.mode == lobbyCreate {
+		switch m.mode {
+		case lobbyCreate:
 			return m.handleCreateInput(msg)
+		case lobbySearch:
+			return m.handleSearchInput(msg)
+		case lobbyChallenge:
+			return m.handleChallengeInput(msg)
+		}
+		if msg.String() == "tab" && !m.prompting() {
+			return m.switchTab()
+		}
+		switch m.tab {
+		case tabLeaderboard:
+			var cmd tea.Cmd
+			m.leaderboard, cmd = m.leaderboard.Update(msg)
+			return m, cmd
+		case tabChat:
+			var cmd tea.Cmd
+			m.chat, cmd = m.chat.Update(msg)
+			return m, cmd
+		case tabTournaments:
+			var cmd tea.Cmd
+			m.tournaments, cmd = m.tournaments.Update(msg)
+			return m, cmd
+		case tabReplays:
+			var cmd tea.Cmd
+			m.replays, cmd = m.replays.Update(msg)
+			return m, cmd
+		case tabProfile:
+			var cmd tea.Cmd
+			m.profile, cmd = m.profile.Update(msg)
+			return m, cmd
 		}
 		return m.handleBrowseInput(msg)
 	}
@@ -749,40 +1709,171 @@ func (m lobbyModel) Update(msg tea.Msg) (lobbyModel, tea.Cmd) {
 	return m, nil
 }
 
+// prompting reports whether the current tab has a text prompt open, which
+// keeps the tab key from switching away from it.
+func (m lobbyModel) prompting() bool {
+	switch m.tab {
+	case tabTournaments:
+		return m.tournaments.mode == tournamentsCreate
+	case tabReplays:
+		return m.replays.Importing()
+	case tabProfile:
+		return m.profile.Finding()
+	}
+	return false
+}
+
+// tabs lists the lobby tabs enabled in the server configuration.
+func (m lobbyModel) tabs() []lobbyTab {
+	features := m.shared.Config.Features
+	tabs := []lobbyTab{tabRooms}
+	if features.Leaderboard {
+		tabs = append(tabs, tabLeaderboard)
+	}
+	if features.LobbyChat {
+		tabs = append(tabs, tabChat)
+	}
+	if features.Tournaments {
+		tabs = append(tabs, tabTournaments)
+	}
+	return append(tabs, tabReplays, tabProfile)
+}
+
+func (m lobbyModel) nextTab() lobbyTab {
+	tabs := m.tabs()
+	for i, t := range tabs {
+		if t == m.tab {
+			return tabs[(i+1)%len(tabs)]
+		}
+	}
+	return tabRooms
+}
+
+func (m lobbyModel) switchTab() (lobbyModel, tea.Cmd) {
+	m.chat.Blur()
+	m.tab = m.nextTab()
+	switch m.tab {
+	case tabLeaderboard:
+		return m, m.leaderboard.Load()
+	case tabChat:
+		return m, m.chat.Focus()
+	case tabTournaments:
+		m.tournaments.Load()
+		return m, nil
+	case tabReplays:
+		return m, m.replays.Load()
+	case tabProfile:
+		return m, m.profile.Load()
+	default:
+		return m, nil
+	}
+}
+
 func (m lobbyModel) handleBrowseInput(msg tea.KeyMsg) (lobbyModel, tea.Cmd) {
 	switch msg.String() {
 	case "up", "k":
-		if m.cursor > 0 {
-			m.cursor--
-		}
+		m.moveCursor(-1)
 
 	case "down", "j":
-		if m.cursor < len(m.rooms)-1 {
-			m.cursor++
-		}
+		m.moveCursor(1)
 
 	case "enter":
-		if len(m.rooms) > 0 {
-			id := m.rooms[m.cursor].ID
+		if len(m.visible) > 0 {
+			id := m.visible[m.cursor].ID
 			return m, func() tea.Msg { return JoinRoomMsg{RoomID: id} }
 		}
 
 	case "c":
 		m.mode = lobbyCreate
+		m.createErr = ""
+		m.create.field = createName
 		m.input.Reset()
 		m.input.Focus()
 		return m, textinput.Blink
+
+	case "p":
+		if !m.shared.Config.Features.Challenges {
+			break
+		}
+		m.mode = lobbyChallenge
+		m.challenge.userID = m.userID
+		m.notice = ""
+
+	case "/":
+		m.mode = lobbySearch
+		m.search.Focus()
+		return m, textinput.Blink
+
+	case "o":
+		m.roomSort = (m.roomSort + 1) % (roomSortPlayers + 1)
+		m.refreshVisible()
+
+	case "f":
+		m.roomFilter = (m.roomFilter + 1) % (roomFilterFinished + 1)
+		m.refreshVisible()
+	}
+
+	return m, nil
+}
+
+func (m lobbyModel) handleChallengeInput(msg tea.KeyMsg) (lobbyModel, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = lobbyBrowse
+		return m, nil
+
+	case "enter":
+		names := m.challenge.opponents()
+		if len(names) == 0 {
+			return m, nil
+		}
+		to := names[min(m.challenge.opponent, len(names)-1)]
+		if _, err := m.shared.SendChallenge(m.chat.sessID, to, m.challenge.settings()); err != nil {
+			m.notice = err.Error()
+		} else {
+			m.notice = fmt.Sprintf("Challenge sent to %s, waiting for an answer...", to)
+		}
+		m.mode = lobbyBrowse
+		return m, nil
 	}
 
+	m.challenge = m.challenge.Update(msg)
 	return m, nil
 }
 
+func (m lobbyModel) handleSearchInput(msg tea.KeyMsg) (lobbyModel, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		m.mode = lobbyBrowse
+		m.search.Blur()
+		return m, nil
+
+	case "esc":
+		m.mode = lobbyBrowse
+		m.search.Blur()
+		m.search.Reset()
+		m.refreshVisible()
+		return m, nil
+
+	case "up", "down":
+		return m.handleBrowseInput(msg)
+	}
+
+	var cmd tea.Cmd
+	m.search, cmd = m.search.Update(msg)
+	m.refreshVisible()
+	return m, cmd
+}
+
 func (m lobbyModel) handleCreateInput(msg tea.KeyMsg) (lobbyModel, tea.Cmd) {
 	switch msg.String() {
 	case "enter":
 		name := strings.TrimSpace(m.input.Value())
 		if name != "" {
-			m.shared.Rooms.Create(name)
+			if _, err := m.shared.Rooms.Create(name, m.create.settings(), m.userID); err != nil {
+				m.createErr = err.Error()
+				return m, nil
+			}
 			m.shared.BroadcastLobby()
 			m.mode = lobbyBrowse
 			return m, func() tea.Msg { return JoinRoomMsg{RoomID: name} }
@@ -791,6 +1882,20 @@ func (m lobbyModel) handleCreateInput(msg tea.KeyMsg) (lobbyModel, tea.Cmd) {
 	case "esc":
 		m.mode = lobbyBrowse
 		return m, nil
+
+	case "up", "down", "tab", "shift+tab":
+		m.create = m.create.Update(msg)
+		if m.create.field == createName {
+			m.input.Focus()
+		} else {
+			m.input.Blur()
+		}
+		return m, nil
+	}
+
+	if m.create.field != createName {
+		m.create = m.create.Update(msg)
+		return m, nil
 	}
 
 	var cmd tea.Cmd
@@ -814,6 +1919,9 @@ var (
 	lobbyStatusFinished = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
 
 	lobbyHelpStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("241")).MarginTop(1)
+
+	lobbyTabActive   = lipgloss.NewStyle().Foreground(lipgloss.Color("170")).Bold(true).Underline(true)
+	lobbyTabInactive = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
 )
 
 func (m lobbyModel) View() string {
@@ -823,16 +1931,76 @@ func (m lobbyModel) View() string {
 	b.WriteString("\n\n")
 
 	if m.mode == lobbyCreate {
-		b.WriteString("  Enter room name:\n\n")
+		b.WriteString("  Enter room name (letters, digits, spaces, - _ .):\n\n")
 		b.WriteString("  " + m.input.View() + "\n\n")
-		b.WriteString(lobbyHelpStyle.Render("  enter: create  esc: cancel"))
+		b.WriteString(m.create.View() + "\n")
+		if m.createErr != "" {
+			b.WriteString("  " + dmUnreadStyle.Render(m.createErr) + "\n\n")
+		}
+		b.WriteString(lobbyHelpStyle.Render("  ↑/↓: field  ←/→: change  enter: create  esc: cancel"))
+		return b.String()
+	}
+
+	if m.mode == lobbyChallenge {
+		b.WriteString("  Challenge a player:\n\n")
+		b.WriteString(m.challenge.View())
+		b.WriteString(lobbyHelpStyle.Render("  ↑/↓: field  ←/→: change  enter: send  esc: cancel"))
+		return b.String()
+	}
+
+	b.WriteString("  " + m.viewTabs() + "\n\n")
+	if m.notice != "" {
+		b.WriteString("  " + focusLabel.Render(m.notice) + "\n\n")
+	}
+
+	if m.tab == tabLeaderboard {
+		b.WriteString(m.leaderboard.View())
+		b.WriteString(lobbyHelpStyle.Render("  ←/→: page  s: sort  w: window  m: my rank  r: refresh  " + m.viewTabHelp() + "ctrl+c: quit"))
+		return b.String()
+	}
+
+	if m.tab == tabChat {
+		b.WriteString(m.chat.View() + "\n")
+		b.WriteString(lobbyHelpStyle.Render("  type to chat  /help: commands  enter: send  " + m.viewTabHelp() + "ctrl+c: quit"))
+		return b.String()
+	}
+
+	if m.tab == tabTournaments {
+		b.WriteString(m.tournaments.View())
+		b.WriteString(lobbyHelpStyle.Render(m.tournaments.Help() + m.viewTabHelp() + "ctrl+c: quit"))
+		return b.String()
+	}
+
+	if m.tab == tabReplays {
+		b.WriteString(m.replays.View())
+		b.WriteString(lobbyHelpStyle.Render(m.replays.Help() + m.viewTabHelp() + "ctrl+c: quit"))
+		return b.String()
+	}
+
+	if m.tab == tabProfile {
+		b.WriteString(m.profile.View())
+		help := "  /: find player  m: me  r: refresh  "
+		if m.profile.Finding() {
+			help = "  enter: show  esc: cancel  "
+		}
+		b.WriteString(lobbyHelpStyle.Render(help + m.viewTabHelp() + "ctrl+c: quit"))
 		return b.String()
 	}
 
-	if len(m.rooms) == 0 {
+	b.WriteString(lobbyHelpStyle.UnsetMarginTop().Render(
+		fmt.Sprintf("  sort: %s  filter: %s", m.roomSort, m.roomFilter)) + "\n")
+	if m.mode == lobbySearch || m.search.Value() != "" {
+		b.WriteString("  " + m.search.View() + "\n")
+	}
+	b.WriteString("\n")
+
+	switch {
+	case len(m.rooms) == 0:
 		b.WriteString("  No rooms yet. Press 'c' to create one.\n")
-	} else {
-		for i, room := range m.rooms {
+	case len(m.visible) == 0:
+		b.WriteString("  No rooms match.\n")
+	default:
+		for i, room := range m.visible {
 			cursor := "  "
 			style := lobbyItemStyle
 			if i == m.cursor {
@@ -842,16 +2010,58 @@ func (m lobbyModel) View() string {
 
 			status := renderStatus(room.Status)
 			line := fmt.Sprintf("%s%s  [%d/2]  %s", cursor, room.ID, room.Players, status)
+			if room.Spectators > 0 {
+				line += fmt.Sprintf("  👁 %d", room.Spectators)
+			}
 			b.WriteString(style.Render(line) + "\n")
 		}
 	}
 
 	b.WriteString("\n")
-	b.WriteString(lobbyHelpStyle.Render("  ↑/↓: navigate  enter: join  c: create  ctrl+c: quit"))
+	if m.mode == lobbySearch {
+		b.WriteString(lobbyHelpStyle.Render("  type to filter  enter: done  esc: clear"))
+		return b.String()
+	}
+	help := "  ↑/↓: navigate  enter: join  c: create  "
+	if m.shared.Config.Features.Challenges {
+		help += "p: challenge  "
+	}
+	help += "/: search  o: sort  f: filter  " + m.viewTabHelp() + "ctrl+c: quit"
+	b.WriteString(lobbyHelpStyle.Render(help))
 
 	return b.String()
 }
 
+var lobbyTabLabels = map[lobbyTab]string{
+	tabRooms:       "Rooms",
+	tabLeaderboard: "Leaderboard",
+	tabChat:        "Chat",
+	tabTournaments: "Tournaments",
+	tabReplays:     "Replays",
+	tabProfile:     "Profile",
+}
+
+func (m lobbyModel) viewTabs() string {
+	var rendered []string
+	for _, t := range m.tabs() {
+		if t == m.tab {
+			rendered = append(rendered, lobbyTabActive.Render(lobbyTabLabels[t]))
+		} else {
+			rendered = append(rendered, lobbyTabInactive.Render(lobbyTabLabels[t]))
+		}
+	}
+	return strings.Join(rendered, "  ")
+}
+
+// viewTabHelp names the tab the tab key switches to, if there is one.
+func (m lobbyModel) viewTabHelp() string {
+	next := m.nextTab()
+	if next == m.tab {
+		return ""
+	}
+	return "tab: " + strings.ToLower(lobbyTabLabels[next]) + "  "
+}
+
 func renderStatus(status string) string {
 	switch status {
 	case "waiting":
@@ -884,12 +2094,26 @@ type roomModel struct {
 	focus     focusPane
 	cursorRow int
 	cursorCol int
+	// cursorLayer is the grid the cursor is on when a board has several.
+	cursorLayer int
+	// placeMark is the mark placed in variants that let players choose.
+	placeMark rune
 
-	cells       [9]rune
+	board       variant.State
+	sequence    []int
 	currentTurn string
 	gameOver    bool
 	winner      string
 	gameStarted bool
+	roster      RosterMsg
+	clocks      [2]time.Duration
+	clocksAt    time.Time
+
+	// hint is the cell the engine suggests, or -1.
+	hint int
+	// analysis is the finished game walked move by move, when open.
+	analysis  *gameAnalysis
+	analysing bool
 
 	chatViewport viewport.Model
 	chatInput    textinput.Model
@@ -908,11 +2132,6 @@ func newRoomModel(room *Room, sessID, userID string, role PlayerRole, shared *Sh
 	ti.CharLimit = 200
 	ti.Width = 28
 
-	var cells [9]rune
-	for i := range cells {
-		cells[i] = ' '
-	}
-
 	focus := paneGame
 	if role == RoleSpectator {
 		focus = paneChat
@@ -922,13 +2141,18 @@ func newRoomModel(room *Room, sessID, userID string, role PlayerRole, shared *Sh
 	return roomModel{
 		room: room, shared: shared,
 		sessID: sessID, userID: userID, role: role,
-		focus: focus, cells: cells,
+		focus: focus, board: newBoard(room.Settings()), placeMark: 'X', hint: -1,
 		chatViewport: vp, chatInput: ti, chatLog: []string{},
 		width: w, height: h,
 	}
 }
 
-func (m roomModel) Init() tea.Cmd { return nil }
+func (m roomModel) Init() tea.Cmd {
+	if m.room.Settings().TimeControl.Timed() {
+		return clockTick()
+	}
+	return nil
+}
 
 func (m roomModel) Update(msg tea.Msg) (roomModel, tea.Cmd) {
 	switch msg := msg.(type) {
@@ -940,16 +2164,50 @@ func (m roomModel) Update(msg tea.Msg) (roomModel, tea.Cmd) {
 
 	case RoleAssignedMsg:
 		m.role = msg.Role
+		if m.role != RoleSpectator && m.focus == paneChat {
+			m.toggleFocus()
+		}
+
+	case RosterMsg:
+		m.roster = msg
 
 	case GameUpdateMsg:
-		m.cells = msg.Cells
+		m.board = msg.Board
+		m.sequence = msg.Sequence
+		m.hint = -1
+		if !msg.IsOver {
+			m.analysis = nil
+		}
+		m.followForcedBoard()
 		m.currentTurn = msg.CurrentTurn
 		m.gameOver = msg.IsOver
 		m.winner = msg.Winner
 		m.gameStarted = true
+		m.clocks, m.clocksAt = msg.Clocks, msg.At
+
+	case clockTickMsg:
+		return m, clockTick()
+
+	case hintMsg:
+		if msg.moves == len(m.sequence) && !m.gameOver {
+			m.hint = msg.cell
+		}
+
+	case analysisMsg:
+		m.analysing = false
+		m.analysis = msg.analysis
 
 	case RoomChatMsg:
-		m.appendChat(fmt.Sprintf("%s: %s", msg.Sender, msg.Text))
+		m.appendChat(formatChat(msg))
+
+	case ChatHistoryMsg:
+		lines := make([]string, 0, len(msg.Messages)+len(m.chatLog))
+		for _, c := range msg.Messages {
+			lines = append(lines, formatChat(c))
+		}
+		m.chatLog = append(lines, m.chatLog...)
+		m.chatViewport.SetContent(strings.Join(m.chatLog, "\n"))
+		m.chatViewport.GotoBottom()
 
 	case PlayerJoinedMsg:
 		m.appendChat(fmt.Sprintf("* %s joined as %s", msg.Name, msg.Role))
@@ -958,14 +2216,26 @@ func (m roomModel) Update(msg tea.Msg) (roomModel, tea.Cmd) {
 		m.appendChat(fmt.Sprintf("* %s left", msg.Name))
 
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c":
+		if msg.String() == "ctrl+c" {
 			return m, tea.Quit
+		}
+		if m.analysis != nil {
+			return m.handleAnalysisInput(msg)
+		}
+
+		switch msg.String() {
 		case "esc":
 			return m, func() tea.Msg { return LeaveRoomMsg{} }
 		case "tab":
 			m.toggleFocus()
 			return m, nil
+		case "ctrl+p":
+			if m.role == RoleSpectator {
+				if _, ok := m.room.ClaimSeat(m.sessID); ok {
+					m.shared.BroadcastLobby()
+				}
+			}
+			return m, nil
 		}
 
 		if m.focus == paneGame {
@@ -994,19 +2264,40 @@ func (m *roomModel) toggleFocus() {
 }
 
 func (m roomModel) handleGameInput(msg tea.KeyMsg) (roomModel, tea.Cmd) {
+	switch msg.String() {
+	case "?":
+		return m.requestHint()
+	case "a":
+		return m.requestAnalysis()
+	}
+	if g, ok := gravityOf(m.board); ok {
+		return m.handleDropInput(g, msg)
+	}
+
 	switch msg.String() {
 	case "up", "k":
 		m.cursorRow = max(0, m.cursorRow-1)
 	case "down", "j":
-		m.cursorRow = min(2, m.cursorRow+1)
+		m.cursorRow = min(layoutOf(m.board).rows-1, m.cursorRow+1)
 	case "left", "h":
 		m.cursorCol = max(0, m.cursorCol-1)
 	case "right", "l":
-		m.cursorCol = min(2, m.cursorCol+1)
+		m.cursorCol = min(layoutOf(m.board).cols-1, m.cursorCol+1)
+	case "[", "]":
+		if n := layoutOf(m.board).layers; n > 1 {
+			step := 1
+			if msg.String() == "[" {
+				step = n - 1
+			}
+			m.cursorLayer = (m.cursorLayer + step) % n
+		}
+	case "m":
+		if _, ok := m.board.(variant.MarkChooser); ok {
+			m.placeMark = variant.Other(m.placeMark)
+		}
 	case "enter", " ":
 		if m.role != RoleSpectator && m.gameStarted && !m.gameOver {
-			pos := m.cursorRow*3 + m.cursorCol
-			m.room.HandleMove(m.sessID, pos)
+			m.room.HandleMove(m.sessID, m.cursorMove())
 		}
 	}
 	return m, nil
@@ -1016,7 +2307,7 @@ func (m roomModel) handleChatInput(msg tea.KeyMsg) (roomModel, tea.Cmd) {
 	if msg.String() == "enter" {
 		text := strings.TrimSpace(m.chatInput.Value())
 		if text != "" {
-			m.room.BroadcastChat(m.userID, text)
+			m.room.HandleChat(m.sessID, text)
 			m.chatInput.Reset()
 		}
 		return m, nil
@@ -1044,6 +2335,7 @@ var (
 	cellDefault   = lipgloss.NewStyle().Width(3).Height(1).Align(lipgloss.Center)
 	cellHighlight = lipgloss.NewStyle().Width(3).Height(1).Align(lipgloss.Center).
 			Background(lipgloss.Color("62")).Foreground(lipgloss.Color("0"))
+	cellHint = cellDefault.Background(lipgloss.Color("28")).Foreground(lipgloss.Color("0"))
 
 	markX = lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Bold(true)
 	markO = lipgloss.NewStyle().Foreground(lipgloss.Color("4")).Bold(true)
@@ -1059,48 +2351,105 @@ func (m roomModel) View() string {
 
 	panels := lipgloss.JoinHorizontal(lipgloss.Top, game, chat)
 	status := m.viewStatusBar()
+	roster := m.viewRoster()
 	help := m.viewHelp()
 
-	return lipgloss.JoinVertical(lipgloss.Left, panels, status, help)
+	return lipgloss.JoinVertical(lipgloss.Left, panels, status, roster, help)
+}
+
+func (m roomModel) viewRoster() string {
+	seat := func(name string) string {
+		if name == "" {
+			return "(open)"
+		}
+		return name
+	}
+
+	watching := "nobody"
+	if len(m.roster.Spectators) > 0 {
+		watching = strings.Join(m.roster.Spectators, ", ")
+	}
+
+	return roomHelpText.Render(fmt.Sprintf("X: %s  O: %s  Watching (%d): %s",
+		seat(m.roster.PlayerX), seat(m.roster.PlayerO), len(m.roster.Spectators), watching))
 }
 
 func (m roomModel) viewGamePanel() string {
 	var b strings.Builder
 
+	title := "GAME"
+	if m.analysis != nil {
+		title = "ANALYSIS"
+	}
 	if m.focus == paneGame {
-		b.WriteString(focusLabel.Render("▸ GAME") + "\n\n")
+		b.WriteString(focusLabel.Render("▸ "+title) + "\n\n")
 	} else {
-		b.WriteString("  GAME\n\n")
+		b.WriteString("  " + title + "\n\n")
 	}
 
-	for row := 0; row < 3; row++ {
-		var rendered []string
-		for col := 0; col < 3; col++ {
-			idx := row*3 + col
-			ch := m.cells[idx]
-
-			display := " "
-			if ch == 'X' {
-				display = markX.Render("X")
-			} else if ch == 'O' {
-				display = markO.Render("O")
-			}
+	if m.analysis != nil {
+		b.WriteString(m.viewAnalysis())
+	} else {
+		b.WriteString(m.viewBoard())
+	}
+
+	return boardBorder.Render(b.String())
+}
+
+// viewBoard draws the board in whichever way suits its variant.
+func (m roomModel) viewBoard() string {
+	layout := layoutOf(m.board)
+	if u, ok := m.board.(*variant.Ultimate); ok {
+		return m.viewUltimate(u)
+	} else if g, ok := gravityOf(m.board); ok {
+		return m.viewDrop(g)
+	} else if layout.layers > 1 {
+		return m.viewLayers(layout)
+	}
+	cursor := -1
+	if m.focus == paneGame {
+		cursor = m.cursorRow*layout.cols + m.cursorCol
+	}
+	return renderGrid(m.board.Cells(), layout.cols, layout.rows, cursor, m.hint, "  ")
+}
 
-			isCursor := row == m.cursorRow && col == m.cursorCol && m.focus == paneGame
-			if isCursor {
+// renderGrid draws a cols×rows grid of cells with the cells at index cursor
+// and hint highlighted; -1 highlights none. Each line starts with indent.
+func renderGrid(cells []rune, cols, rows, cursor, hint int, indent string) string {
+	var b strings.Builder
+	rule := strings.TrimSuffix(strings.Repeat("───┼", cols), "┼")
+	for row := 0; row < rows; row++ {
+		var rendered []string
+		for col := 0; col < cols; col++ {
+			idx := row*cols + col
+			display := renderMark(cells[idx])
+			switch idx {
+			case cursor:
 				rendered = append(rendered, cellHighlight.Render(display))
-			} else {
+			case hint:
+				rendered = append(rendered, cellHint.Render(display))
+			default:
 				rendered = append(rendered, cellDefault.Render(display))
 			}
 		}
 
-		b.WriteString("  " + strings.Join(rendered, "│") + "\n")
-		if row < 2 {
-			b.WriteString("  ───┼───┼───\n")
+		b.WriteString(indent + strings.Join(rendered, "│") + "\n")
+		if row < rows-1 {
+			b.WriteString(indent + rule + "\n")
 		}
 	}
+	return b.String()
+}
 
-	return boardBorder.Render(b.String())
+func renderMark(ch rune) string {
+	switch ch {
+	case 'X':
+		return markX.Render("X")
+	case 'O':
+		return markO.Render("O")
+	default:
+		return " "
+	}
 }
 
 func (m roomModel) viewChatPanel() string {
@@ -1121,6 +2470,16 @@ func (m roomModel) viewChatPanel() string {
 func (m roomModel) viewStatusBar() string {
 	parts := []string{fmt.Sprintf("You: %s", m.role)}
 
+	if settings := m.room.Settings(); settings.TimeControl.Timed() {
+		clocks := m.clocks
+		if !m.gameStarted {
+			clocks = [2]time.Duration{settings.TimeControl.Base, settings.TimeControl.Base}
+		} else if !m.gameOver {
+			clocks[sideIndex(rune(m.currentTurn[0]))] -= time.Since(m.clocksAt)
+		}
+		parts = append(parts, fmt.Sprintf("X %s  O %s", formatClock(clocks[0]), formatClock(clocks[1])))
+	}
+
 	switch {
 	case !m.gameStarted:
 		parts = append(parts, "Waiting for opponent...")
@@ -1130,14 +2489,49 @@ func (m roomModel) viewStatusBar() string {
 		parts = append(parts, "Draw!")
 	default:
 		parts = append(parts, fmt.Sprintf("Turn: %s", m.currentTurn))
+		switch board := m.board.(type) {
+		case *variant.Ultimate:
+			parts = append(parts, ultimateStatus(board))
+		case variant.MarkChooser:
+			parts = append(parts, fmt.Sprintf("Placing: %c", m.placeMark))
+		}
+	}
+	if _, ok := m.board.(*variant.OrderChaos); ok {
+		parts = append(parts, "X is Order, O is Chaos")
+	}
+	if m.analysing {
+		parts = append(parts, "Analysing game...")
 	}
 
 	return roomStatus.Render(strings.Join(parts, "  "))
 }
 
 func (m roomModel) viewHelp() string {
+	help := "type to chat  /help: commands  enter: send  tab: game  esc: leave"
 	if m.focus == paneGame {
-		return roomHelpText.Render("↑/↓/←/→: move  enter: place  tab: chat  esc: leave")
+		keys := []string{"↑/↓/←/→: move"}
+		if g, ok := gravityOf(m.board); ok {
+			keys = []string{dropKeysHelp(g.Options().Cols)}
+		}
+		if layout := layoutOf(m.board); layout.layers > 1 {
+			keys = append(keys, "[/]: "+strings.ToLower(layout.label))
+		}
+		if _, ok := m.board.(variant.MarkChooser); ok {
+			keys = append(keys, "m: mark")
+		}
+		if m.role != RoleSpectator && !m.gameOver && hintsAllowed(m.room.Settings()) {
+			keys = append(keys, "?: hint")
+		}
+		if m.gameOver {
+			keys = append(keys, "a: analyse")
+		}
+		help = strings.Join(append(keys, "enter: place  tab: chat  esc: leave"), "  ")
+	}
+	if m.analysis != nil {
+		help = "←/→: step through moves  home/end: first/last  a/esc: back to the game"
+	}
+	if m.role == RoleSpectator && !m.gameStarted && (m.roster.PlayerX == "" || m.roster.PlayerO == "") {
+		help += "  ctrl+p: take open seat"
 	}
-	return roomHelpText.Render("type to chat  enter: send  tab: game  esc: leave")
+	return roomHelpText.Render(help)
 }
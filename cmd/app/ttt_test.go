@@ -0,0 +1,143 @@
+package main
+
+import "testing"
+
+func TestEloUpdate(t *testing.T) {
+	tests := []struct {
+		name         string
+		a, b         int
+		score        float64
+		wantA, wantB int
+	}{
+		{"a win between equals", 1200, 1200, 1, 1216, 1184},
+		{"a loss between equals", 1200, 1200, 0, 1184, 1216},
+		{"a draw between equals", 1200, 1200, 0.5, 1200, 1200},
+		{"the favourite winning gains little", 1600, 1200, 1, 1603, 1197},
+		{"the underdog winning gains a lot", 1200, 1600, 1, 1229, 1571},
+		{"a draw moves the favourite down", 1600, 1200, 0.5, 1587, 1213},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := eloUpdate(tt.a, tt.b, tt.score)
+			if a != tt.wantA || b != tt.wantB {
+				t.Errorf("got %d and %d, want %d and %d", a, b, tt.wantA, tt.wantB)
+			}
+			if a+b != tt.a+tt.b {
+				t.Errorf("ratings total %d, want %d", a+b, tt.a+tt.b)
+			}
+		})
+	}
+}
+
+func TestRecordGame(t *testing.T) {
+	t.Run("a game between people moves wins and ratings", func(t *testing.T) {
+		store := newTestStore(t)
+		store.RecordGame(GameResult{Room: "r", PlayerX: "ann", PlayerO: "bob", Winner: "X"})
+		if got := store.GetPlayerScore("ann"); got != 1 {
+			t.Errorf("got %d wins, want 1", got)
+		}
+		var rating int
+		if err := store.db.QueryRow("SELECT rating FROM players WHERE name = 'ann'").Scan(&rating); err != nil {
+			t.Fatal(err)
+		}
+		if rating <= 1200 {
+			t.Errorf("got rating %d, want it above 1200", rating)
+		}
+	})
+	t.Run("a game against the computer is unrated", func(t *testing.T) {
+		store := newTestStore(t)
+		store.RecordGame(GameResult{Room: "r", PlayerX: "ann", PlayerO: computerEasy.Name(), Winner: "X"})
+		var wins, rating int
+		if err := store.db.QueryRow("SELECT wins, rating FROM players WHERE name = 'ann'").Scan(&wins, &rating); err != nil {
+			t.Fatal(err)
+		}
+		if wins != 0 || rating != 1200 {
+			t.Errorf("got %d wins and rating %d, want 0 and 1200", wins, rating)
+		}
+		var computers int
+		if err := store.db.QueryRow("SELECT COUNT(*) FROM players WHERE name = ?", computerEasy.Name()).Scan(&computers); err != nil {
+			t.Fatal(err)
+		}
+		if computers != 0 {
+			t.Error("the computer was added to the players table")
+		}
+	})
+}
+
+func TestValidateRoomName(t *testing.T) {
+	tests := []struct {
+		name string
+		ok   bool
+	}{
+		{"lobby", true},
+		{"Room 42", true},
+		{"a-b_c.d", true},
+		{"7", true},
+		{"café", true},
+		{"abcdefghijklmnopqrstuvwx", true},
+		{"", false},
+		{"abcdefghijklmnopqrstuvwxy", false},
+		{" leading space", false},
+		{"-dash", false},
+		{"semi;colon", false},
+		{"slash/room", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRoomName(tt.name)
+			if (err == nil) != tt.ok {
+				t.Errorf("validateRoomName(%q) = %v, want ok %v", tt.name, err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestClaimSeat(t *testing.T) {
+	t.Run("a spectator takes the seat of a player who left before the first move", func(t *testing.T) {
+		r := NewRoom("open", DefaultRoomSettings(), newTestStore(t), nil)
+		r.Join("s1", "ann", discardProgram(), false)
+		r.Join("s2", "bob", discardProgram(), false)
+		r.Join("s3", "cat", discardProgram(), false)
+		r.Leave("s2")
+
+		role, ok := r.ClaimSeat("s3")
+		if !ok || role != RolePlayerO {
+			t.Fatalf("got %v, %v, want O", role, ok)
+		}
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+		if !r.started {
+			t.Error("taking the last seat did not start the game")
+		}
+	})
+	t.Run("a seat left mid-game stays closed", func(t *testing.T) {
+		r := NewRoom("busy", DefaultRoomSettings(), newTestStore(t), nil)
+		r.Join("s1", "ann", discardProgram(), false)
+		r.Join("s2", "bob", discardProgram(), false)
+		r.Join("s3", "cat", discardProgram(), false)
+		if !r.HandleMove("s1", 4) {
+			t.Fatal("X could not move")
+		}
+		r.Leave("s2")
+
+		if _, ok := r.ClaimSeat("s3"); ok {
+			t.Error("a spectator took a seat in a game under way")
+		}
+	})
+}
+
+func TestDropKeysHelp(t *testing.T) {
+	tests := []struct {
+		cols int
+		want string
+	}{
+		{7, "1-7: drop  ←/→: column"},
+		{10, "1-9,0: drop  ←/→: column"},
+		{15, "1-9,0: drop  ←/→: column (11-15)"},
+	}
+	for _, tt := range tests {
+		if got := dropKeysHelp(tt.cols); got != tt.want {
+			t.Errorf("dropKeysHelp(%d) = %q, want %q", tt.cols, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jwc20/ssh-ttt/variant"
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Ultimate Board (room model)
+// ─────────────────────────────────────────────────────────────────────────────
+
+// The ultimate cursor moves over a 9×9 grid of cells; row/3 and col/3 pick
+// the sub-board, row%3 and col%3 the cell inside it.
+
+var (
+	subBoardX        = cellDefault.Background(lipgloss.Color("52")).Foreground(lipgloss.Color("1")).Bold(true)
+	subBoardO        = cellDefault.Background(lipgloss.Color("17")).Foreground(lipgloss.Color("4")).Bold(true)
+	subBoardDrawn    = cellDefault.Foreground(lipgloss.Color("241"))
+	subBoardPlayable = cellDefault.Background(lipgloss.Color("236"))
+
+	ultimateRule = strings.Repeat("━", 9) + "╋" + strings.Repeat("━", 9) + "╋" + strings.Repeat("━", 9)
+)
+
+// followForcedBoard moves the cursor into the sub-board the next move must
+// be played in, keeping its place within the sub-board.
+func (m *roomModel) followForcedBoard() {
+	u, ok := m.board.(*variant.Ultimate)
+	if !ok || u.Next() < 0 {
+		return
+	}
+	next := u.Next()
+	m.cursorRow = (next/3)*3 + m.cursorRow%3
+	m.cursorCol = (next%3)*3 + m.cursorCol%3
+}
+
+func (m roomModel) viewUltimate(u *variant.Ultimate) string {
+	var b strings.Builder
+	for row := range 9 {
+		if row > 0 && row%3 == 0 {
+			b.WriteString("  " + ultimateRule + "\n")
+		}
+		var boards []string
+		for bc := range 3 {
+			var rendered []string
+			for cc := range 3 {
+				board := (row/3)*3 + bc
+				idx := board*9 + (row%3)*3 + cc
+				rendered = append(rendered, m.ultimateCell(u, board, idx, row, bc*3+cc))
+			}
+			boards = append(boards, strings.Join(rendered, ""))
+		}
+		b.WriteString("  " + strings.Join(boards, "┃") + "\n")
+	}
+	return b.String()
+}
+
+// ultimateCell shades decided sub-boards in their winner's colour and the
+// sub-boards the side to move may play in.
+func (m roomModel) ultimateCell(u *variant.Ultimate, board, idx, row, col int) string {
+	mark := u.Cells()[idx]
+	display := renderMark(mark)
+	if mark == variant.Empty {
+		display = "·"
+	}
+	if row == m.cursorRow && col == m.cursorCol && m.focus == paneGame {
+		return cellHighlight.Render(display)
+	}
+	if idx == m.hint {
+		return cellHint.Render(display)
+	}
+	switch u.BoardWinner(board) {
+	case 'X':
+		return subBoardX.Render(string(mark))
+	case 'O':
+		return subBoardO.Render(string(mark))
+	case variant.Drawn:
+		return subBoardDrawn.Render(string(mark))
+	}
+	if m.gameStarted && u.Playable(board) {
+		return subBoardPlayable.Render(display)
+	}
+	return cellDefault.Render(display)
+}
+
+// ultimateStatus names the sub-board the side to move must play in.
+func ultimateStatus(u *variant.Ultimate) string {
+	if u.Next() < 0 {
+		return "Board: any"
+	}
+	return "Board: " + squareNames[u.Next()]
+}
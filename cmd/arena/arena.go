@@ -0,0 +1,370 @@
+// Command arena plays engines against each other and reports how they did.
+//
+//	arena -a alphabeta -b mcts:2000 -variant ultimate -games 200
+//
+// The two engines take turns playing X. Every game's random choices come
+// from the seed, so a run can be repeated exactly with the seed it prints.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	ttt "github.com/jwc20/ssh-ttt"
+	"github.com/jwc20/ssh-ttt/engine"
+	"github.com/jwc20/ssh-ttt/variant"
+)
+
+const engineHelp = `engines:
+  random            a random legal move
+  alphabeta[:N]     alpha-beta search, N plies deep (default 6, 50000 nodes)
+  mcts[:N]          Monte Carlo tree search, N playouts a move (default 10000)
+  tablebase         perfect play from the solved 3×3 tablebase; classic only
+  easy|medium|hard  the SSH server's computer players
+`
+
+func main() {
+	a := flag.String("a", "alphabeta", "first engine")
+	b := flag.String("b", "random", "second engine")
+	variantName := flag.String("variant", "classic", "variant to play: "+strings.Join(variant.Names(), ", "))
+	board := flag.String("board", "", `board for variants that take one, e.g. "7x6, 4 in a row, gravity"`)
+	games := flag.Int("games", 100, "number of games")
+	parallel := flag.Int("parallel", runtime.NumCPU(), "games played at once")
+	seed := flag.Uint64("seed", 0, "seed for the engines' random choices; 0 picks one")
+	asJSON := flag.Bool("json", false, "print the results as JSON")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags]\n\n", os.Args[0])
+		flag.PrintDefaults()
+		fmt.Fprint(flag.CommandLine.Output(), "\n"+engineHelp)
+	}
+	flag.Parse()
+
+	var opts variant.Options
+	if *board != "" {
+		var err error
+		if opts, err = variant.ParseOptions(strings.ReplaceAll(*board, "x", "×")); err != nil {
+			log.Fatalf("-board: %v", err)
+		}
+	}
+	start, err := variant.NewWith(*variantName, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, spec := range []string{*a, *b} {
+		if _, err := newPlayer(spec, start, nil); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *games < 1 || *parallel < 1 {
+		log.Fatal("-games and -parallel must be at least 1")
+	}
+	if *seed == 0 {
+		*seed = rand.Uint64()
+	}
+
+	ar := arena{specs: [2]string{*a, *b}, variant: *variantName, start: start, seed: *seed}
+	rep := newReport(ar, opts, ar.run(*games, *parallel))
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(rep); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	rep.print(os.Stdout)
+}
+
+// player is an engine with the counters the report needs.
+type player struct {
+	engine engine.Engine
+	// nodes is how many positions or playouts the last move took; ok is
+	// false for moves that weren't searched, e.g. random or looked up.
+	nodes func() (n int, ok bool)
+}
+
+// newPlayer builds the engine spec names for games starting from start.
+// rng is the source of the engine's random choices; nil is allowed when
+// only checking the spec.
+func newPlayer(spec string, start variant.State, rng *rand.Rand) (player, error) {
+	name, arg, hasArg := strings.Cut(spec, ":")
+	n := 0
+	if hasArg {
+		var err error
+		if n, err = strconv.Atoi(arg); err != nil || n < 1 || (name != "alphabeta" && name != "mcts") {
+			return player{}, fmt.Errorf("bad engine %q\n\n%s", spec, engineHelp)
+		}
+	}
+	uncounted := func() (int, bool) { return 0, false }
+
+	switch name {
+	case "random":
+		return player{engine: engine.Random{Rand: rng}, nodes: uncounted}, nil
+	case "alphabeta":
+		search := &engine.AlphaBeta{MaxDepth: 6, MaxNodes: 50_000}
+		if hasArg {
+			search = &engine.AlphaBeta{MaxDepth: n}
+		}
+		return player{engine: search, nodes: func() (int, bool) { return search.Nodes, true }}, nil
+	case "mcts":
+		search := &engine.MCTS{Iterations: engine.DefaultIterations, Workers: 1, Seed: 1}
+		if hasArg {
+			search.Iterations = n
+		}
+		if rng != nil {
+			search.Seed = rng.Uint64() | 1
+		}
+		return player{engine: search, nodes: func() (int, bool) { return search.Playouts, true }}, nil
+	case "tablebase":
+		if _, ok := start.(*variant.Classic); !ok {
+			return player{}, fmt.Errorf("tablebase only plays classic")
+		}
+		return player{engine: tablebase{}, nodes: uncounted}, nil
+	}
+
+	rate, ok := engine.BlunderRate(name)
+	if !ok {
+		return player{}, fmt.Errorf("%w %q\n\n%s", engine.ErrUnknownEngine, spec, engineHelp)
+	}
+	best, err := newPlayer("alphabeta", start, rng)
+	if _, classic := start.(*variant.Classic); classic {
+		best, err = newPlayer("tablebase", start, rng)
+	}
+	if err != nil {
+		return player{}, err
+	}
+	l := &level{best: best, rate: rate, rng: rng}
+	return player{engine: l, nodes: func() (int, bool) { return l.nodes, l.searched }}, nil
+}
+
+// tablebase plays classic perfectly by looking each position up, as the
+// library's Position does.
+type tablebase struct{}
+
+func (tablebase) Move(s variant.State) int {
+	return ttt.Position{
+		Turn:  strings.ToLower(string(s.Turn())),
+		Board: strings.ToLower(string(s.Cells())),
+	}.BestMove()
+}
+
+// level plays like a computer player: its best engine's move, or at its
+// blunder rate a random one.
+type level struct {
+	best     player
+	rate     float64
+	rng      *rand.Rand
+	nodes    int
+	searched bool
+}
+
+func (l *level) Move(s variant.State) int {
+	if l.rng.Float64() < l.rate {
+		l.nodes, l.searched = 0, false
+		return engine.Random{Rand: l.rng}.Move(s)
+	}
+	move := l.best.engine.Move(s)
+	l.nodes, l.searched = l.best.nodes()
+	return move
+}
+
+// arena plays games between two engine specs.
+type arena struct {
+	specs   [2]string
+	variant string
+	start   variant.State
+	seed    uint64
+}
+
+// gameResult is one game. First is which spec played X; winner is 0 or 1
+// for the spec that won and -1 for a draw.
+type gameResult struct {
+	first, winner int
+	moves         [2]int
+	time          [2]time.Duration
+	// nodes totals what each side's searched moves took; searched counts
+	// those moves.
+	nodes    [2]int64
+	searched [2]int
+	err      error
+}
+
+// run plays the games, parallel at a time. Results are in game order, so
+// they don't depend on which game finished first.
+func (a arena) run(games, parallel int) []gameResult {
+	results := make([]gameResult, games)
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(parallel, games) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = a.play(i)
+			}
+		}()
+	}
+	for i := range games {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
+
+// play plays game i. The specs swap sides every game and each engine has
+// its own random source drawn from the seed and the game number.
+func (a arena) play(i int) gameResult {
+	r := gameResult{first: i % 2, winner: -1}
+	var players [2]player
+	for p, spec := range a.specs {
+		rng := rand.New(rand.NewPCG(a.seed, uint64(2*i+p)))
+		players[p], r.err = newPlayer(spec, a.start, rng)
+		if r.err != nil {
+			return r
+		}
+	}
+
+	s := a.start.Clone()
+	for !s.Over() {
+		p := r.first
+		if s.Turn() != 'X' {
+			p = 1 - r.first
+		}
+		begin := time.Now()
+		move := players[p].engine.Move(s)
+		r.time[p] += time.Since(begin)
+		if n, ok := players[p].nodes(); ok {
+			r.nodes[p] += int64(n)
+			r.searched[p]++
+		}
+		r.moves[p]++
+		if err := s.Play(move); err != nil {
+			r.err = fmt.Errorf("game %d: %s played %d: %w", i+1, a.specs[p], move, err)
+			return r
+		}
+	}
+	switch s.Winner() {
+	case 'X':
+		r.winner = r.first
+	case 'O':
+		r.winner = 1 - r.first
+	}
+	return r
+}
+
+// report is what a run comes to, from each engine's point of view.
+type report struct {
+	Variant string         `json:"variant"`
+	Board   string         `json:"board,omitempty"`
+	Games   int            `json:"games"`
+	Seed    uint64         `json:"seed"`
+	Engines []engineReport `json:"engines"`
+	Errors  []string       `json:"errors,omitempty"`
+}
+
+type engineReport struct {
+	Engine string `json:"engine"`
+	Wins   int    `json:"wins"`
+	Draws  int    `json:"draws"`
+	Losses int    `json:"losses"`
+	// Score counts a win as 1 and a draw as a half, over the games.
+	Score      float64 `json:"score"`
+	WinsAsX    int     `json:"wins_as_x"`
+	WinsAsO    int     `json:"wins_as_o"`
+	Moves      int     `json:"moves"`
+	MoveTimeUS float64 `json:"avg_move_us"`
+	// Nodes and NodesMove only cover searched moves; NodesMove is null
+	// when the engine searched none.
+	Nodes     int64    `json:"nodes"`
+	NodesMove *float64 `json:"avg_nodes"`
+}
+
+func newReport(a arena, opts variant.Options, results []gameResult) report {
+	rep := report{Variant: a.variant, Games: len(results), Seed: a.seed}
+	if opts != (variant.Options{}) {
+		rep.Board = opts.String()
+	}
+	var engines [2]engineReport
+	var elapsed [2]time.Duration
+	var searched [2]int
+	for p, spec := range a.specs {
+		engines[p].Engine = spec
+	}
+	for _, r := range results {
+		if r.err != nil {
+			rep.Errors = append(rep.Errors, r.err.Error())
+			continue
+		}
+		for p := range engines {
+			e := &engines[p]
+			switch r.winner {
+			case -1:
+				e.Draws++
+			case p:
+				e.Wins++
+				if r.first == p {
+					e.WinsAsX++
+				} else {
+					e.WinsAsO++
+				}
+			default:
+				e.Losses++
+			}
+			e.Moves += r.moves[p]
+			e.Nodes += r.nodes[p]
+			searched[p] += r.searched[p]
+			elapsed[p] += r.time[p]
+		}
+	}
+	for p := range engines {
+		e := &engines[p]
+		if played := e.Wins + e.Draws + e.Losses; played > 0 {
+			e.Score = (float64(e.Wins) + float64(e.Draws)/2) / float64(played)
+		}
+		if e.Moves > 0 {
+			e.MoveTimeUS = float64(elapsed[p].Microseconds()) / float64(e.Moves)
+		}
+		if searched[p] > 0 {
+			avg := float64(e.Nodes) / float64(searched[p])
+			e.NodesMove = &avg
+		}
+	}
+	rep.Engines = engines[:]
+	return rep
+}
+
+func (rep report) print(w io.Writer) {
+	title := rep.Variant
+	if rep.Board != "" {
+		title += " (" + rep.Board + ")"
+	}
+	fmt.Fprintf(w, "%s, %d games, seed %d\n\n", title, rep.Games, rep.Seed)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "engine\twins\tdraws\tlosses\tscore\tas X\tas O\tavg move\tnodes/move\t")
+	for _, e := range rep.Engines {
+		move := time.Duration(e.MoveTimeUS * float64(time.Microsecond)).Round(time.Microsecond)
+		nodes := "n/a"
+		if e.NodesMove != nil {
+			nodes = fmt.Sprintf("%.0f", *e.NodesMove)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%.1f%%\t%d\t%d\t%s\t%s\t\n",
+			e.Engine, e.Wins, e.Draws, e.Losses, e.Score*100, e.WinsAsX, e.WinsAsO, move, nodes)
+	}
+	tw.Flush()
+	for _, err := range rep.Errors {
+		fmt.Fprintln(w, "error:", err)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jwc20/ssh-ttt/variant"
+)
+
+func TestArenaSeed(t *testing.T) {
+	tests := []struct {
+		name    string
+		variant string
+		specs   [2]string
+	}{
+		{"computer players on classic", "classic", [2]string{"hard", "medium"}},
+		{"searches on ultimate", "ultimate", [2]string{"mcts:50", "alphabeta:2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, err := variant.New(tt.variant)
+			if err != nil {
+				t.Fatal(err)
+			}
+			run := func(seed uint64, parallel int) report {
+				a := arena{specs: tt.specs, variant: tt.variant, start: start, seed: seed}
+				rep := newReport(a, variant.Options{}, a.run(6, parallel))
+				for i := range rep.Engines {
+					rep.Engines[i].MoveTimeUS = 0
+				}
+				return rep
+			}
+
+			first := run(42, 1)
+			if len(first.Errors) > 0 {
+				t.Fatalf("games failed: %v", first.Errors)
+			}
+			if again := run(42, 3); !reflect.DeepEqual(first, again) {
+				t.Errorf("the same seed gave\n%+v\nthen\n%+v", first, again)
+			}
+		})
+	}
+}
+
+func TestArenaNodes(t *testing.T) {
+	tests := []struct {
+		spec    string
+		counted bool
+	}{
+		{"random", false},
+		{"tablebase", false},
+		{"alphabeta:2", true},
+		{"mcts:20", true},
+	}
+	start, err := variant.New("classic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			a := arena{specs: [2]string{tt.spec, "random"}, variant: "classic", start: start, seed: 7}
+			rep := newReport(a, variant.Options{}, a.run(2, 1))
+			if len(rep.Errors) > 0 {
+				t.Fatalf("games failed: %v", rep.Errors)
+			}
+			if got := rep.Engines[0].NodesMove != nil; got != tt.counted {
+				t.Errorf("got nodes counted %v, want %v", got, tt.counted)
+			}
+			if rep.Engines[1].NodesMove != nil {
+				t.Error("random moves were counted as searched")
+			}
+		})
+	}
+
+	t.Run("the tablebase only plays classic", func(t *testing.T) {
+		ultimate, err := variant.New("ultimate")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := newPlayer("tablebase", ultimate, nil); err == nil {
+			t.Error("got a tablebase player for ultimate")
+		}
+	})
+}
@@ -1,16 +1,51 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	ttt "github.com/jwc20/ssh-ttt"
+	"github.com/jwc20/ssh-ttt/engine"
 )
 
 const dbFileName = "game.db.json"
 
 func main() {
+	variant := flag.String("variant", "classic", "game to play: classic, qubic3 (3×3×3) or qubic4 (4×4×4)")
+	computer := flag.String("computer", "", "engine to play O against: "+strings.Join(engine.Names(), ", ")+"; empty for two players")
+	load := flag.String("load", "", "game notation file to carry on from; its Variant tag picks the variant")
+	save := flag.String("save", "", "file to write the finished game to in game notation")
+	position := flag.String("position", "", `classic position to start from, e.g. "x1o/1x1/3 o"`)
+	flag.Parse()
+
+	var record *ttt.GameRecord
+	if *load != "" {
+		text, err := os.ReadFile(*load)
+		if err != nil {
+			log.Fatal(err)
+		}
+		record = &ttt.GameRecord{}
+		if err := record.UnmarshalText(text); err != nil {
+			log.Fatalf("%s: %v", *load, err)
+		}
+		*variant = record.Variant
+	}
+	if *position != "" {
+		if record != nil {
+			log.Fatal("-position and -load can't be used together")
+		}
+		p, err := ttt.ParsePosition(*position)
+		if err != nil {
+			log.Fatalf("-position: %v", err)
+		}
+		record = &ttt.GameRecord{Variant: "classic", Setup: &p}
+		*variant = record.Variant
+	}
+
 	store, close, err := ttt.FileSystemPlayerStoreFromFile(dbFileName)
 
 	if err != nil {
@@ -18,9 +53,50 @@ func main() {
 	}
 	defer close()
 
+	var game ttt.TicTacToeGame
+	switch *variant {
+	case "classic":
+		game = ttt.NewTicTacToe(store)
+	case "qubic3":
+		game = ttt.NewQubic(store, 3)
+	case "qubic4":
+		game = ttt.NewQubic(store, 4)
+	default:
+		log.Fatalf("unknown variant %q", *variant)
+	}
+
 	fmt.Println("Let's play Tic-Tac-Toe!")
 
-	game := ttt.NewTicTacToe(store)
 	cli := ttt.NewCLI(os.Stdin, os.Stdout, game)
+	if *computer != "" {
+		e, err := engine.New(*computer)
+		if err != nil {
+			log.Fatal(err)
+		}
+		cli.SetComputer("O", e)
+	}
+	if record != nil {
+		if err := cli.Load(*record); err != nil {
+			log.Fatal(err)
+		}
+	}
 	cli.PlayGame()
+
+	if *save != "" {
+		saved, ok := cli.Record()
+		if !ok {
+			log.Fatalf("can't save a %s game", *variant)
+		}
+		saved.Date = time.Now().Format(ttt.DateFormat)
+		if record != nil {
+			saved.PlayerX, saved.PlayerO, saved.Tags = record.PlayerX, record.PlayerO, record.Tags
+		}
+		text, err := saved.MarshalText()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(*save, text, 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
 }
@@ -1,33 +1,58 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"log"
+	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	ttt "github.com/jwc20/ssh-ttt"
+	"github.com/jwc20/ssh-ttt/config"
 	"github.com/jwc20/ssh-ttt/handlers"
+	"github.com/jwc20/ssh-ttt/metrics"
 	_ "github.com/mattn/go-sqlite3"
 )
 
-const dbFileName = "/.app.db"
-
 func main() {
-	store, close, err := ttt.FileSystemTTTStoreFromFile(dbFileName)
+	cfg, opts, err := config.Load("webserver", os.Args[1:], os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+	if opts.PrintConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	store, close, err := ttt.FileSystemTTTStoreFromFile(cfg.HTTP.DBPath)
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer close()
 
-	db := store.Database
-
 	router := gin.Default()
-	router.GET("/users", handlers.ListUser(db))
-	router.POST("/users", handlers.CreateUser(db))
+	router.Use(instrument)
+	router.GET("/users", handlers.ListUser(store))
+	router.POST("/users", handlers.CreateUser(store))
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
 
-	port := ":8080"
-	log.Printf("Server is running on http://localhost%s", port)
+	log.Printf("Server is running on %s", cfg.HTTP.Addr)
 
-	if err := router.Run(port); err != nil {
+	if err := router.Run(cfg.HTTP.Addr); err != nil {
 		log.Fatalf("Error starting server: %s", err)
 	}
 }
+
+// instrument counts and times every request under the route it matched,
+// with the same metrics as the SSH server's HTTP API.
+func instrument(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+	metrics.ObserveRequest(c.Request.Method, c.FullPath(), c.Writer.Status(), start)
+}
@@ -0,0 +1,351 @@
+// Package config loads server settings for the ssh-ttt binaries from
+// defaults, an optional JSON file, TTT_* environment variables and command
+// line flags, in increasing order of precedence.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duration is a time.Duration that reads and writes as "30s" in JSON.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"30s\": %v", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d Duration) D() time.Duration { return time.Duration(d) }
+
+type SSH struct {
+	Host            string   `json:"host"`
+	Port            int      `json:"port"`
+	HostKeyPath     string   `json:"host_key_path"`
+	AdminKeysPath   string   `json:"admin_keys_path"`
+	ShutdownTimeout Duration `json:"shutdown_timeout"`
+}
+
+type HTTP struct {
+	Addr   string `json:"addr"`
+	DBPath string `json:"db_path"`
+}
+
+type Database struct {
+	Path string `json:"path"`
+}
+
+type Rooms struct {
+	MaxRooms        int      `json:"max_rooms"`
+	MaxPerUser      int      `json:"max_per_user"`
+	CleanupInterval Duration `json:"cleanup_interval"`
+	RestoreGrace    Duration `json:"restore_grace"`
+	ArchiveAfter    Duration `json:"archive_after"`
+}
+
+type Limits struct {
+	MaxSessions        int      `json:"max_sessions"`
+	MaxSessionsPerIP   int      `json:"max_sessions_per_ip"`
+	MaxSessionsPerUser int      `json:"max_sessions_per_user"`
+	IdleTimeout        Duration `json:"idle_timeout"`
+	ActionBurst        int      `json:"action_burst"`
+	ActionInterval     Duration `json:"action_interval"`
+}
+
+type Chat struct {
+	WordFilterPath string `json:"word_filter_path"`
+}
+
+type Events struct {
+	Path       string `json:"path"`
+	MaxSizeMB  int    `json:"max_size_mb"`
+	MaxBackups int    `json:"max_backups"`
+}
+
+type Metrics struct {
+	Addr string `json:"addr"`
+}
+
+// API is where the SSH server serves its JSON API: tournaments, player
+// stats, variants, game replays and notation, and positions.
+type API struct {
+	Addr string `json:"addr"`
+}
+
+type Features struct {
+	Leaderboard    bool `json:"leaderboard"`
+	LobbyChat      bool `json:"lobby_chat"`
+	DirectMessages bool `json:"direct_messages"`
+	Challenges     bool `json:"challenges"`
+	Tournaments    bool `json:"tournaments"`
+}
+
+type Config struct {
+	SSH      SSH      `json:"ssh"`
+	HTTP     HTTP     `json:"http"`
+	Database Database `json:"database"`
+	Rooms    Rooms    `json:"rooms"`
+	Limits   Limits   `json:"limits"`
+	Chat     Chat     `json:"chat"`
+	Events   Events   `json:"events"`
+	Metrics  Metrics  `json:"metrics"`
+	API      API      `json:"api"`
+	Features Features `json:"features"`
+}
+
+// Default returns the settings the servers used before they were
+// configurable.
+func Default() Config {
+	return Config{
+		SSH: SSH{
+			Host:            "localhost",
+			Port:            2222,
+			HostKeyPath:     ".ssh/id_ed25519",
+			AdminKeysPath:   ".ssh/admin_keys",
+			ShutdownTimeout: Duration(30 * time.Second),
+		},
+		HTTP: HTTP{
+			Addr:   ":8080",
+			DBPath: "app.db",
+		},
+		Database: Database{Path: "tictactoe.db"},
+		Rooms: Rooms{
+			MaxRooms:        100,
+			MaxPerUser:      3,
+			CleanupInterval: Duration(30 * time.Second),
+			RestoreGrace:    Duration(10 * time.Minute),
+			ArchiveAfter:    Duration(5 * time.Minute),
+		},
+		Limits: Limits{
+			MaxSessions:        200,
+			MaxSessionsPerIP:   10,
+			MaxSessionsPerUser: 3,
+			IdleTimeout:        Duration(30 * time.Minute),
+			ActionBurst:        20,
+			ActionInterval:     Duration(50 * time.Millisecond),
+		},
+		Chat:    Chat{WordFilterPath: "wordfilter.txt"},
+		Events:  Events{MaxSizeMB: 10, MaxBackups: 5},
+		Metrics: Metrics{Addr: "localhost:2112"},
+		API:     API{Addr: "localhost:2113"},
+		Features: Features{
+			Leaderboard:    true,
+			LobbyChat:      true,
+			DirectMessages: true,
+			Challenges:     true,
+			Tournaments:    true,
+		},
+	}
+}
+
+// binding ties one setting to its flag and environment variable. The key
+// "ssh.port" becomes the flag -ssh-port and the variable TTT_SSH_PORT.
+type binding struct {
+	key   string
+	usage string
+	ptr   any
+}
+
+func (c *Config) bindings() []binding {
+	return []binding{
+		{"ssh.host", "SSH listen host", &c.SSH.Host},
+		{"ssh.port", "SSH listen port", &c.SSH.Port},
+		{"ssh.host_key_path", "SSH host key file", &c.SSH.HostKeyPath},
+		{"ssh.admin_keys_path", "authorized_keys file of admin public keys", &c.SSH.AdminKeysPath},
+		{"ssh.shutdown_timeout", "graceful shutdown timeout", &c.SSH.ShutdownTimeout},
+		{"http.addr", "HTTP listen address", &c.HTTP.Addr},
+		{"http.db_path", "HTTP server SQLite database path", &c.HTTP.DBPath},
+		{"database.path", "SSH server SQLite database path", &c.Database.Path},
+		{"rooms.max_rooms", "maximum number of open rooms", &c.Rooms.MaxRooms},
+		{"rooms.max_per_user", "maximum rooms one user may have open, 0 for no limit", &c.Rooms.MaxPerUser},
+		{"rooms.cleanup_interval", "how often empty rooms are removed", &c.Rooms.CleanupInterval},
+		{"rooms.restore_grace", "how long restored rooms wait for their players", &c.Rooms.RestoreGrace},
+		{"rooms.archive_after", "close rooms this long after their game ends, 0 to keep them", &c.Rooms.ArchiveAfter},
+		{"limits.max_sessions", "maximum concurrent SSH sessions, 0 for no limit", &c.Limits.MaxSessions},
+		{"limits.max_sessions_per_ip", "maximum concurrent sessions per remote address, 0 for no limit", &c.Limits.MaxSessionsPerIP},
+		{"limits.max_sessions_per_user", "maximum concurrent sessions per user name, 0 for no limit", &c.Limits.MaxSessionsPerUser},
+		{"limits.idle_timeout", "disconnect sessions idle for this long, 0 to never", &c.Limits.IdleTimeout},
+		{"limits.action_burst", "keypresses a session may send at once", &c.Limits.ActionBurst},
+		{"limits.action_interval", "time to regain one keypress of burst", &c.Limits.ActionInterval},
+		{"chat.word_filter_path", "file of filtered chat words, one per line", &c.Chat.WordFilterPath},
+		{"events.path", "JSON-lines game event log, empty to log to stderr only", &c.Events.Path},
+		{"events.max_size_mb", "rotate the event log after this many megabytes", &c.Events.MaxSizeMB},
+		{"events.max_backups", "number of rotated event logs to keep", &c.Events.MaxBackups},
+		{"metrics.addr", "SSH server HTTP address for /metrics, empty to disable", &c.Metrics.Addr},
+		{"api.addr", "SSH server HTTP address for the JSON API under /api, empty to disable; may equal metrics.addr", &c.API.Addr},
+		{"features.leaderboard", "enable the lobby leaderboard", &c.Features.Leaderboard},
+		{"features.lobby_chat", "enable lobby chat", &c.Features.LobbyChat},
+		{"features.direct_messages", "enable direct messages", &c.Features.DirectMessages},
+		{"features.challenges", "enable player challenges", &c.Features.Challenges},
+		{"features.tournaments", "enable tournaments", &c.Features.Tournaments},
+	}
+}
+
+func (b binding) flagName() string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(b.key)
+}
+
+func (b binding) envName() string {
+	return "TTT_" + strings.ToUpper(strings.ReplaceAll(b.key, ".", "_"))
+}
+
+func (b binding) set(raw string) error {
+	switch p := b.ptr.(type) {
+	case *string:
+		*p = raw
+	case *int:
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not a number", b.key, raw)
+		}
+		*p = v
+	case *bool:
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not a boolean", b.key, raw)
+		}
+		*p = v
+	case *Duration:
+		v, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not a duration", b.key, raw)
+		}
+		*p = Duration(v)
+	default:
+		return fmt.Errorf("%s: unsupported setting type %T", b.key, b.ptr)
+	}
+	return nil
+}
+
+// Options are the command line switches that are not settings themselves.
+type Options struct {
+	Path        string
+	PrintConfig bool
+}
+
+// Load builds the configuration for a binary from args (without the program
+// name) and the environment, read through lookupEnv (os.LookupEnv outside
+// tests). A variable that is set but empty still counts, so TTT_METRICS_ADDR=
+// turns the metrics server off.
+func Load(name string, args []string, lookupEnv func(string) (string, bool)) (Config, Options, error) {
+	var opts Options
+	cfg := Default()
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	path, _ := lookupEnv("TTT_CONFIG")
+	fs.StringVar(&opts.Path, "config", path, "path to a JSON config file")
+	fs.BoolVar(&opts.PrintConfig, "print-config", false, "print the effective configuration and exit")
+
+	// Flags are only recorded here and applied after the file and the
+	// environment so they always win.
+	flagValues := map[string]string{}
+	for _, b := range cfg.bindings() {
+		key := b.key
+		fs.Func(b.flagName(), b.usage, func(v string) error {
+			flagValues[key] = v
+			return nil
+		})
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return cfg, opts, err
+	}
+
+	if opts.Path != "" {
+		if err := cfg.loadFile(opts.Path); err != nil {
+			return cfg, opts, err
+		}
+	}
+
+	for _, b := range cfg.bindings() {
+		if v, ok := lookupEnv(b.envName()); ok {
+			if err := b.set(v); err != nil {
+				return cfg, opts, fmt.Errorf("%s: %v", b.envName(), err)
+			}
+		}
+	}
+
+	for _, b := range cfg.bindings() {
+		if v, ok := flagValues[b.key]; ok {
+			if err := b.set(v); err != nil {
+				return cfg, opts, fmt.Errorf("-%s: %v", b.flagName(), err)
+			}
+		}
+	}
+
+	return cfg, opts, cfg.Validate()
+}
+
+func (c *Config) loadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("problem opening config %s, %v", path, err)
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(c); err != nil {
+		return fmt.Errorf("problem parsing config %s, %v", path, err)
+	}
+	return nil
+}
+
+// Validate reports every invalid setting at once.
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.SSH.Port > 0 && c.SSH.Port < 65536, "ssh.port must be between 1 and 65535, got %d", c.SSH.Port)
+	check(c.SSH.HostKeyPath != "", "ssh.host_key_path must be set")
+	check(c.SSH.ShutdownTimeout > 0, "ssh.shutdown_timeout must be positive")
+	check(c.HTTP.Addr != "", "http.addr must be set")
+	check(c.HTTP.DBPath != "", "http.db_path must be set")
+	check(c.Database.Path != "", "database.path must be set")
+	check(c.Rooms.MaxRooms > 0, "rooms.max_rooms must be positive, got %d", c.Rooms.MaxRooms)
+	check(c.Rooms.CleanupInterval > 0, "rooms.cleanup_interval must be positive")
+	check(c.Rooms.MaxPerUser >= 0, "rooms.max_per_user must not be negative")
+	check(c.Rooms.RestoreGrace >= 0, "rooms.restore_grace must not be negative")
+	check(c.Rooms.ArchiveAfter >= 0, "rooms.archive_after must not be negative")
+	check(c.Limits.MaxSessions >= 0, "limits.max_sessions must not be negative")
+	check(c.Limits.MaxSessionsPerIP >= 0, "limits.max_sessions_per_ip must not be negative")
+	check(c.Limits.MaxSessionsPerUser >= 0, "limits.max_sessions_per_user must not be negative")
+	check(c.Limits.IdleTimeout >= 0, "limits.idle_timeout must not be negative")
+	check(c.Limits.ActionBurst > 0, "limits.action_burst must be positive, got %d", c.Limits.ActionBurst)
+	check(c.Limits.ActionInterval >= 0, "limits.action_interval must not be negative")
+	check(c.Events.MaxSizeMB > 0, "events.max_size_mb must be positive, got %d", c.Events.MaxSizeMB)
+	check(c.Events.MaxBackups >= 0, "events.max_backups must not be negative")
+
+	return errors.Join(errs...)
+}
+
+// Print writes the configuration as indented JSON, ready to be used as a
+// config file.
+func (c Config) Print(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c)
+}
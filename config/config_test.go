@@ -0,0 +1,168 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	noEnv := func(string) (string, bool) { return "", false }
+
+	t.Run("defaults", func(t *testing.T) {
+		got, _, err := Load("test", nil, noEnv)
+		assertNoError(t, err)
+		if got != Default() {
+			t.Errorf("got %+v, want defaults %+v", got, Default())
+		}
+	})
+
+	t.Run("flags beat env beat file", func(t *testing.T) {
+		path := writeConfig(t, `{"ssh": {"port": 3000, "host": "0.0.0.0"}, "rooms": {"max_rooms": 5}}`)
+		env := map[string]string{
+			"TTT_CONFIG":          path,
+			"TTT_SSH_PORT":        "4000",
+			"TTT_ROOMS_MAX_ROOMS": "7",
+		}
+
+		got, opts, err := Load("test", []string{"-ssh-port", "5000"}, envOf(env))
+		assertNoError(t, err)
+
+		if opts.Path != path {
+			t.Errorf("config path %q, want %q", opts.Path, path)
+		}
+		if got.SSH.Port != 5000 {
+			t.Errorf("ssh.port %d, want 5000 from the flag", got.SSH.Port)
+		}
+		if got.Rooms.MaxRooms != 7 {
+			t.Errorf("rooms.max_rooms %d, want 7 from the environment", got.Rooms.MaxRooms)
+		}
+		if got.SSH.Host != "0.0.0.0" {
+			t.Errorf("ssh.host %q, want 0.0.0.0 from the file", got.SSH.Host)
+		}
+	})
+
+	t.Run("durations and toggles", func(t *testing.T) {
+		path := writeConfig(t, `{"rooms": {"cleanup_interval": "1m"}, "features": {"lobby_chat": false}}`)
+
+		got, _, err := Load("test", []string{"--config", path, "--features-challenges=false"}, noEnv)
+		assertNoError(t, err)
+
+		if got.Rooms.CleanupInterval.D() != time.Minute {
+			t.Errorf("rooms.cleanup_interval %v, want 1m", got.Rooms.CleanupInterval.D())
+		}
+		if got.Features.LobbyChat || got.Features.Challenges {
+			t.Errorf("features %+v, want lobby chat and challenges off", got.Features)
+		}
+		if !got.Features.Leaderboard {
+			t.Error("leaderboard should stay enabled")
+		}
+	})
+
+	t.Run("limits", func(t *testing.T) {
+		got, _, err := Load("test", []string{"-limits-max-sessions-per-ip", "2", "-limits-idle-timeout", "0s"}, noEnv)
+		assertNoError(t, err)
+
+		if got.Limits.MaxSessionsPerIP != 2 || got.Limits.IdleTimeout != 0 {
+			t.Errorf("limits %+v, want 2 sessions per IP and no idle timeout", got.Limits)
+		}
+
+		_, _, err = Load("test", []string{"-limits-action-burst", "0"}, noEnv)
+		if err == nil || !strings.Contains(err.Error(), "limits.action_burst") {
+			t.Errorf("got %v, want an error about limits.action_burst", err)
+		}
+	})
+
+	t.Run("unknown keys in the file are rejected", func(t *testing.T) {
+		path := writeConfig(t, `{"ssh": {"prot": 22}}`)
+
+		_, _, err := Load("test", []string{"--config", path}, noEnv)
+		if err == nil {
+			t.Fatal("expected an error for a misspelt key")
+		}
+	})
+
+	t.Run("bad env value", func(t *testing.T) {
+		_, _, err := Load("test", nil, envOf(map[string]string{"TTT_SSH_PORT": "twenty-two"}))
+		if err == nil || !strings.Contains(err.Error(), "TTT_SSH_PORT") {
+			t.Errorf("got %v, want an error naming TTT_SSH_PORT", err)
+		}
+	})
+
+	t.Run("an empty env value still counts", func(t *testing.T) {
+		path := writeConfig(t, `{"metrics": {"addr": "localhost:9000"}}`)
+		env := map[string]string{"TTT_CONFIG": path, "TTT_METRICS_ADDR": ""}
+
+		got, _, err := Load("test", nil, envOf(env))
+		assertNoError(t, err)
+		if got.Metrics.Addr != "" {
+			t.Errorf("metrics.addr %q, want it cleared by the environment", got.Metrics.Addr)
+		}
+	})
+
+	t.Run("print-config", func(t *testing.T) {
+		_, opts, err := Load("test", []string{"--print-config"}, noEnv)
+		assertNoError(t, err)
+		if !opts.PrintConfig {
+			t.Error("print-config was not set")
+		}
+	})
+}
+
+func TestValidate(t *testing.T) {
+	cfg := Default()
+	cfg.SSH.Port = 70000
+	cfg.Rooms.MaxRooms = 0
+	cfg.Database.Path = ""
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	for _, want := range []string{"ssh.port", "rooms.max_rooms", "database.path"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+}
+
+func TestPrintRoundTrip(t *testing.T) {
+	want := Default()
+	want.SSH.ShutdownTimeout = Duration(5 * time.Second)
+
+	var b strings.Builder
+	assertNoError(t, want.Print(&b))
+	path := writeConfig(t, b.String())
+
+	got, _, err := Load("test", []string{"--config", path}, envOf(nil))
+	assertNoError(t, err)
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+// envOf looks variables up in env the way os.LookupEnv does.
+func envOf(env map[string]string) func(string) (string, bool) {
+	return func(k string) (string, bool) {
+		v, ok := env[k]
+		return v, ok
+	}
+}
+
+func writeConfig(t testing.TB, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func assertNoError(t testing.TB, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("didn't expect an error but got one, %v", err)
+	}
+}
@@ -0,0 +1,236 @@
+// Package engine picks moves for any variant.State: a random mover for the
+// weakest computer players, a depth-limited alpha-beta search and a Monte
+// Carlo tree search for boards too big to search deeply.
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jwc20/ssh-ttt/variant"
+)
+
+var ErrUnknownEngine = errors.New("unknown engine")
+
+// Names lists the engines New builds.
+func Names() []string {
+	return []string{"alphabeta", "mcts", "random"}
+}
+
+// New returns the engine called name, set up for interactive play: each
+// move takes well under a second on any variant.
+func New(name string) (Engine, error) {
+	switch name {
+	case "alphabeta":
+		return &AlphaBeta{MaxDepth: 6, MaxNodes: 50_000}, nil
+	case "mcts":
+		return &MCTS{Budget: 400 * time.Millisecond}, nil
+	case "random":
+		return Random{}, nil
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownEngine, name)
+}
+
+// Engine chooses a move for the player to move in s. s must not be over.
+type Engine interface {
+	Move(s variant.State) int
+}
+
+// Random plays a uniformly random legal move.
+type Random struct {
+	// Rand, when set, is the source of the moves, so games can be
+	// replayed; nil uses the shared source.
+	Rand *rand.Rand
+}
+
+func (r Random) Move(s variant.State) int {
+	moves := s.Moves()
+	if r.Rand != nil {
+		return moves[r.Rand.IntN(len(moves))]
+	}
+	return moves[rand.IntN(len(moves))]
+}
+
+// blunderRates are how often each computer player level plays a random
+// move instead of its best. Perfect play can't be beaten at 3×3, so even
+// hard slips now and then.
+var blunderRates = map[string]float64{"easy": 1, "medium": 0.5, "hard": 0.1}
+
+// Levels lists the computer player levels, weakest first.
+func Levels() []string {
+	return []string{"easy", "medium", "hard"}
+}
+
+// BlunderRate returns the share of moves the computer player level plays
+// at random, or false if there is no such level.
+func BlunderRate(level string) (float64, bool) {
+	rate, ok := blunderRates[level]
+	return rate, ok
+}
+
+// win outscores any heuristic evaluation; the search adds the remaining
+// depth so quicker wins rank higher.
+const win = 1 << 20
+
+// AlphaBeta is an iterative-deepening negamax search. Unfinished leaves are
+// scored with the variant's Evaluator, or as even when it has none.
+type AlphaBeta struct {
+	// MaxDepth caps the plies searched; zero searches to the end.
+	MaxDepth int
+	// MaxNodes stops the search once it has visited this many positions;
+	// zero means no cap. The move from the deepest finished depth is used.
+	MaxNodes int
+	// Nodes is the number of positions the last Move visited.
+	Nodes int
+
+	aborted bool
+}
+
+func (a *AlphaBeta) Move(s variant.State) int {
+	moves := s.Moves()
+	best := moves[0]
+	a.Nodes, a.aborted = 0, false
+	limit := a.MaxDepth
+	if limit <= 0 {
+		limit = len(s.Cells())
+	}
+	for depth := 1; depth <= limit; depth++ {
+		move, score := a.root(s, moves, depth)
+		if a.aborted {
+			break
+		}
+		best = move
+		if score >= win || score <= -win {
+			break
+		}
+		// Search the best move first next time round for better cut-offs.
+		moves = append([]int{move}, remove(moves, move)...)
+	}
+	return best
+}
+
+// MoveScore is a move's score for the player making it. Scores of win or
+// more are forced wins and of -win or less forced losses.
+type MoveScore struct {
+	Move  int
+	Score int
+}
+
+// Outcome is 1 for a forced win, -1 for a forced loss and 0 when the search
+// could not tell.
+func (m MoveScore) Outcome() int {
+	switch {
+	case m.Score >= win:
+		return 1
+	case m.Score <= -win:
+		return -1
+	}
+	return 0
+}
+
+// Scores searches every legal move in s to the same depth, in the order
+// Moves lists them, so they can be compared with each other. It honours
+// MaxDepth and MaxNodes like Move, keeping the deepest finished depth.
+func (a *AlphaBeta) Scores(s variant.State) []MoveScore {
+	moves := s.Moves()
+	scores := make([]MoveScore, len(moves))
+	for i, m := range moves {
+		scores[i] = MoveScore{Move: m}
+	}
+	a.Nodes, a.aborted = 0, false
+	limit := a.MaxDepth
+	if limit <= 0 {
+		limit = len(s.Cells())
+	}
+	for depth := 1; depth <= limit; depth++ {
+		next := make([]MoveScore, len(moves))
+		decided := true
+		for i, m := range moves {
+			child := s.Clone()
+			child.Play(m)
+			next[i] = MoveScore{Move: m, Score: -a.negamax(child, depth-1, -2*win, 2*win)}
+			if a.aborted {
+				return scores
+			}
+			decided = decided && next[i].Outcome() != 0
+		}
+		scores = next
+		if decided {
+			break
+		}
+	}
+	return scores
+}
+
+func (a *AlphaBeta) root(s variant.State, moves []int, depth int) (int, int) {
+	best, bestScore := moves[0], -2*win
+	alpha := -2 * win
+	for _, m := range moves {
+		child := s.Clone()
+		child.Play(m)
+		score := -a.negamax(child, depth-1, -2*win, -alpha)
+		if a.aborted {
+			break
+		}
+		if score > bestScore {
+			best, bestScore = m, score
+		}
+		alpha = max(alpha, score)
+	}
+	return best, bestScore
+}
+
+func (a *AlphaBeta) negamax(s variant.State, depth, alpha, beta int) int {
+	a.Nodes++
+	if a.MaxNodes > 0 && a.Nodes >= a.MaxNodes {
+		a.aborted = true
+		return 0
+	}
+	sign := 1
+	if s.Turn() == 'O' {
+		sign = -1
+	}
+	if s.Over() {
+		switch s.Winner() {
+		case variant.Empty:
+			return 0
+		case s.Turn():
+			return win + depth
+		default:
+			return -win - depth
+		}
+	}
+	if depth == 0 {
+		if e, ok := s.(variant.Evaluator); ok {
+			return sign * e.Evaluate()
+		}
+		return 0
+	}
+	best := -2 * win
+	for _, m := range s.Moves() {
+		child := s.Clone()
+		child.Play(m)
+		score := -a.negamax(child, depth-1, -beta, -alpha)
+		if a.aborted {
+			return 0
+		}
+		best = max(best, score)
+		alpha = max(alpha, score)
+		if alpha >= beta {
+			break
+		}
+	}
+	return best
+}
+
+func remove(moves []int, m int) []int {
+	out := make([]int, 0, len(moves)-1)
+	for _, x := range moves {
+		if x != m {
+			out = append(out, x)
+		}
+	}
+	return out
+}
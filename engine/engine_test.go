@@ -0,0 +1,341 @@
+package engine
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jwc20/ssh-ttt/variant"
+)
+
+func TestAlphaBetaClassic(t *testing.T) {
+	t.Run("takes the win", func(t *testing.T) {
+		s := classic(t, 0, 3, 1, 4)
+		assertMove(t, &AlphaBeta{}, s, 2)
+	})
+
+	t.Run("blocks", func(t *testing.T) {
+		s := classic(t, 0, 4, 1)
+		assertMove(t, &AlphaBeta{}, s, 2)
+	})
+
+	t.Run("never loses to random play", func(t *testing.T) {
+		for range 50 {
+			s := variant.NewClassic()
+			players := map[rune]Engine{'X': Random{}, 'O': &AlphaBeta{}}
+			for !s.Over() {
+				if err := s.Play(players[s.Turn()].Move(s)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if s.Winner() == 'X' {
+				t.Fatalf("random play beat the search: %q", string(s.Cells()))
+			}
+		}
+	})
+}
+
+func TestAlphaBetaUltimate(t *testing.T) {
+	t.Run("wins the meta board", func(t *testing.T) {
+		// X wins boards 0 and 1, then takes two cells of board 2 and is
+		// sent back there with the game one move away.
+		s := variant.NewUltimate()
+		moves := []int{
+			0*9 + 4, 4*9 + 0, 0*9 + 3, 3*9 + 0, 0*9 + 5,
+			5*9 + 1, 1*9 + 4, 4*9 + 1, 1*9 + 3, 3*9 + 1, 1*9 + 5,
+			5*9 + 2, 2*9 + 0, 6*9 + 2, 2*9 + 1, 7*9 + 2,
+		}
+		if err := variant.Replay(s, moves); err != nil {
+			t.Fatal(err)
+		}
+		if s.Turn() != 'X' || s.Next() != 2 {
+			t.Fatalf("setup: turn %q, next %d", s.Turn(), s.Next())
+		}
+		ab := &AlphaBeta{MaxDepth: 3}
+		m := ab.Move(s)
+		if err := s.Play(m); err != nil {
+			t.Fatal(err)
+		}
+		if s.Winner() != 'X' {
+			t.Errorf("move %d did not win the game", m)
+		}
+	})
+
+	t.Run("node cap", func(t *testing.T) {
+		ab := &AlphaBeta{MaxNodes: 2000}
+		s := variant.NewUltimate()
+		m := ab.Move(s)
+		if !slices.Contains(s.Moves(), m) {
+			t.Errorf("move %d is not legal", m)
+		}
+		if ab.Nodes == 0 || ab.Nodes > ab.MaxNodes {
+			t.Errorf("visited %d nodes with a cap of %d", ab.Nodes, ab.MaxNodes)
+		}
+	})
+}
+
+func TestAlphaBetaScores(t *testing.T) {
+	t.Run("rates every move", func(t *testing.T) {
+		// X X . / O O . / . . . with X to move: 2 wins, anything else
+		// lets O win on 5, except 5 itself, which only draws.
+		s := classic(t, 0, 3, 1, 4)
+		scores := (&AlphaBeta{}).Scores(s)
+		want := map[int]int{2: 1, 5: 0, 6: -1, 7: -1, 8: -1}
+		if len(scores) != len(want) {
+			t.Fatalf("got %d scores, want %d", len(scores), len(want))
+		}
+		for _, sc := range scores {
+			if sc.Outcome() != want[sc.Move] {
+				t.Errorf("move %d: got outcome %d, want %d", sc.Move, sc.Outcome(), want[sc.Move])
+			}
+		}
+	})
+
+	t.Run("node cap keeps the last full depth", func(t *testing.T) {
+		ab := &AlphaBeta{MaxNodes: 3000}
+		scores := ab.Scores(variant.NewUltimate())
+		if len(scores) != 81 {
+			t.Errorf("got %d scores, want 81", len(scores))
+		}
+		if ab.Nodes > ab.MaxNodes {
+			t.Errorf("visited %d nodes with a cap of %d", ab.Nodes, ab.MaxNodes)
+		}
+	})
+}
+
+func classic(t testing.TB, moves ...int) variant.State {
+	t.Helper()
+	s := variant.NewClassic()
+	if err := variant.Replay(s, moves); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func assertMove(t testing.TB, e Engine, s variant.State, want int) {
+	t.Helper()
+	if got := e.Move(s); got != want {
+		t.Errorf("got move %d, want %d", got, want)
+	}
+}
+
+func TestAlphaBetaQubic(t *testing.T) {
+	// X has three of layer 0's top row and O must block the fourth.
+	s := variant.NewQubic(4)
+	if err := variant.Replay(s, []int{0, 16, 1, 32, 2}); err != nil {
+		t.Fatal(err)
+	}
+	assertMove(t, &AlphaBeta{MaxDepth: 2}, s, 3)
+}
+
+func TestAlphaBetaPlaysEveryVariant(t *testing.T) {
+	for _, info := range variant.All() {
+		t.Run(info.Name, func(t *testing.T) {
+			s := info.New()
+			players := map[rune]Engine{'X': &AlphaBeta{MaxDepth: 3, MaxNodes: 5000}, 'O': Random{}}
+			for !s.Over() {
+				m := players[s.Turn()].Move(s)
+				if !slices.Contains(s.Moves(), m) {
+					t.Fatalf("move %d is not legal", m)
+				}
+				if err := s.Play(m); err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestAlphaBetaMisere(t *testing.T) {
+	// X X . / O O . / . . . with X to move: 2 loses at once, anything else
+	// keeps the game going.
+	s := variant.NewMisere()
+	if err := variant.Replay(s, []int{0, 3, 1, 4}); err != nil {
+		t.Fatal(err)
+	}
+	if m := (&AlphaBeta{MaxDepth: 1}).Move(s); m == 2 {
+		t.Error("completed a line in misère")
+	}
+}
+
+func TestAlphaBetaWild(t *testing.T) {
+	// Two Os in the top row: the side to move finishes it with an O.
+	s := variant.NewWild()
+	if err := variant.Replay(s, []int{s.MoveFor(0, 'O'), s.MoveFor(1, 'O')}); err != nil {
+		t.Fatal(err)
+	}
+	assertMove(t, &AlphaBeta{MaxDepth: 2}, s, s.MoveFor(2, 'O'))
+}
+
+func TestAlphaBetaGravity(t *testing.T) {
+	t.Run("blocks a column", func(t *testing.T) {
+		// X has three stacked in column 0; O must drop on top.
+		s := variant.NewGrid(variant.ConnectFour)
+		if err := variant.Replay(s, []int{0, 6, 0, 6, 0}); err != nil {
+			t.Fatal(err)
+		}
+		assertMove(t, &AlphaBeta{MaxDepth: 4}, s, 0)
+	})
+
+	t.Run("only plays open columns", func(t *testing.T) {
+		s := variant.NewGrid(variant.Options{Cols: 3, Rows: 3, InARow: 3, Gravity: true})
+		if err := variant.Replay(s, []int{1, 1, 1}); err != nil {
+			t.Fatal(err)
+		}
+		if m := (&AlphaBeta{MaxDepth: 2}).Move(s); m == 1 {
+			t.Error("dropped into a full column")
+		}
+	})
+}
+
+func TestMCTSClassic(t *testing.T) {
+	t.Run("takes the win", func(t *testing.T) {
+		s := classic(t, 0, 3, 1, 4)
+		assertMove(t, &MCTS{Iterations: 2000, Workers: 2, Seed: 1}, s, 2)
+	})
+
+	t.Run("blocks", func(t *testing.T) {
+		s := classic(t, 0, 4, 1)
+		assertMove(t, &MCTS{Iterations: 5000, Workers: 2, Seed: 1}, s, 2)
+	})
+
+	t.Run("counts its playouts", func(t *testing.T) {
+		m := &MCTS{Iterations: 1001, Workers: 4, Seed: 1}
+		m.Move(variant.NewClassic())
+		if m.Playouts != 1001 {
+			t.Errorf("ran %d playouts, want 1001", m.Playouts)
+		}
+	})
+}
+
+func TestMCTSDeterministic(t *testing.T) {
+	s := variant.NewUltimate()
+	if err := variant.Replay(s, []int{40, 36, 4}); err != nil {
+		t.Fatal(err)
+	}
+	first := (&MCTS{Iterations: 3000, Workers: 4, Seed: 7}).Move(s)
+	for range 3 {
+		if got := (&MCTS{Iterations: 3000, Workers: 4, Seed: 7}).Move(s); got != first {
+			t.Fatalf("same seed chose %d, then %d", first, got)
+		}
+	}
+}
+
+func TestMCTSSeedWithoutWorkers(t *testing.T) {
+	s := variant.NewUltimate()
+	if err := variant.Replay(s, []int{40, 36, 4}); err != nil {
+		t.Fatal(err)
+	}
+	want := (&MCTS{Iterations: 3000, Workers: 1, Seed: 7}).Move(s)
+	m := &MCTS{Iterations: 3000, Seed: 7}
+	if got := m.Move(s); got != want {
+		t.Errorf("a seeded search with no workers chose %d, one worker chose %d", got, want)
+	}
+	if m.Playouts != 3000 {
+		t.Errorf("ran %d playouts, want 3000", m.Playouts)
+	}
+}
+
+func TestMCTSBudget(t *testing.T) {
+	m := &MCTS{Budget: 50 * time.Millisecond, Workers: 2}
+	start := time.Now()
+	move := m.Move(variant.NewQubic(4))
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("took %v with a budget of %v", elapsed, m.Budget)
+	}
+	if move < 0 || move >= 64 {
+		t.Errorf("move %d is off the board", move)
+	}
+	if m.Playouts == 0 {
+		t.Error("ran no playouts")
+	}
+}
+
+func TestMCTSPlaysEveryVariant(t *testing.T) {
+	for _, info := range variant.All() {
+		t.Run(info.Name, func(t *testing.T) {
+			s := info.New()
+			players := map[rune]Engine{'X': &MCTS{Iterations: 200, Workers: 2, Seed: 3}, 'O': Random{}}
+			for !s.Over() {
+				m := players[s.Turn()].Move(s)
+				if !slices.Contains(s.Moves(), m) {
+					t.Fatalf("move %d is not legal", m)
+				}
+				if err := s.Play(m); err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestMCTSBeatsRandom(t *testing.T) {
+	wins := 0
+	for i := range 10 {
+		s := variant.NewGrid(variant.ConnectFour)
+		players := map[rune]Engine{'X': &MCTS{Iterations: 2000, Workers: 2, Seed: uint64(i + 1)}, 'O': Random{}}
+		for !s.Over() {
+			if err := s.Play(players[s.Turn()].Move(s)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if s.Winner() == 'X' {
+			wins++
+		}
+	}
+	if wins < 8 {
+		t.Errorf("won %d of 10 games against random play", wins)
+	}
+}
+
+func TestRandomSeeded(t *testing.T) {
+	play := func() []int {
+		s := variant.NewQubic(4)
+		r := Random{Rand: rand.New(rand.NewPCG(3, 4))}
+		var moves []int
+		for !s.Over() {
+			m := r.Move(s)
+			moves = append(moves, m)
+			s.Play(m)
+		}
+		return moves
+	}
+	if first, second := play(), play(); !slices.Equal(first, second) {
+		t.Errorf("same seed played %v, then %v", first, second)
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, name := range Names() {
+		e, err := New(name)
+		if err != nil {
+			t.Fatalf("New(%q): %v", name, err)
+		}
+		s := variant.NewClassic()
+		if m := e.Move(s); !slices.Contains(s.Moves(), m) {
+			t.Errorf("%s played illegal move %d", name, m)
+		}
+	}
+	if _, err := New("oracle"); !errors.Is(err, ErrUnknownEngine) {
+		t.Errorf("got %v, want ErrUnknownEngine", err)
+	}
+}
+
+func TestBlunderRate(t *testing.T) {
+	prev := 1.0
+	for _, level := range Levels() {
+		rate, ok := BlunderRate(level)
+		if !ok {
+			t.Fatalf("level %q has no blunder rate", level)
+		}
+		if rate <= 0 || rate > prev {
+			t.Errorf("level %q blunders %v of the time, want above 0 and at most %v", level, rate, prev)
+		}
+		prev = rate
+	}
+	if _, ok := BlunderRate("grandmaster"); ok {
+		t.Error("an unknown level has a blunder rate")
+	}
+}
@@ -0,0 +1,203 @@
+package engine
+
+import (
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/jwc20/ssh-ttt/variant"
+)
+
+// DefaultIterations is the number of playouts MCTS runs when it is given
+// neither Iterations nor a Budget.
+const DefaultIterations = 10_000
+
+// MCTS is a Monte Carlo tree search using the UCT rule to pick which line
+// to explore next. It needs nothing from a variant beyond its legal moves,
+// Play and the result, so it copes with boards too big to search deeply.
+//
+// Each worker grows its own tree from the position; their root statistics
+// are added up and the most visited move wins.
+type MCTS struct {
+	// Iterations caps the playouts per move, shared between the workers.
+	Iterations int
+	// Budget caps the time per move.
+	Budget time.Duration
+	// Workers is the number of trees searched in parallel; zero means one
+	// per CPU, or one when Seed is set.
+	Workers int
+	// Exploration is the UCT constant; zero means √2.
+	Exploration float64
+	// Seed, when non-zero, makes Move deterministic: every worker's random
+	// source is derived from it and the time budget is ignored. The move
+	// also depends on the number of workers, so a seeded search with no
+	// Workers uses one rather than the machine's CPU count.
+	Seed uint64
+	// Playouts is the number of playouts the last Move ran.
+	Playouts int
+}
+
+// node is a position in a worker's tree, reached by playing move.
+type node struct {
+	move     int
+	mover    rune
+	parent   *node
+	children []*node
+	untried  []int
+	visits   int
+	// wins counts playouts from here won by mover, draws as a half.
+	wins float64
+}
+
+func newNode(s variant.State, move int, mover rune, parent *node) *node {
+	return &node{move: move, mover: mover, parent: parent, untried: s.Moves()}
+}
+
+type rootStats struct {
+	visits   map[int]int
+	playouts int
+}
+
+func (m *MCTS) Move(s variant.State) int {
+	moves := s.Moves()
+	if len(moves) == 1 {
+		m.Playouts = 0
+		return moves[0]
+	}
+
+	workers := m.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+		if m.Seed != 0 {
+			workers = 1
+		}
+	}
+	iterations, budget := m.Iterations, m.Budget
+	if m.Seed != 0 {
+		budget = 0
+	}
+	if iterations <= 0 && budget <= 0 {
+		iterations = DefaultIterations
+	}
+	var deadline time.Time
+	if budget > 0 {
+		deadline = time.Now().Add(budget)
+	}
+
+	seed := m.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	stats := make([]rootStats, workers)
+	var wg sync.WaitGroup
+	for w := range workers {
+		limit := -1
+		if iterations > 0 {
+			limit = iterations / workers
+			if w < iterations%workers {
+				limit++
+			}
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rng := rand.New(rand.NewPCG(seed, uint64(w)))
+			stats[w] = m.search(s, rng, limit, deadline)
+		}()
+	}
+	wg.Wait()
+
+	visits := make(map[int]int)
+	m.Playouts = 0
+	for _, st := range stats {
+		m.Playouts += st.playouts
+		for move, n := range st.visits {
+			visits[move] += n
+		}
+	}
+	best := moves[0]
+	for _, move := range moves {
+		if visits[move] > visits[best] {
+			best = move
+		}
+	}
+	return best
+}
+
+// search grows one tree for limit playouts, or until the deadline when
+// limit is negative or the deadline comes first.
+func (m *MCTS) search(s variant.State, rng *rand.Rand, limit int, deadline time.Time) rootStats {
+	c := m.Exploration
+	if c == 0 {
+		c = math.Sqrt2
+	}
+	root := newNode(s, -1, 0, nil)
+	playouts := 0
+	for limit < 0 || playouts < limit {
+		if !deadline.IsZero() && playouts%64 == 0 && time.Now().After(deadline) {
+			break
+		}
+		m.iterate(root, s.Clone(), rng, c)
+		playouts++
+	}
+
+	visits := make(map[int]int, len(root.children))
+	for _, child := range root.children {
+		visits[child.move] = child.visits
+	}
+	return rootStats{visits: visits, playouts: playouts}
+}
+
+// iterate runs one playout: select down the tree by UCT, expand one new
+// node, play randomly to the end and record the result on the way back.
+func (m *MCTS) iterate(root *node, s variant.State, rng *rand.Rand, c float64) {
+	n := root
+	for len(n.untried) == 0 && len(n.children) > 0 {
+		n = selectChild(n, c)
+		s.Play(n.move)
+	}
+
+	if len(n.untried) > 0 {
+		i := rng.IntN(len(n.untried))
+		move := n.untried[i]
+		n.untried[i] = n.untried[len(n.untried)-1]
+		n.untried = n.untried[:len(n.untried)-1]
+
+		mover := s.Turn()
+		s.Play(move)
+		child := newNode(s, move, mover, n)
+		n.children = append(n.children, child)
+		n = child
+	}
+
+	for !s.Over() {
+		moves := s.Moves()
+		s.Play(moves[rng.IntN(len(moves))])
+	}
+
+	winner := s.Winner()
+	for ; n != nil; n = n.parent {
+		n.visits++
+		switch winner {
+		case n.mover:
+			n.wins++
+		case variant.Empty:
+			n.wins += 0.5
+		}
+	}
+}
+
+func selectChild(n *node, c float64) *node {
+	logN := math.Log(float64(n.visits))
+	var best *node
+	bestScore := math.Inf(-1)
+	for _, child := range n.children {
+		score := child.wins/float64(child.visits) + c*math.Sqrt(logN/float64(child.visits))
+		if score > bestScore {
+			best, bestScore = child, score
+		}
+	}
+	return best
+}
@@ -8,6 +8,9 @@ import (
 	"log"
 	"os"
 	"sort"
+	"time"
+
+	"github.com/jwc20/ssh-ttt/metrics"
 )
 
 type FileSystemPlayerStore struct {
@@ -49,6 +52,8 @@ func NewFileSystemPlayerStore(file *os.File) (*FileSystemPlayerStore, error) {
 }
 
 func (f *FileSystemPlayerStore) RecordWin(name string) {
+	defer metrics.StoreDuration.With("json", "record_win").ObserveSince(time.Now())
+
 	player := f.league.Find(name)
 
 	if player != nil {
@@ -61,6 +66,8 @@ func (f *FileSystemPlayerStore) RecordWin(name string) {
 }
 
 func (f *FileSystemPlayerStore) GetLeague() League {
+	defer metrics.StoreDuration.With("json", "get_league").ObserveSince(time.Now())
+
 	sort.Slice(f.league, func(i, j int) bool {
 		return f.league[i].Wins > f.league[j].Wins
 	})
@@ -68,6 +75,8 @@ func (f *FileSystemPlayerStore) GetLeague() League {
 }
 
 func (f *FileSystemPlayerStore) GetPlayerScore(name string) int {
+	defer metrics.StoreDuration.With("json", "get_player_score").ObserveSince(time.Now())
+
 	player := f.league.Find(name)
 
 	if player != nil {
@@ -142,8 +151,43 @@ func NewFileSystemTTTStore(db *sql.DB) (*FileSystemTTTStore, error) {
 	}, nil
 }
 
+// User is an account in the web server's users table.
+type User struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	PublicKey string    `json:"public_key"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (f *FileSystemTTTStore) CreateUser(name, publicKey string) error {
+	defer metrics.StoreDuration.With("sqlite", "create_user").ObserveSince(time.Now())
+
+	_, err := f.Database.Exec("INSERT INTO users (name, public_key) VALUES (?, ?)", name, publicKey)
+	return err
+}
+
+func (f *FileSystemTTTStore) ListUsers() ([]User, error) {
+	defer metrics.StoreDuration.With("sqlite", "list_users").ObserveSince(time.Now())
+
+	rows, err := f.Database.Query("SELECT id, name, public_key, created_at FROM users")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []User
+	for rows.Next() {
+		var user User
+		if err := rows.Scan(&user.ID, &user.Name, &user.PublicKey, &user.CreatedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
 func FileSystemTTTStoreFromFile(path string) (*FileSystemTTTStore, func(), error) {
-	db, err := sql.Open("sqlite3", "./app.db")
+	db, err := sql.Open("sqlite3", path)
 	if err != nil {
 		return nil, nil, fmt.Errorf("problem opening %s %v", path, err)
 	}
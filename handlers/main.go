@@ -1,18 +1,13 @@
 package handlers
 
 import (
-	"database/sql"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	ttt "github.com/jwc20/ssh-ttt"
 )
 
-type User struct {
-	ID        int       `json:"id"`
-	Name      string    `json:"name"`
-	PublicKey string    `json:"public_key"`
-	CreatedAt time.Time `json:"created_at"`
-}
+type User = ttt.User
 
 type Room struct {
 	ID         int       `json:"id"`
@@ -22,13 +17,12 @@ type Room struct {
 	FinishedAt time.Time `json:"finished_at"`
 }
 
-func CreateUser(db *sql.DB) gin.HandlerFunc {
+func CreateUser(store *ttt.FileSystemTTTStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		name := c.PostForm("name")
 		publicKey := c.PostForm("public_key")
 
-		_, err := db.Exec("INSERT INTO users (name, public_key) VALUES (?, ?)", name, publicKey)
-		if err != nil {
+		if err := store.CreateUser(name, publicKey); err != nil {
 			c.JSON(500, gin.H{"error": err.Error()})
 			return
 		}
@@ -36,24 +30,13 @@ func CreateUser(db *sql.DB) gin.HandlerFunc {
 
 }
 
-func ListUser(db *sql.DB) gin.HandlerFunc {
+func ListUser(store *ttt.FileSystemTTTStore) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		rows, err := db.Query("SELECT id, name, public_key, created_at FROM users")
+		users, err := store.ListUsers()
 		if err != nil {
 			ctx.JSON(500, gin.H{"error": err.Error()})
 			return
 		}
-		defer rows.Close()
-
-		var users []User
-		for rows.Next() {
-			var user User
-			if err := rows.Scan(&user.ID, &user.Name, &user.PublicKey, &user.CreatedAt); err != nil {
-				ctx.JSON(500, gin.H{"error": err.Error()})
-				return
-			}
-			users = append(users, user)
-		}
 
 		ctx.JSON(200, users)
 	}
@@ -0,0 +1,80 @@
+package limits
+
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	ErrServerFull     = errors.New("the server is full")
+	ErrTooManyFromIP  = errors.New("too many sessions from your address")
+	ErrTooManyForUser = errors.New("too many sessions for your user name")
+)
+
+// ConnLimits caps concurrent sessions. A zero field means no limit.
+type ConnLimits struct {
+	MaxSessions int
+	MaxPerIP    int
+	MaxPerUser  int
+}
+
+// ConnLimiter counts open sessions overall, per remote address and per
+// user name.
+type ConnLimiter struct {
+	mu      sync.Mutex
+	limits  ConnLimits
+	total   int
+	perIP   map[string]int
+	perUser map[string]int
+}
+
+func NewConnLimiter(limits ConnLimits) *ConnLimiter {
+	return &ConnLimiter{
+		limits:  limits,
+		perIP:   make(map[string]int),
+		perUser: make(map[string]int),
+	}
+}
+
+// Acquire reserves a session slot for ip and user. The returned release
+// function frees it and is safe to call more than once.
+func (c *ConnLimiter) Acquire(ip, user string) (release func(), err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	over := func(limit, n int) bool { return limit > 0 && n >= limit }
+	switch {
+	case over(c.limits.MaxSessions, c.total):
+		return nil, ErrServerFull
+	case over(c.limits.MaxPerIP, c.perIP[ip]):
+		return nil, ErrTooManyFromIP
+	case over(c.limits.MaxPerUser, c.perUser[user]):
+		return nil, ErrTooManyForUser
+	}
+
+	c.total++
+	c.perIP[ip]++
+	c.perUser[user]++
+
+	var once sync.Once
+	return func() { once.Do(func() { c.release(ip, user) }) }, nil
+}
+
+func (c *ConnLimiter) release(ip, user string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.total--
+	if c.perIP[ip]--; c.perIP[ip] <= 0 {
+		delete(c.perIP, ip)
+	}
+	if c.perUser[user]--; c.perUser[user] <= 0 {
+		delete(c.perUser, user)
+	}
+}
+
+// Open returns the number of sessions currently holding a slot.
+func (c *ConnLimiter) Open() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.total
+}
@@ -0,0 +1,59 @@
+package limits
+
+import (
+	"sync"
+	"time"
+)
+
+// IdleTracker records the last activity of each key and reports the keys
+// that have been quiet for longer than the timeout.
+type IdleTracker struct {
+	mu      sync.Mutex
+	timeout time.Duration
+	last    map[string]time.Time
+	now     func() time.Time
+}
+
+// NewIdleTracker returns a tracker; a timeout of zero disables it.
+func NewIdleTracker(timeout time.Duration) *IdleTracker {
+	return &IdleTracker{
+		timeout: timeout,
+		last:    make(map[string]time.Time),
+		now:     time.Now,
+	}
+}
+
+func (t *IdleTracker) Timeout() time.Duration { return t.timeout }
+
+// Touch marks key as active now.
+func (t *IdleTracker) Touch(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.last[key] = t.now()
+}
+
+func (t *IdleTracker) Forget(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.last, key)
+}
+
+// Expired removes and returns every key idle for at least the timeout, so
+// each one is reported once.
+func (t *IdleTracker) Expired() []string {
+	if t.timeout <= 0 {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	var keys []string
+	for key, last := range t.last {
+		if now.Sub(last) >= t.timeout {
+			keys = append(keys, key)
+			delete(t.last, key)
+		}
+	}
+	return keys
+}
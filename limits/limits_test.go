@@ -0,0 +1,153 @@
+package limits
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func TestRateLimiter(t *testing.T) {
+	t.Run("allows a burst then refills over time", func(t *testing.T) {
+		clock := &fakeClock{t: time.Unix(0, 0)}
+		l := NewRateLimiter(3, time.Second)
+		l.now = clock.now
+
+		for i := range 3 {
+			if !l.Allow("bob") {
+				t.Fatalf("action %d should be allowed", i+1)
+			}
+		}
+		if l.Allow("bob") {
+			t.Fatal("fourth action should be limited")
+		}
+
+		clock.advance(time.Second)
+		if !l.Allow("bob") {
+			t.Error("one token should be back after an interval")
+		}
+		if l.Allow("bob") {
+			t.Error("only one token should be back")
+		}
+	})
+
+	t.Run("keys are independent", func(t *testing.T) {
+		l := NewRateLimiter(1, time.Hour)
+		l.Allow("bob")
+		if !l.Allow("alice") {
+			t.Error("alice should not share bob's bucket")
+		}
+	})
+
+	t.Run("forget resets a key", func(t *testing.T) {
+		l := NewRateLimiter(1, time.Hour)
+		l.Allow("bob")
+		l.Forget("bob")
+		if !l.Allow("bob") {
+			t.Error("bob should start with a full bucket again")
+		}
+	})
+}
+
+func TestConnLimiter(t *testing.T) {
+	t.Run("per user", func(t *testing.T) {
+		c := NewConnLimiter(ConnLimits{MaxPerUser: 2})
+		acquire(t, c, "1.1.1.1", "bob")
+		release := acquire(t, c, "2.2.2.2", "bob")
+
+		assertLimitErr(t, c, "3.3.3.3", "bob", ErrTooManyForUser)
+		acquire(t, c, "3.3.3.3", "alice")
+
+		release()
+		acquire(t, c, "3.3.3.3", "bob")
+	})
+
+	t.Run("per IP", func(t *testing.T) {
+		c := NewConnLimiter(ConnLimits{MaxPerIP: 1})
+		acquire(t, c, "1.1.1.1", "bob")
+		assertLimitErr(t, c, "1.1.1.1", "alice", ErrTooManyFromIP)
+		acquire(t, c, "2.2.2.2", "alice")
+	})
+
+	t.Run("global", func(t *testing.T) {
+		c := NewConnLimiter(ConnLimits{MaxSessions: 2, MaxPerIP: 5, MaxPerUser: 5})
+		acquire(t, c, "1.1.1.1", "bob")
+		acquire(t, c, "2.2.2.2", "alice")
+		assertLimitErr(t, c, "3.3.3.3", "carol", ErrServerFull)
+		if got := c.Open(); got != 2 {
+			t.Errorf("got %d open sessions, want 2", got)
+		}
+	})
+
+	t.Run("release is idempotent", func(t *testing.T) {
+		c := NewConnLimiter(ConnLimits{MaxSessions: 1})
+		release := acquire(t, c, "1.1.1.1", "bob")
+		release()
+		release()
+		if got := c.Open(); got != 0 {
+			t.Errorf("got %d open sessions, want 0", got)
+		}
+	})
+
+	t.Run("zero means unlimited", func(t *testing.T) {
+		c := NewConnLimiter(ConnLimits{})
+		for range 100 {
+			acquire(t, c, "1.1.1.1", "bob")
+		}
+	})
+}
+
+func TestIdleTracker(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	tr := NewIdleTracker(time.Minute)
+	tr.now = clock.now
+
+	tr.Touch("a")
+	tr.Touch("b")
+	clock.advance(40 * time.Second)
+	tr.Touch("b")
+	clock.advance(20 * time.Second)
+
+	if got := tr.Expired(); !slices.Equal(got, []string{"a"}) {
+		t.Errorf("got %v expired, want [a]", got)
+	}
+	if got := tr.Expired(); len(got) != 0 {
+		t.Errorf("expired keys should only be reported once, got %v", got)
+	}
+
+	tr.Forget("b")
+	clock.advance(time.Hour)
+	if got := tr.Expired(); len(got) != 0 {
+		t.Errorf("forgotten keys should not expire, got %v", got)
+	}
+
+	t.Run("zero timeout disables", func(t *testing.T) {
+		tr := NewIdleTracker(0)
+		tr.Touch("a")
+		if got := tr.Expired(); got != nil {
+			t.Errorf("got %v, want nothing", got)
+		}
+	})
+}
+
+func acquire(t testing.TB, c *ConnLimiter, ip, user string) func() {
+	t.Helper()
+	release, err := c.Acquire(ip, user)
+	if err != nil {
+		t.Fatalf("acquire %s@%s: %v", user, ip, err)
+	}
+	return release
+}
+
+func assertLimitErr(t testing.TB, c *ConnLimiter, ip, user string, want error) {
+	t.Helper()
+	_, err := c.Acquire(ip, user)
+	if !errors.Is(err, want) {
+		t.Errorf("got %v, want %v", err, want)
+	}
+}
@@ -0,0 +1,64 @@
+// Package limits holds the rate, connection and idle limits the SSH server
+// applies to its users.
+package limits
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter is a keyed token bucket: each key may spend up to burst
+// actions at once and regains one action every interval.
+type RateLimiter struct {
+	mu       sync.Mutex
+	burst    int
+	interval time.Duration
+	buckets  map[string]*bucket
+	now      func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func NewRateLimiter(burst int, interval time.Duration) *RateLimiter {
+	return &RateLimiter{
+		burst:    burst,
+		interval: interval,
+		buckets:  make(map[string]*bucket),
+		now:      time.Now,
+	}
+}
+
+// Allow spends one token for key and reports whether one was available.
+func (l *RateLimiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.burst), last: now}
+		l.buckets[key] = b
+	}
+
+	if l.interval > 0 {
+		b.tokens += float64(now.Sub(b.last)) / float64(l.interval)
+		b.tokens = min(b.tokens, float64(l.burst))
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Forget drops the bucket for key, e.g. when a session ends.
+func (l *RateLimiter) Forget(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.buckets, key)
+}
@@ -0,0 +1,427 @@
+// Package metrics is a small, dependency-free registry of counters, gauges
+// and histograms that can be scraped by Prometheus in the text exposition
+// format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefBuckets are latency buckets in seconds, from 500µs to 10s.
+var DefBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Default is the registry served by Handler.
+var Default = NewRegistry()
+
+// StoreDuration times store operations in every binary, labelled by store
+// implementation and operation.
+var StoreDuration = Default.NewHistogramVec("ttt_store_duration_seconds",
+	"Time spent in store operations.", DefBuckets, "store", "op")
+
+// HTTPRequests and HTTPDuration count and time the requests handled by
+// every binary's HTTP server, labelled by method and matched route.
+var (
+	HTTPRequests = Default.NewCounterVec("ttt_http_requests_total",
+		"HTTP requests handled, by status.", "method", "route", "status")
+	HTTPDuration = Default.NewHistogramVec("ttt_http_request_duration_seconds",
+		"Time spent handling HTTP requests.", DefBuckets, "method", "route")
+)
+
+func init() {
+	start := float64(time.Now().Unix())
+	Default.NewGaugeFunc("process_start_time_seconds",
+		"Start time of the process since the Unix epoch in seconds.",
+		func() float64 { return start })
+	Default.NewGaugeFunc("go_goroutines", "Number of goroutines that currently exist.",
+		func() float64 { return float64(runtime.NumGoroutine()) })
+}
+
+// ── Registry ─────────────────────────────────────────────────────────────────
+
+type metric interface {
+	kind() string
+	write(w io.Writer, name string)
+}
+
+type entry struct {
+	help string
+	m    metric
+}
+
+type Registry struct {
+	mu      sync.Mutex
+	metrics map[string]entry
+}
+
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]entry)}
+}
+
+func (r *Registry) register(name, help string, m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, dup := r.metrics[name]; dup {
+		panic(fmt.Sprintf("metrics: %s registered twice", name))
+	}
+	r.metrics[name] = entry{help: help, m: m}
+}
+
+// Write writes every metric in the text exposition format, sorted by name.
+func (r *Registry) Write(w io.Writer) {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.metrics))
+	entries := make(map[string]entry, len(r.metrics))
+	for name, e := range r.metrics {
+		names = append(names, name)
+		entries[name] = e
+	}
+	r.mu.Unlock()
+	sort.Strings(names)
+
+	for _, name := range names {
+		e := entries[name]
+		fmt.Fprintf(w, "# HELP %s %s\n", name, escapeHelp(e.help))
+		fmt.Fprintf(w, "# TYPE %s %s\n", name, e.m.kind())
+		e.m.write(w, name)
+	}
+}
+
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.Write(w)
+	})
+}
+
+// Handler serves the Default registry.
+func Handler() http.Handler { return Default.Handler() }
+
+// ── Counter & Gauge ──────────────────────────────────────────────────────────
+
+// value is a float64 that can be updated atomically.
+type value struct{ bits atomic.Uint64 }
+
+func (v *value) add(d float64) {
+	for {
+		old := v.bits.Load()
+		if v.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+d)) {
+			return
+		}
+	}
+}
+
+func (v *value) load() float64 { return math.Float64frombits(v.bits.Load()) }
+
+type Counter struct{ v value }
+
+func (c *Counter) Inc() { c.v.add(1) }
+
+// Add increases the counter; negative deltas are ignored.
+func (c *Counter) Add(d float64) {
+	if d > 0 {
+		c.v.add(d)
+	}
+}
+
+func (c *Counter) Value() float64 { return c.v.load() }
+
+func (c *Counter) kind() string { return "counter" }
+
+func (c *Counter) write(w io.Writer, name string) { writeSample(w, name, "", c.Value()) }
+
+func (r *Registry) NewCounter(name, help string) *Counter {
+	c := &Counter{}
+	r.register(name, help, c)
+	return c
+}
+
+type Gauge struct{ v value }
+
+func (g *Gauge) Set(f float64) { g.v.bits.Store(math.Float64bits(f)) }
+func (g *Gauge) Add(d float64) { g.v.add(d) }
+func (g *Gauge) Inc()          { g.v.add(1) }
+func (g *Gauge) Dec()          { g.v.add(-1) }
+func (g *Gauge) Value() float64 {
+	return g.v.load()
+}
+
+func (g *Gauge) kind() string { return "gauge" }
+
+func (g *Gauge) write(w io.Writer, name string) { writeSample(w, name, "", g.Value()) }
+
+func (r *Registry) NewGauge(name, help string) *Gauge {
+	g := &Gauge{}
+	r.register(name, help, g)
+	return g
+}
+
+type gaugeFunc func() float64
+
+func (f gaugeFunc) kind() string { return "gauge" }
+
+func (f gaugeFunc) write(w io.Writer, name string) { writeSample(w, name, "", f()) }
+
+// NewGaugeFunc registers a gauge whose value is read from fn at scrape time.
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.register(name, help, gaugeFunc(fn))
+}
+
+type gaugeVecFunc struct {
+	labels []string
+	fn     func(set func(v float64, labelValues ...string))
+}
+
+func (g gaugeVecFunc) kind() string { return "gauge" }
+
+func (g gaugeVecFunc) write(w io.Writer, name string) {
+	type sample struct {
+		labels string
+		v      float64
+	}
+	var samples []sample
+	g.fn(func(v float64, values ...string) {
+		samples = append(samples, sample{formatLabels(g.labels, values, "", ""), v})
+	})
+	sort.Slice(samples, func(i, j int) bool { return samples[i].labels < samples[j].labels })
+	for _, s := range samples {
+		writeSample(w, name, s.labels, s.v)
+	}
+}
+
+// NewGaugeVecFunc registers a labelled gauge collected at scrape time: fn
+// calls set once per series.
+func (r *Registry) NewGaugeVecFunc(name, help string, labels []string, fn func(set func(v float64, labelValues ...string))) {
+	r.register(name, help, gaugeVecFunc{labels: labels, fn: fn})
+}
+
+// ── Histogram ────────────────────────────────────────────────────────────────
+
+type Histogram struct {
+	mu      sync.Mutex
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func newHistogram(buckets []float64) *Histogram {
+	return &Histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, upper := range h.buckets {
+		if v <= upper {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+// ObserveSince records the seconds elapsed since start. It is meant to be
+// deferred: defer h.ObserveSince(time.Now()).
+func (h *Histogram) ObserveSince(start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+func (h *Histogram) kind() string { return "histogram" }
+
+func (h *Histogram) write(w io.Writer, name string) { h.writeLabelled(w, name, nil, nil) }
+
+func (h *Histogram) writeLabelled(w io.Writer, name string, labels, values []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, upper := range h.buckets {
+		writeSample(w, name+"_bucket", formatLabels(labels, values, "le", formatFloat(upper)), float64(h.counts[i]))
+	}
+	writeSample(w, name+"_bucket", formatLabels(labels, values, "le", "+Inf"), float64(h.count))
+	writeSample(w, name+"_sum", formatLabels(labels, values, "", ""), h.sum)
+	writeSample(w, name+"_count", formatLabels(labels, values, "", ""), float64(h.count))
+}
+
+func (r *Registry) NewHistogram(name, help string, buckets []float64) *Histogram {
+	h := newHistogram(buckets)
+	r.register(name, help, h)
+	return h
+}
+
+// ── Labelled vectors ─────────────────────────────────────────────────────────
+
+type vec[T any] struct {
+	mu       sync.Mutex
+	labels   []string
+	children map[string]*child[T]
+	newChild func() T
+}
+
+type child[T any] struct {
+	values []string
+	m      T
+}
+
+func (v *vec[T]) with(values []string) T {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: got %d label values for labels %v", len(values), v.labels))
+	}
+	key := strings.Join(values, "\xff")
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	c, ok := v.children[key]
+	if !ok {
+		c = &child[T]{values: append([]string(nil), values...), m: v.newChild()}
+		v.children[key] = c
+	}
+	return c.m
+}
+
+func (v *vec[T]) sorted() []*child[T] {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	keys := make([]string, 0, len(v.children))
+	for k := range v.children {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]*child[T], len(keys))
+	for i, k := range keys {
+		out[i] = v.children[k]
+	}
+	return out
+}
+
+type CounterVec struct{ vec[*Counter] }
+
+// With returns the counter for the given label values, creating it on first
+// use.
+func (c *CounterVec) With(labelValues ...string) *Counter { return c.with(labelValues) }
+
+func (c *CounterVec) kind() string { return "counter" }
+
+func (c *CounterVec) write(w io.Writer, name string) {
+	for _, ch := range c.sorted() {
+		writeSample(w, name, formatLabels(c.labels, ch.values, "", ""), ch.m.Value())
+	}
+}
+
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{vec[*Counter]{
+		labels:   labels,
+		children: make(map[string]*child[*Counter]),
+		newChild: func() *Counter { return &Counter{} },
+	}}
+	r.register(name, help, c)
+	return c
+}
+
+type HistogramVec struct{ vec[*Histogram] }
+
+func (h *HistogramVec) With(labelValues ...string) *Histogram { return h.with(labelValues) }
+
+func (h *HistogramVec) kind() string { return "histogram" }
+
+func (h *HistogramVec) write(w io.Writer, name string) {
+	for _, ch := range h.sorted() {
+		ch.m.writeLabelled(w, name, h.labels, ch.values)
+	}
+}
+
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{vec[*Histogram]{
+		labels:   labels,
+		children: make(map[string]*child[*Histogram]),
+		newChild: func() *Histogram { return newHistogram(buckets) },
+	}}
+	r.register(name, help, h)
+	return h
+}
+
+// ── HTTP ─────────────────────────────────────────────────────────────────────
+
+// unmatchedRoute labels requests no route matched, so stray paths don't
+// each get a series of their own.
+const unmatchedRoute = "unmatched"
+
+// ObserveRequest records a request to route that started at start.
+func ObserveRequest(method, route string, status int, start time.Time) {
+	if route == "" {
+		route = unmatchedRoute
+	}
+	HTTPRequests.With(method, route, strconv.Itoa(status)).Inc()
+	HTTPDuration.With(method, route).ObserveSince(start)
+}
+
+// Instrument counts and times every request mux serves under the pattern
+// it matched.
+func Instrument(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		_, route := mux.Handler(req)
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		mux.ServeHTTP(rec, req)
+		ObserveRequest(req.Method, route, rec.status, start)
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// ── Exposition format ────────────────────────────────────────────────────────
+
+func writeSample(w io.Writer, name, labels string, v float64) {
+	fmt.Fprintf(w, "%s%s %s\n", name, labels, formatFloat(v))
+}
+
+// formatLabels renders {a="1",b="2"}, with an optional extra pair such as
+// the histogram "le" label appended.
+func formatLabels(names, values []string, extraName, extraValue string) string {
+	var pairs []string
+	for i, n := range names {
+		pairs = append(pairs, n+`="`+escapeLabel(values[i])+`"`)
+	}
+	if extraName != "" {
+		pairs = append(pairs, extraName+`="`+extraValue+`"`)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
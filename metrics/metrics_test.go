@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Run("counters and gauges", func(t *testing.T) {
+		r := NewRegistry()
+		c := r.NewCounter("moves_total", "Moves played.")
+		g := r.NewGauge("sessions", "Open sessions.")
+
+		c.Inc()
+		c.Add(2)
+		c.Add(-5)
+		g.Inc()
+		g.Inc()
+		g.Dec()
+
+		assertExposition(t, r, `# HELP moves_total Moves played.
+# TYPE moves_total counter
+moves_total 3
+# HELP sessions Open sessions.
+# TYPE sessions gauge
+sessions 1
+`)
+	})
+
+	t.Run("labelled series are sorted and escaped", func(t *testing.T) {
+		r := NewRegistry()
+		games := r.NewCounterVec("games_total", "Games.", "result")
+		games.With("x").Inc()
+		games.With("draw").Inc()
+		games.With("x").Inc()
+		r.NewGaugeVecFunc("rooms", "Rooms.", []string{"name"}, func(set func(float64, ...string)) {
+			set(1, `say "hi"`)
+		})
+
+		assertExposition(t, r, `# HELP games_total Games.
+# TYPE games_total counter
+games_total{result="draw"} 1
+games_total{result="x"} 2
+# HELP rooms Rooms.
+# TYPE rooms gauge
+rooms{name="say \"hi\""} 1
+`)
+	})
+
+	t.Run("histogram buckets are cumulative", func(t *testing.T) {
+		r := NewRegistry()
+		h := r.NewHistogramVec("op_seconds", "Op time.", []float64{0.1, 1}, "op")
+		h.With("save").Observe(0.05)
+		h.With("save").Observe(0.5)
+		h.With("save").Observe(3)
+
+		assertExposition(t, r, `# HELP op_seconds Op time.
+# TYPE op_seconds histogram
+op_seconds_bucket{op="save",le="0.1"} 1
+op_seconds_bucket{op="save",le="1"} 2
+op_seconds_bucket{op="save",le="+Inf"} 3
+op_seconds_sum{op="save"} 3.55
+op_seconds_count{op="save"} 3
+`)
+	})
+
+	t.Run("registering a name twice panics", func(t *testing.T) {
+		r := NewRegistry()
+		r.NewCounter("dup", "")
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic")
+			}
+		}()
+		r.NewGauge("dup", "")
+	})
+
+	t.Run("handler", func(t *testing.T) {
+		r := NewRegistry()
+		r.NewGaugeFunc("answer", "The answer.", func() float64 { return 42 })
+
+		response := httptest.NewRecorder()
+		r.Handler().ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		if got := response.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/plain; version=0.0.4") {
+			t.Errorf("content type %q", got)
+		}
+		if !strings.Contains(response.Body.String(), "answer 42\n") {
+			t.Errorf("body %q does not contain the gauge", response.Body.String())
+		}
+	})
+}
+
+func TestInstrument(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/games/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	h := Instrument(mux)
+
+	for _, path := range []string{"/api/games/1", "/api/games/2", "/nowhere"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if got := HTTPRequests.With("GET", "GET /api/games/{id}", "404").Value(); got != 2 {
+		t.Errorf("got %v requests to the route, want 2", got)
+	}
+	if got := HTTPRequests.With("GET", unmatchedRoute, "404").Value(); got != 1 {
+		t.Errorf("got %v unmatched requests, want 1", got)
+	}
+}
+
+func assertExposition(t testing.TB, r *Registry, want string) {
+	t.Helper()
+	var b strings.Builder
+	r.Write(&b)
+	if got := b.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,536 @@
+package ttt
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jwc20/ssh-ttt/variant"
+)
+
+// Game notation
+//
+// Games are written as a block of tag pairs, a blank line and the moves,
+// in the manner of chess's PGN:
+//
+//	[Variant "classic"]
+//	[Date "2026.10.19"]
+//	[X "alice"]
+//	[O "bob"]
+//	[Result "1-0"]
+//
+//	1. b2 b3 2. c3 a1 3. c1 a3 4. c2 1-0
+//
+// Tag values are quoted, with \" and \\ escaped. Variant names a variant
+// from the registry and Board, for variants that take one, its options as
+// variant.Options prints them. Unknown players are "?" and an unknown date
+// "????.??.??". Result is 1-0 when X wins, 0-1 when O wins, 1/2-1/2 for a
+// draw and * for a game still in progress; the moves end with it too. Any
+// other tags are kept, in order.
+//
+// A move names the cell its mark goes on: a file letter counted from a on
+// the left, then a rank counted from 1 at the bottom. Boards made of
+// several grids (ultimate's sub-boards, qubic's layers, notakto's boards)
+// put the grid's number, counted from 1 in reading order, and a colon in
+// front: 5:b2 is the centre of ultimate's centre board. Where players
+// choose the mark, =X or =O follows the cell. Gravity moves name the cell
+// the mark lands on. The move numbers are optional when reading.
+//
+// A classic game may start from a set-up position, given in a Position tag
+// in position notation; the moves then carry on from it.
+
+var ErrBadNotation = errors.New("bad game notation")
+
+const (
+	ResultXWins   = "1-0"
+	ResultOWins   = "0-1"
+	ResultDraw    = "1/2-1/2"
+	ResultOngoing = "*"
+)
+
+const (
+	unknownPlayer = "?"
+	unknownDate   = "????.??.??"
+	// DateFormat is how the Date tag writes a day.
+	DateFormat = "2006.01.02"
+)
+
+// Tag is a tag pair the notation has no field for.
+type Tag struct {
+	Name, Value string
+}
+
+// GameRecord is a game as the notation writes it. Empty players and date
+// are unknown.
+type GameRecord struct {
+	Variant string
+	// Board is the board the variant was played on; zero means its
+	// defaults.
+	Board variant.Options
+	// Setup is the position a classic game was set up from; nil means
+	// the empty board.
+	Setup   *Position
+	Date    string
+	PlayerX string
+	PlayerO string
+	// Result is one of the Result constants; empty means the result of
+	// the position the moves reach.
+	Result string
+	// Moves are the moves played, as variant.State.Play takes them.
+	Moves []int
+	Tags  []Tag
+}
+
+// NewState returns the position the record starts from.
+func (r GameRecord) NewState() (variant.State, error) {
+	if r.Setup == nil {
+		return variant.NewWith(r.Variant, r.Board)
+	}
+	if r.Variant != "classic" {
+		return nil, fmt.Errorf("%w: only classic games can be set up, not %s", ErrBadPosition, r.Variant)
+	}
+	return r.Setup.State()
+}
+
+// State returns the position the record's moves reach.
+func (r GameRecord) State() (variant.State, error) {
+	s, err := r.NewState()
+	if err != nil {
+		return nil, err
+	}
+	for i, m := range r.Moves {
+		if err := s.Play(m); err != nil {
+			return nil, fmt.Errorf("%w: move %d: %w", ErrBadNotation, i+1, err)
+		}
+	}
+	return s, nil
+}
+
+// ResultOf returns the result of s in notation form.
+func ResultOf(s variant.State) string {
+	if !s.Over() {
+		return ResultOngoing
+	}
+	switch s.Winner() {
+	case 'X':
+		return ResultXWins
+	case 'O':
+		return ResultOWins
+	}
+	return ResultDraw
+}
+
+// MarshalText writes the record in game notation.
+func (r GameRecord) MarshalText() ([]byte, error) {
+	s, err := r.NewState()
+	if err != nil {
+		return nil, err
+	}
+	// A set-up game may start with O to move, numbered 1... like chess.
+	first := 0
+	if s.Turn() == 'O' {
+		first = 1
+	}
+	var moves []string
+	for i, m := range r.Moves {
+		text := FormatMove(s, m)
+		if err := s.Play(m); err != nil {
+			return nil, fmt.Errorf("%w: move %d: %w", ErrBadNotation, i+1, err)
+		}
+		switch ply := i + first; {
+		case ply%2 == 0:
+			text = fmt.Sprintf("%d. %s", ply/2+1, text)
+		case i == 0:
+			text = fmt.Sprintf("%d... %s", ply/2+1, text)
+		}
+		moves = append(moves, text)
+	}
+	result := r.Result
+	if result == "" {
+		result = ResultOf(s)
+	}
+
+	var b strings.Builder
+	tag := func(name, value, unknown string) {
+		if value == "" {
+			value = unknown
+		}
+		fmt.Fprintf(&b, "[%s %s]\n", name, strconv.Quote(value))
+	}
+	tag("Variant", r.Variant, "")
+	if r.Board != (variant.Options{}) {
+		tag("Board", r.Board.String(), "")
+	}
+	if r.Setup != nil {
+		tag("Position", r.Setup.Notation(), "")
+	}
+	tag("Date", r.Date, unknownDate)
+	tag("X", r.PlayerX, unknownPlayer)
+	tag("O", r.PlayerO, unknownPlayer)
+	tag("Result", result, "")
+	for _, t := range r.Tags {
+		tag(t.Name, t.Value, "")
+	}
+	b.WriteString("\n")
+
+	line := 0
+	for _, word := range append(moves, result) {
+		if line > 0 && line+1+len(word) > 79 {
+			b.WriteString("\n")
+			line = 0
+		}
+		if line > 0 {
+			b.WriteString(" ")
+			line++
+		}
+		b.WriteString(word)
+		line += len(word)
+	}
+	b.WriteString("\n")
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText reads a record written in game notation; see ParseGame.
+func (r *GameRecord) UnmarshalText(text []byte) error {
+	rec, err := ParseGame(string(text))
+	if err != nil {
+		return err
+	}
+	*r = rec
+	return nil
+}
+
+var (
+	tagPattern        = regexp.MustCompile(`^\[([A-Za-z0-9_]+)\s+("(?:[^"\\]|\\.)*")\]$`)
+	moveNumberPattern = regexp.MustCompile(`^[0-9]+\.+`)
+)
+
+// ParseGame reads a game in notation. The moves must be legal and the
+// result must match the final position when the game is over; unfinished
+// games may carry any result, as they end when a player runs out of time.
+func ParseGame(text string) (GameRecord, error) {
+	var r GameRecord
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	body := len(lines)
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "[") {
+			body = i
+			break
+		}
+		m := tagPattern.FindStringSubmatch(line)
+		if m == nil {
+			return GameRecord{}, fmt.Errorf("%w: bad tag %s", ErrBadNotation, line)
+		}
+		value, err := strconv.Unquote(m[2])
+		if err != nil {
+			return GameRecord{}, fmt.Errorf("%w: bad tag %s", ErrBadNotation, line)
+		}
+		if err := r.setTag(m[1], value); err != nil {
+			return GameRecord{}, err
+		}
+	}
+	if r.Variant == "" {
+		return GameRecord{}, fmt.Errorf("%w: missing Variant tag", ErrBadNotation)
+	}
+
+	s, err := r.NewState()
+	if err != nil {
+		if errors.Is(err, ErrBadPosition) {
+			return GameRecord{}, err
+		}
+		return GameRecord{}, fmt.Errorf("%w: %w", ErrBadNotation, err)
+	}
+	result := ""
+	for _, word := range strings.Fields(strings.Join(lines[body:], " ")) {
+		if result != "" {
+			return GameRecord{}, fmt.Errorf("%w: %s after the result", ErrBadNotation, word)
+		}
+		switch word {
+		case ResultXWins, ResultOWins, ResultDraw, ResultOngoing:
+			result = word
+			continue
+		}
+		word = moveNumberPattern.ReplaceAllString(word, "")
+		if word == "" {
+			continue
+		}
+		m, err := ParseMove(s, word)
+		if err != nil {
+			return GameRecord{}, fmt.Errorf("move %d: %w", len(r.Moves)+1, err)
+		}
+		s.Play(m)
+		r.Moves = append(r.Moves, m)
+	}
+
+	switch {
+	case r.Result == "":
+		r.Result = result
+	case result != "" && result != r.Result:
+		return GameRecord{}, fmt.Errorf("%w: Result tag says %s but the moves end %s", ErrBadNotation, r.Result, result)
+	}
+	if r.Result == "" {
+		r.Result = ResultOf(s)
+	}
+	if s.Over() && r.Result != ResultOf(s) {
+		return GameRecord{}, fmt.Errorf("%w: result %s, but the game ended %s", ErrBadNotation, r.Result, ResultOf(s))
+	}
+	return r, nil
+}
+
+func (r *GameRecord) setTag(name, value string) error {
+	switch name {
+	case "Variant":
+		r.Variant = value
+	case "Board":
+		opts, err := variant.ParseOptions(value)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrBadNotation, err)
+		}
+		r.Board = opts
+	case "Position":
+		p, err := ParsePosition(value)
+		if err != nil {
+			return err
+		}
+		r.Setup = &p
+	case "Date":
+		r.Date = known(value, unknownDate)
+	case "X":
+		r.PlayerX = known(value, unknownPlayer)
+	case "O":
+		r.PlayerO = known(value, unknownPlayer)
+	case "Result":
+		switch value {
+		case ResultXWins, ResultOWins, ResultDraw, ResultOngoing:
+			r.Result = value
+		default:
+			return fmt.Errorf("%w: unknown result %q", ErrBadNotation, value)
+		}
+	default:
+		r.Tags = append(r.Tags, Tag{Name: name, Value: value})
+	}
+	return nil
+}
+
+// known returns value, or "" when it is the unknown placeholder.
+func known(value, unknown string) string {
+	if value == unknown {
+		return ""
+	}
+	return value
+}
+
+// geometry is how a variant lays out its cells: grids of cols×rows cells
+// one after another, each numbered row by row from the top.
+func geometry(s variant.State) (grids, cols, rows int) {
+	switch b := s.(type) {
+	case *variant.Ultimate:
+		return 9, 3, 3
+	case *variant.Qubic:
+		return b.Size(), b.Size(), b.Size()
+	case *variant.Notakto:
+		return b.Boards(), 3, 3
+	case *variant.Grid:
+		return 1, b.Options().Cols, b.Options().Rows
+	}
+	n := int(math.Sqrt(float64(len(s.Cells()))))
+	return 1, n, n
+}
+
+// FormatMove writes move, which must be legal in s, in notation.
+func FormatMove(s variant.State, move int) string {
+	cell, mark := move, ""
+	if g, ok := s.(*variant.Grid); ok && g.Options().Gravity {
+		cell = g.Drop(move)
+	}
+	if c, ok := s.(variant.MarkChooser); ok {
+		cell = move % len(s.Cells())
+		mark = "=O"
+		if c.MoveFor(cell, 'X') == move {
+			mark = "=X"
+		}
+	}
+
+	grids, cols, rows := geometry(s)
+	in := cell % (cols * rows)
+	text := fmt.Sprintf("%c%d", 'a'+in%cols, rows-in/cols)
+	if grids > 1 {
+		text = fmt.Sprintf("%d:%s", cell/(cols*rows)+1, text)
+	}
+	return text + mark
+}
+
+var movePattern = regexp.MustCompile(`^(?:([0-9]+):)?([a-z])([0-9]+)(?:=([XO]))?$`)
+
+// ParseMove reads a move in notation and checks that it is legal in s.
+func ParseMove(s variant.State, text string) (int, error) {
+	m := movePattern.FindStringSubmatch(text)
+	if m == nil {
+		return 0, fmt.Errorf("%w: %q is not a move", ErrBadNotation, text)
+	}
+	grids, cols, rows := geometry(s)
+	grid := 1
+	switch {
+	case m[1] != "" && grids == 1:
+		return 0, fmt.Errorf("%w: %q names a grid, but the board has only one", ErrBadNotation, text)
+	case m[1] == "" && grids > 1:
+		return 0, fmt.Errorf("%w: %q needs a grid number", ErrBadNotation, text)
+	case m[1] != "":
+		grid, _ = strconv.Atoi(m[1])
+	}
+	file := int(m[2][0] - 'a')
+	rank, _ := strconv.Atoi(m[3])
+	if grid < 1 || grid > grids || file >= cols || rank < 1 || rank > rows {
+		return 0, fmt.Errorf("%w: %q is off the board", ErrBadNotation, text)
+	}
+	cell := (grid-1)*cols*rows + (rows-rank)*cols + file
+
+	move := cell
+	if g, ok := s.(*variant.Grid); ok && g.Options().Gravity {
+		if g.Drop(file) != cell {
+			return 0, fmt.Errorf("%w: %q is not where a mark dropped there would land", ErrBadNotation, text)
+		}
+		move = file
+	}
+	c, chooses := s.(variant.MarkChooser)
+	switch {
+	case chooses && m[4] == "":
+		return 0, fmt.Errorf("%w: %q needs =X or =O", ErrBadNotation, text)
+	case chooses:
+		move = c.MoveFor(cell, rune(m[4][0]))
+	case m[4] != "":
+		return 0, fmt.Errorf("%w: %q chooses a mark, but this variant doesn't", ErrBadNotation, text)
+	}
+	if !slices.Contains(s.Moves(), move) {
+		return 0, fmt.Errorf("%w: %s", variant.ErrIllegalMove, text)
+	}
+	return move, nil
+}
+
+// Position notation
+//
+// A classic position is written in the manner of chess's FEN: the rows
+// from the top, separated by slashes, each cell x or o and each run of
+// empty cells its length; then the side to move and the variant:
+//
+//	x1o/1x1/3 o classic
+//
+// The variant may be left off when reading. Other variants can't be set up
+// from a position; their games are replayed from the start.
+
+var ErrBadPosition = errors.New("bad position")
+
+// ParsePosition reads a position in position notation. The position must
+// be one that can come up in play; see Position.Validate.
+func ParsePosition(s string) (Position, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 2 || len(fields) > 3 {
+		return Position{}, fmt.Errorf("%w: %q: want the board, the side to move and the variant", ErrBadPosition, s)
+	}
+	if len(fields) == 3 && fields[2] != "classic" {
+		return Position{}, fmt.Errorf("%w: only classic positions can be set up, not %s", ErrBadPosition, fields[2])
+	}
+
+	rows := strings.Split(strings.ToLower(fields[0]), "/")
+	if len(rows) != DIM {
+		return Position{}, fmt.Errorf("%w: %d rows, want %d", ErrBadPosition, len(rows), DIM)
+	}
+	var board strings.Builder
+	for i, row := range rows {
+		n := 0
+		for _, c := range row {
+			switch {
+			case c == 'x' || c == 'o':
+				board.WriteRune(c)
+				n++
+			case c >= '1' && c <= '9':
+				board.WriteString(strings.Repeat(" ", int(c-'0')))
+				n += int(c - '0')
+			default:
+				return Position{}, fmt.Errorf("%w: row %d: %q is not x, o or a number of empty cells", ErrBadPosition, i+1, c)
+			}
+		}
+		if n != DIM {
+			return Position{}, fmt.Errorf("%w: row %d has %d cells, want %d", ErrBadPosition, i+1, n, DIM)
+		}
+	}
+
+	p := Position{Turn: strings.ToLower(fields[1]), Board: board.String()}
+	if err := p.Validate(); err != nil {
+		return Position{}, err
+	}
+	return p, nil
+}
+
+// Validate reports why p could not come up in play, if it couldn't: a
+// board of the wrong size or with stray characters, a side to move that
+// isn't x or o, mark counts that don't fit the side to move, or three in a
+// row for the side to move or for both sides.
+func (p Position) Validate() error {
+	if len(p.Board) != SIZE {
+		return fmt.Errorf("%w: %d cells, want %d", ErrBadPosition, len(p.Board), SIZE)
+	}
+	for i, c := range p.Board {
+		if c != 'x' && c != 'o' && c != ' ' {
+			return fmt.Errorf("%w: cell %d holds %q, want x, o or a space", ErrBadPosition, i+1, c)
+		}
+	}
+	if p.Turn != "x" && p.Turn != "o" {
+		return fmt.Errorf("%w: side to move is %q, want x or o", ErrBadPosition, p.Turn)
+	}
+
+	xs, os := strings.Count(p.Board, "x"), strings.Count(p.Board, "o")
+	switch {
+	case xs != os && xs != os+1:
+		return fmt.Errorf("%w: x has %d marks and o %d, but x moves first so has as many or one more", ErrBadPosition, xs, os)
+	case xs == os && p.Turn != "x":
+		return fmt.Errorf("%w: both sides have %d marks, so x is to move", ErrBadPosition, xs)
+	case xs == os+1 && p.Turn != "o":
+		return fmt.Errorf("%w: x has a mark more than o, so o is to move", ErrBadPosition)
+	}
+
+	xWins, oWins := p.IsWinFor("x"), p.IsWinFor("o")
+	switch {
+	case xWins && oWins:
+		return fmt.Errorf("%w: both x and o have three in a row", ErrBadPosition)
+	case xWins && p.Turn == "x":
+		return fmt.Errorf("%w: x has three in a row, but o moved last", ErrBadPosition)
+	case oWins && p.Turn == "o":
+		return fmt.Errorf("%w: o has three in a row, but x moved last", ErrBadPosition)
+	}
+	return nil
+}
+
+// Notation writes p in position notation.
+func (p Position) Notation() string {
+	var b strings.Builder
+	for row := range DIM {
+		if row > 0 {
+			b.WriteByte('/')
+		}
+		empty := 0
+		for _, c := range p.Board[row*DIM : row*DIM+DIM] {
+			if c == ' ' {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				b.WriteString(strconv.Itoa(empty))
+				empty = 0
+			}
+			b.WriteRune(c)
+		}
+		if empty > 0 {
+			b.WriteString(strconv.Itoa(empty))
+		}
+	}
+	return b.String() + " " + p.Turn + " classic"
+}
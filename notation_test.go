@@ -0,0 +1,290 @@
+package ttt_test
+
+import (
+	"errors"
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	ttt "github.com/jwc20/ssh-ttt"
+	"github.com/jwc20/ssh-ttt/variant"
+	"github.com/stretchr/testify/assert"
+)
+
+const sampleGame = `[Variant "classic"]
+[Date "2026.10.19"]
+[X "alice"]
+[O "bob"]
+[Result "1-0"]
+[Event "club night"]
+
+1. b2 b3 2. c3 a1 3. c1 a3 4. c2 1-0
+`
+
+func TestParseGame(t *testing.T) {
+	t.Run("reads tags and moves", func(t *testing.T) {
+		r, err := ttt.ParseGame(sampleGame)
+		assert.NoError(t, err)
+		assert.Equal(t, ttt.GameRecord{
+			Variant: "classic",
+			Date:    "2026.10.19",
+			PlayerX: "alice",
+			PlayerO: "bob",
+			Result:  ttt.ResultXWins,
+			Moves:   []int{4, 1, 2, 6, 8, 0, 5},
+			Tags:    []ttt.Tag{{Name: "Event", Value: "club night"}},
+		}, r)
+	})
+
+	t.Run("move numbers are optional", func(t *testing.T) {
+		r, err := ttt.ParseGame("[Variant \"classic\"]\n\nb2 b3 c3")
+		assert.NoError(t, err)
+		assert.Equal(t, []int{4, 1, 2}, r.Moves)
+		assert.Equal(t, ttt.ResultOngoing, r.Result)
+		assert.Equal(t, "", r.PlayerX)
+	})
+
+	t.Run("unfinished games keep their result", func(t *testing.T) {
+		r, err := ttt.ParseGame("[Variant \"classic\"]\n[Result \"0-1\"]\n\n1. b2 b3 0-1")
+		assert.NoError(t, err)
+		assert.Equal(t, ttt.ResultOWins, r.Result)
+	})
+
+	errs := map[string]string{
+		"no variant":       "1. b2 *",
+		"unknown variant":  "[Variant \"chess\"]\n\n*",
+		"bad tag":          "[Variant classic]\n\n*",
+		"bad result tag":   "[Variant \"classic\"]\n[Result \"2-0\"]\n\n*",
+		"bad board":        "[Variant \"mnk\"]\n[Board \"huge\"]\n\n*",
+		"cell taken":       "[Variant \"classic\"]\n\n1. b2 b2",
+		"off the board":    "[Variant \"classic\"]\n\n1. d4",
+		"not a move":       "[Variant \"classic\"]\n\n1. centre",
+		"wrong result":     "[Variant \"classic\"]\n\n1. b2 b3 2. c3 a1 3. c1 a3 4. c2 0-1",
+		"results disagree": "[Variant \"classic\"]\n[Result \"1-0\"]\n\n1. b2 1/2-1/2",
+		"moves after end":  "[Variant \"classic\"]\n\n1. b2 * b3",
+		"game over":        "[Variant \"classic\"]\n\n1. b2 b3 2. c3 a1 3. c1 a3 4. c2 b1",
+	}
+	for name, text := range errs {
+		t.Run(name, func(t *testing.T) {
+			_, err := ttt.ParseGame(text)
+			if !errors.Is(err, ttt.ErrBadNotation) && !errors.Is(err, variant.ErrIllegalMove) {
+				t.Errorf("got %v, want a notation error", err)
+			}
+		})
+	}
+}
+
+func TestMoveNotation(t *testing.T) {
+	cases := []struct {
+		variant string
+		board   variant.Options
+		setup   []int
+		move    int
+		want    string
+	}{
+		{"classic", variant.Options{}, nil, 0, "a3"},
+		{"classic", variant.Options{}, nil, 4, "b2"},
+		{"classic", variant.Options{}, nil, 8, "c1"},
+		{"ultimate", variant.Options{}, nil, 40, "5:b2"},
+		{"ultimate", variant.Options{}, []int{40}, 36, "5:a3"},
+		{"qubic4", variant.Options{}, nil, 63, "4:d1"},
+		{"notakto", variant.Options{}, nil, 9, "2:a3"},
+		{"wild", variant.Options{}, nil, 9, "a3=O"},
+		{"wild", variant.Options{}, nil, 8, "c1=X"},
+		{"order-chaos", variant.Options{}, nil, 36 + 35, "f1=O"},
+		{"mnk", variant.ConnectFour, nil, 3, "d1"},
+		{"mnk", variant.ConnectFour, []int{3}, 3, "d2"},
+		{"mnk", variant.Options{Cols: 10, Rows: 10, InARow: 5}, nil, 9, "j10"},
+	}
+	for _, c := range cases {
+		t.Run(c.want, func(t *testing.T) {
+			s, err := variant.NewWith(c.variant, c.board)
+			assert.NoError(t, err)
+			assert.NoError(t, variant.Replay(s, c.setup))
+			assert.Equal(t, c.want, ttt.FormatMove(s, c.move))
+			move, err := ttt.ParseMove(s, c.want)
+			assert.NoError(t, err)
+			assert.Equal(t, c.move, move)
+		})
+	}
+
+	t.Run("gravity moves must land where they say", func(t *testing.T) {
+		s := variant.NewGrid(variant.ConnectFour)
+		_, err := ttt.ParseMove(s, "d2")
+		assert.ErrorIs(t, err, ttt.ErrBadNotation)
+	})
+
+	t.Run("grids and marks are checked", func(t *testing.T) {
+		_, err := ttt.ParseMove(variant.NewUltimate(), "b2")
+		assert.ErrorIs(t, err, ttt.ErrBadNotation)
+		_, err = ttt.ParseMove(variant.NewClassic(), "1:b2")
+		assert.ErrorIs(t, err, ttt.ErrBadNotation)
+		_, err = ttt.ParseMove(variant.NewWild(), "b2")
+		assert.ErrorIs(t, err, ttt.ErrBadNotation)
+		_, err = ttt.ParseMove(variant.NewClassic(), "b2=X")
+		assert.ErrorIs(t, err, ttt.ErrBadNotation)
+		_, err = ttt.ParseMove(variant.NewUltimate(), "10:b2")
+		assert.ErrorIs(t, err, ttt.ErrBadNotation)
+	})
+}
+
+func TestNotationRoundTrip(t *testing.T) {
+	t.Run("sample", func(t *testing.T) {
+		r, err := ttt.ParseGame(sampleGame)
+		assert.NoError(t, err)
+		text, err := r.MarshalText()
+		assert.NoError(t, err)
+		assert.Equal(t, sampleGame, string(text))
+	})
+
+	for _, info := range variant.All() {
+		t.Run(info.Name, func(t *testing.T) {
+			for i := range 20 {
+				rng := rand.New(rand.NewPCG(uint64(i), 1))
+				r := ttt.GameRecord{Variant: info.Name, PlayerX: "alice", PlayerO: `"quoted" \ name`}
+				if info.Configure != nil && i%2 == 1 {
+					r.Board = variant.Options{Cols: 5, Rows: 4, InARow: 3, Gravity: i%4 == 1}
+				}
+				s, err := r.NewState()
+				assert.NoError(t, err)
+				for n := rng.IntN(30); n > 0 && !s.Over(); n-- {
+					moves := s.Moves()
+					m := moves[rng.IntN(len(moves))]
+					s.Play(m)
+					r.Moves = append(r.Moves, m)
+				}
+				r.Result = ttt.ResultOf(s)
+
+				text, err := r.MarshalText()
+				assert.NoError(t, err)
+				var back ttt.GameRecord
+				if err := back.UnmarshalText(text); err != nil {
+					t.Fatalf("%v\n%s", err, text)
+				}
+				assert.Equal(t, r, back)
+				again, _ := back.MarshalText()
+				assert.Equal(t, string(text), string(again))
+			}
+		})
+	}
+
+	t.Run("long games wrap", func(t *testing.T) {
+		// X takes the even columns and O the odd ones, four rows deep.
+		r := ttt.GameRecord{Variant: "mnk", Board: variant.Options{Cols: 10, Rows: 10, InARow: 5}}
+		for cell := range 40 {
+			r.Moves = append(r.Moves, cell)
+		}
+		_, err := r.State()
+		assert.NoError(t, err)
+		text, err := r.MarshalText()
+		assert.NoError(t, err)
+		for _, line := range strings.Split(string(text), "\n") {
+			if len(line) > 79 {
+				t.Errorf("line too long: %q", line)
+			}
+		}
+	})
+}
+
+func TestParsePosition(t *testing.T) {
+	t.Run("reads the board, side to move and variant", func(t *testing.T) {
+		for text, want := range map[string]ttt.Position{
+			"3/3/3 x":             {Turn: "x", Board: "         "},
+			"x1o/1x1/3 o classic": {Turn: "o", Board: "x o x    "},
+			"X1O/1X1/2O X":        {Turn: "x", Board: "x o x   o"},
+			"  xox/oxx/oxo   o  ": {Turn: "o", Board: "xoxoxxoxo"},
+		} {
+			got, err := ttt.ParsePosition(text)
+			assert.NoError(t, err, text)
+			assert.Equal(t, want, got, text)
+		}
+	})
+
+	t.Run("every position in play round trips", func(t *testing.T) {
+		seen := map[string]bool{}
+		var walk func(p ttt.Position)
+		walk = func(p ttt.Position) {
+			if seen[p.String()] {
+				return
+			}
+			seen[p.String()] = true
+			assert.NoError(t, p.Validate(), p.String())
+			got, err := ttt.ParsePosition(p.Notation())
+			assert.NoError(t, err, p.Notation())
+			assert.Equal(t, p, got)
+			if p.IsGameEnd() {
+				return
+			}
+			for _, m := range p.PossibleMoves() {
+				walk(*p.Copy().Move(m))
+			}
+		}
+		walk(*ttt.InitPosition())
+		assert.Equal(t, 5478, len(seen))
+	})
+
+	errs := map[string]string{
+		"empty":              "",
+		"no side to move":    "3/3/3",
+		"extra field":        "3/3/3 x classic now",
+		"other variant":      "3/3/3 x qubic3",
+		"two rows":           "3/3 x",
+		"four rows":          "3/3/3/3 x",
+		"short row":          "x1/3/3 o",
+		"long row":           "xo2/3/3 x",
+		"bad character":      "x-o/3/3 x",
+		"bad side":           "3/3/3 z",
+		"too many o":         "o2/3/3 x",
+		"too many x":         "xx1/3/3 o",
+		"wrong side for x":   "x2/3/3 x",
+		"wrong side for o":   "xo1/3/3 o",
+		"both win":           "xxx/ooo/3 x",
+		"winner moves again": "xxx/oo1/o2 x",
+		"o wins, o to move":  "ooo/xx1/x1x o",
+	}
+	for name, text := range errs {
+		t.Run(name, func(t *testing.T) {
+			_, err := ttt.ParsePosition(text)
+			assert.ErrorIs(t, err, ttt.ErrBadPosition)
+		})
+	}
+
+	t.Run("positions built by hand are checked too", func(t *testing.T) {
+		for _, p := range []ttt.Position{
+			{Turn: "x", Board: "   "},
+			{Turn: "x", Board: "x?o      "},
+			{Turn: "", Board: "         "},
+		} {
+			assert.ErrorIs(t, p.Validate(), ttt.ErrBadPosition)
+		}
+	})
+}
+
+func TestSetUpGames(t *testing.T) {
+	const text = `[Variant "classic"]
+[Position "x1o/1x1/3 o classic"]
+[Date "????.??.??"]
+[X "?"]
+[O "?"]
+[Result "1-0"]
+
+1... a1 2. c1 1-0
+`
+	r, err := ttt.ParseGame(text)
+	assert.NoError(t, err)
+	assert.Equal(t, &ttt.Position{Turn: "o", Board: "x o x    "}, r.Setup)
+	assert.Equal(t, []int{6, 8}, r.Moves)
+	again, err := r.MarshalText()
+	assert.NoError(t, err)
+	assert.Equal(t, text, string(again))
+
+	for name, text := range map[string]string{
+		"bad position":  "[Variant \"classic\"]\n[Position \"x1o/1x1/3 x\"]\n\n*",
+		"other variant": "[Variant \"ultimate\"]\n[Position \"x1o/1x1/3 o\"]\n\n*",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ttt.ParseGame(text)
+			assert.ErrorIs(t, err, ttt.ErrBadPosition)
+		})
+	}
+}
@@ -4,6 +4,12 @@ import (
 	"fmt"
 	"math"
 	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/jwc20/ssh-ttt/metrics"
+	"github.com/jwc20/ssh-ttt/variant"
 )
 
 const (
@@ -30,7 +36,12 @@ func (p Position) choose(x, o string) string {
 	return o
 }
 
+// Move plays the side to move on cell i, counting from 0, and returns p. A
+// cell that is off the board or already taken leaves p unchanged.
 func (p *Position) Move(i int) *Position {
+	if i < 0 || i >= len(p.Board) || p.Board[i] != ' ' {
+		return p
+	}
 	p.Board = p.Board[:i] + p.Turn + p.Board[i+1:]
 	p.Turn = p.choose("o", "x")
 	return p
@@ -91,7 +102,11 @@ type cacheKey struct {
 	turn  string
 }
 
-var minimaxCache = map[cacheKey]int{}
+// minimaxCache is shared by every search, so searches hold minimaxMu.
+var (
+	minimaxMu    sync.Mutex
+	minimaxCache = map[cacheKey]int{}
+)
 
 func (p *Position) cacheKey() cacheKey {
 	return cacheKey{p.Board, p.Turn}
@@ -151,11 +166,45 @@ func (p *Position) Copy() *Position {
 	}
 }
 
+// State is p in the variant package's terms, for the engines. It fails
+// with the error from Validate if p is not a valid position.
+func (p Position) State() (variant.State, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	var cells [SIZE]rune
+	for i, c := range p.Board {
+		cells[i] = unicode.ToUpper(c)
+	}
+	return variant.ClassicPosition(cells, unicode.ToUpper(rune(p.Turn[0]))), nil
+}
+
 func (p Position) String() string {
 	return fmt.Sprintf("%s.%s", p.Turn, p.Board)
 }
 
+var aiSearchDuration = metrics.Default.NewHistogramVec("ttt_ai_search_seconds",
+	"Time the computer player spends choosing a move.", metrics.DefBuckets, "engine")
+
+// BestMove plays perfectly. Positions that can come up in play are looked
+// up in the tablebase; anything else is searched.
 func (p Position) BestMove() int {
+	start := time.Now()
+	if evals := p.MoveEvals(); evals != nil {
+		defer aiSearchDuration.With("tablebase").ObserveSince(start)
+		best := evals[0]
+		for _, e := range evals[1:] {
+			if e.Better(best) {
+				best = e
+			}
+		}
+		return best.Move
+	}
+
+	defer aiSearchDuration.With("minimax").ObserveSince(start)
+	minimaxMu.Lock()
+	defer minimaxMu.Unlock()
+
 	bestIdx := -1
 	var bestVal int
 
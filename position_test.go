@@ -39,6 +39,34 @@ func TestPosition(t *testing.T) {
 		assert.Equal(t, ttt.InitPosition().PossibleMoves(), []int{0, 1, 2, 3, 4, 5, 6, 7, 8})
 		assert.Equal(t, ttt.InitPosition().Move(1).PossibleMoves(), []int{0, 2, 3, 4, 5, 6, 7, 8})
 	})
+
+	t.Run("test Move ignores cells off the board or taken", func(t *testing.T) {
+		for _, i := range []int{-1, 9, 100, 4} {
+			position := ttt.Position{Turn: "o", Board: "    x    "}
+			assertPositionEqual(t, *position.Move(i), ttt.Position{Turn: "o", Board: "    x    "})
+		}
+		short := ttt.Position{Turn: "x", Board: "  "}
+		assertPositionEqual(t, *short.Move(5), ttt.Position{Turn: "x", Board: "  "})
+	})
+
+	t.Run("test State", func(t *testing.T) {
+		s, err := ttt.Position{Turn: "o", Board: "    x    "}.State()
+		assert.NoError(t, err)
+		assert.Equal(t, 'O', s.Turn())
+		assert.Equal(t, "    X    ", string(s.Cells()))
+	})
+
+	t.Run("test State rejects invalid positions", func(t *testing.T) {
+		for _, p := range []ttt.Position{
+			{},
+			{Turn: "", Board: "         "},
+			{Turn: "x", Board: "xx"},
+			{Turn: "x", Board: "    x    "},
+		} {
+			_, err := p.State()
+			assert.ErrorIs(t, err, ttt.ErrBadPosition, "position %q", p.String())
+		}
+	})
 }
 
 func TestIsWinFor(t *testing.T) {
@@ -73,6 +101,76 @@ func TestBestMove(t *testing.T) {
 	})
 }
 
+func TestTablebase(t *testing.T) {
+	t.Run("test start is a draw", func(t *testing.T) {
+		outcome, plies, ok := ttt.InitPosition().Solved()
+		assert.True(t, ok)
+		assert.Equal(t, ttt.Draw, outcome)
+		assert.Equal(t, 9, plies)
+	})
+
+	t.Run("test move evals", func(t *testing.T) {
+		evals := ttt.Position{Board: "xx oo    ", Turn: "x"}.MoveEvals()
+		assert.Equal(t, []int{2, 5, 6, 7, 8}, movesOf(evals))
+		assert.Equal(t, "win in 1", evals[0].String())
+		assert.Equal(t, "loss in 2", evals[2].String())
+	})
+
+	t.Run("test symmetric positions agree", func(t *testing.T) {
+		corner := ttt.Position{Board: "x        ", Turn: "o"}.MoveEvals()
+		rotated := ttt.Position{Board: "  x      ", Turn: "o"}.MoveEvals()
+		assert.Equal(t, "draw", corner[3].String())
+		assert.Equal(t, "loss in 6", corner[0].String())
+		// The rotation takes cell 1 to cell 5.
+		assert.Equal(t, corner[0], ttt.MoveEval{Move: 1, Outcome: rotated[4].Outcome, Plies: rotated[4].Plies})
+	})
+
+	t.Run("test unreachable positions are not in the table", func(t *testing.T) {
+		_, _, ok := ttt.Position{Board: "xx       ", Turn: "x"}.Solved()
+		assert.False(t, ok)
+		assert.Nil(t, ttt.Position{Board: "xx       ", Turn: "x"}.MoveEvals())
+	})
+
+	t.Run("test finished games have no moves", func(t *testing.T) {
+		p := ttt.Position{Board: "xxxoo    ", Turn: "o"}
+		outcome, plies, ok := p.Solved()
+		assert.True(t, ok)
+		assert.Equal(t, ttt.Loss, outcome)
+		assert.Equal(t, 0, plies)
+		assert.Nil(t, p.MoveEvals())
+	})
+
+	t.Run("test best move never loses", func(t *testing.T) {
+		for _, side := range []string{"x", "o"} {
+			var play func(p ttt.Position)
+			play = func(p ttt.Position) {
+				if p.IsGameEnd() {
+					if p.IsWinFor(map[string]string{"x": "o", "o": "x"}[side]) {
+						t.Fatalf("best move lost as %s: %q", side, p.Board)
+					}
+					return
+				}
+				if p.Turn == side {
+					play(*p.Copy().Move(p.BestMove()))
+					return
+				}
+				for _, idx := range p.PossibleMoves() {
+					play(*p.Copy().Move(idx))
+				}
+			}
+			play(*ttt.InitPosition())
+		}
+	})
+}
+
+func movesOf(evals []ttt.MoveEval) []int {
+	var moves []int
+	for _, e := range evals {
+		moves = append(moves, e.Move)
+	}
+	return moves
+}
+
 func TestIsGameEnd(t *testing.T) {
 	t.Run("test not end", func(t *testing.T) {
 		assert.False(t, ttt.InitPosition().IsGameEnd())
@@ -0,0 +1,125 @@
+package ttt
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jwc20/ssh-ttt/variant"
+)
+
+// Qubic is the 3D game for the CLI. Moves are entered as coordinates and
+// the layers are printed side by side.
+type Qubic struct {
+	store PlayerStore
+	size  int
+	board *variant.Qubic
+	moves []int
+}
+
+// NewQubic returns a game on a size×size×size cube; size is 3 or 4.
+func NewQubic(store PlayerStore, size int) *Qubic {
+	return &Qubic{store: store, size: size}
+}
+
+func (g *Qubic) Start(numberOfPlayers int) {
+	g.board = variant.NewQubic(g.size)
+	g.moves = nil
+}
+
+func (g *Qubic) Finish(winner string) {
+	g.store.RecordWin(winner)
+}
+
+// Size is the number of layers, rows and columns.
+func (g *Qubic) Size() int { return g.size }
+
+// ParseMove reads "layer,row,column", each counted from 1, and returns the
+// position MakeMove takes. Spaces work as separators too.
+func (g *Qubic) ParseMove(input string) (int, error) {
+	fields := strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == ' ' })
+	if len(fields) != 3 {
+		return 0, errors.New("want three coordinates")
+	}
+	pos := 0
+	for _, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 1 || n > g.size {
+			return 0, fmt.Errorf("coordinate %q out of range", f)
+		}
+		pos = pos*g.size + n - 1
+	}
+	return pos, nil
+}
+
+func (g *Qubic) MakeMove(position int) error {
+	if err := g.board.Play(position); err != nil {
+		return err
+	}
+	g.moves = append(g.moves, position)
+	return nil
+}
+
+func (g *Qubic) Board() string {
+	n := g.size
+	cells := g.board.Cells()
+	rule := strings.Repeat("-", n*4-1)
+
+	var header strings.Builder
+	for l := range n {
+		header.WriteString(fmt.Sprintf("%-*s", n*4+2, fmt.Sprintf("Layer %d", l+1)))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(strings.TrimRight(header.String(), " ") + "\n")
+	for r := range n {
+		for l := range n {
+			if l > 0 {
+				sb.WriteString("   ")
+			}
+			row := make([]string, n)
+			for c := range n {
+				row[c] = string(cells[(l*n+r)*n+c])
+			}
+			sb.WriteString(" " + strings.Join(row, " | ") + " ")
+		}
+		sb.WriteString("\n")
+		if r < n-1 {
+			sb.WriteString(strings.TrimRight(strings.Repeat(rule+"   ", n), " ") + "\n")
+		}
+	}
+	return sb.String()
+}
+
+func (g *Qubic) CurrentPlayer() string {
+	return string(g.board.Turn())
+}
+
+func (g *Qubic) IsOver() bool {
+	return g.board.Over()
+}
+
+func (g *Qubic) Winner() string {
+	if w := g.board.Winner(); w != variant.Empty {
+		return string(w)
+	}
+	return ""
+}
+
+// State is a copy of the board for an engine to search. It never fails.
+func (g *Qubic) State() (variant.State, error) {
+	return g.board.Clone(), nil
+}
+
+// PlayMove is MakeMove: engines and players number the cells the same way.
+func (g *Qubic) PlayMove(move int) error {
+	return g.MakeMove(move)
+}
+
+// Variant is the registry name of the game.
+func (g *Qubic) Variant() string { return fmt.Sprintf("qubic%d", g.size) }
+
+// Moves are the cells played so far.
+func (g *Qubic) Moves() []int { return slices.Clone(g.moves) }
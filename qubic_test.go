@@ -0,0 +1,78 @@
+package ttt_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	ttt "github.com/jwc20/ssh-ttt"
+)
+
+func TestQubic_ParseMove(t *testing.T) {
+	game := ttt.NewQubic(dummyPlayerStore, 4)
+
+	cases := map[string]int{
+		"1,1,1":   0,
+		"1,1,2":   1,
+		"2,1,1":   16,
+		"4 4 4":   63,
+		" 3, 2,1": 36,
+	}
+	for input, want := range cases {
+		got, err := game.ParseMove(input)
+		if err != nil || got != want {
+			t.Errorf("ParseMove(%q) = %d, %v; want %d", input, got, err, want)
+		}
+	}
+
+	for _, input := range []string{"", "1,1", "1,1,1,1", "0,1,1", "1,5,1", "a,b,c"} {
+		if _, err := game.ParseMove(input); err == nil {
+			t.Errorf("ParseMove(%q): expected an error", input)
+		}
+	}
+}
+
+func TestQubic_Board(t *testing.T) {
+	game := ttt.NewQubic(dummyPlayerStore, 3)
+	game.Start(2)
+	game.MakeMove(0)
+	game.MakeMove(13)
+
+	want := "" +
+		"Layer 1       Layer 2       Layer 3\n" +
+		" X |   |         |   |         |   |   \n" +
+		"-----------   -----------   -----------\n" +
+		"   |   |         | O |         |   |   \n" +
+		"-----------   -----------   -----------\n" +
+		"   |   |         |   |         |   |   \n"
+	if got := game.Board(); got != want {
+		t.Errorf("got board\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestQubic_CLI(t *testing.T) {
+	t.Run("plays a space diagonal to a win", func(t *testing.T) {
+		store := &ttt.StubPlayerStore{}
+		game := ttt.NewQubic(store, 3)
+		stdout := &bytes.Buffer{}
+
+		in := userSends("1,1,1", "1,1,2", "2,2,2", "1,1,3", "3,3,3")
+		ttt.NewCLI(in, stdout, game).PlayGame()
+
+		assertOutputContains(t, stdout, fmt.Sprintf(ttt.CoordinatePrompt, "X", 3))
+		assertOutputContains(t, stdout, "Player X wins!\n")
+		ttt.AssertPlayerWin(t, store, "X")
+	})
+
+	t.Run("rejects bad coordinates and taken cells", func(t *testing.T) {
+		game := ttt.NewQubic(dummyPlayerStore, 3)
+		stdout := &bytes.Buffer{}
+
+		in := userSends("4,1,1", "1,1,1", "1,1,1", "1,1,2", "2,2,2", "1,1,3", "3,3,3")
+		ttt.NewCLI(in, stdout, game).PlayGame()
+
+		assertOutputContains(t, stdout, fmt.Sprintf(ttt.BadCoordinateErrMsg, 3))
+		assertOutputContains(t, stdout, ttt.SquareTakenErrMsg)
+		assertOutputContains(t, stdout, "Player X wins!\n")
+	})
+}
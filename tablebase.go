@@ -0,0 +1,170 @@
+package ttt
+
+import (
+	_ "embed"
+	"encoding/binary"
+	"fmt"
+)
+
+//go:generate go run tablebase_gen.go
+
+// tablebaseData is 3×3 tic-tac-toe solved: a 3-byte record for each of the
+// 765 positions that can come up in play, up to rotation and reflection.
+// Each record is the position's key, little-endian, then the outcome for
+// the side to move in the high nibble and the plies left in the low one.
+//
+//go:embed tablebase.bin
+var tablebaseData []byte
+
+// Outcome is the result of a position or move with perfect play.
+type Outcome int
+
+const (
+	Loss Outcome = iota - 1
+	Draw
+	Win
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case Win:
+		return "win"
+	case Loss:
+		return "loss"
+	}
+	return "draw"
+}
+
+// MoveEval is how a move turns out for the player making it when both
+// sides play perfectly afterwards. Plies counts the moves left in the
+// game, this one included.
+type MoveEval struct {
+	Move    int
+	Outcome Outcome
+	Plies   int
+}
+
+func (e MoveEval) String() string {
+	if e.Outcome == Draw {
+		return "draw"
+	}
+	return fmt.Sprintf("%s in %d", e.Outcome, e.Plies)
+}
+
+// Better reports whether e is a better move than other: a win sooner, a
+// loss later, and a draw between the two.
+func (e MoveEval) Better(other MoveEval) bool {
+	if e.Outcome != other.Outcome {
+		return e.Outcome > other.Outcome
+	}
+	switch e.Outcome {
+	case Win:
+		return e.Plies < other.Plies
+	case Loss:
+		return e.Plies > other.Plies
+	}
+	return false
+}
+
+type solvedPosition struct {
+	outcome Outcome
+	plies   int
+}
+
+var tablebase = func() map[uint16]solvedPosition {
+	table := make(map[uint16]solvedPosition, len(tablebaseData)/3)
+	for rec := tablebaseData; len(rec) >= 3; rec = rec[3:] {
+		table[binary.LittleEndian.Uint16(rec)] = solvedPosition{
+			outcome: Outcome(rec[2]>>4) - 1,
+			plies:   int(rec[2] & 0xf),
+		}
+	}
+	return table
+}()
+
+// symmetries are the board's rotations and reflections, each mapping a
+// cell to the cell it is read from.
+var symmetries = func() [][SIZE]int {
+	rot := [SIZE]int{6, 3, 0, 7, 4, 1, 8, 5, 2}
+	flip := [SIZE]int{2, 1, 0, 5, 4, 3, 8, 7, 6}
+	s := [SIZE]int{0, 1, 2, 3, 4, 5, 6, 7, 8}
+	var out [][SIZE]int
+	for range 4 {
+		var f [SIZE]int
+		for i := range f {
+			f[i] = s[flip[i]]
+		}
+		out = append(out, s, f)
+		var r [SIZE]int
+		for i := range r {
+			r[i] = s[rot[i]]
+		}
+		s = r
+	}
+	return out
+}()
+
+// tablebaseKey is the smallest base-3 number any symmetry of the board
+// reads as. It reports false for boards the wrong side is to move on.
+func (p Position) tablebaseKey() (uint16, bool) {
+	if len(p.Board) != SIZE {
+		return 0, false
+	}
+	var digits [SIZE]int
+	xs, ys := 0, 0
+	for i := range SIZE {
+		switch p.Board[i] {
+		case 'x':
+			digits[i] = 1
+			xs++
+		case 'o':
+			digits[i] = 2
+			ys++
+		case ' ':
+		default:
+			return 0, false
+		}
+	}
+	if !(xs == ys && p.Turn == "x" || xs == ys+1 && p.Turn == "o") {
+		return 0, false
+	}
+
+	best := uint16(0xffff)
+	for _, sym := range symmetries {
+		k := 0
+		for i := SIZE - 1; i >= 0; i-- {
+			k = k*3 + digits[sym[i]]
+		}
+		best = min(best, uint16(k))
+	}
+	return best, true
+}
+
+// Solved looks p up in the tablebase: the outcome for the side to move and
+// the plies left with perfect play. ok is false for positions that cannot
+// come up in play.
+func (p Position) Solved() (outcome Outcome, plies int, ok bool) {
+	key, ok := p.tablebaseKey()
+	if !ok {
+		return Draw, 0, false
+	}
+	s, ok := tablebase[key]
+	return s.outcome, s.plies, ok
+}
+
+// MoveEvals rates every legal move in p, in board order. It returns nil
+// when the game is over or p cannot come up in play.
+func (p Position) MoveEvals() []MoveEval {
+	if p.IsGameEnd() {
+		return nil
+	}
+	if _, _, ok := p.Solved(); !ok {
+		return nil
+	}
+	var evals []MoveEval
+	for _, idx := range p.PossibleMoves() {
+		outcome, plies, _ := p.Copy().Move(idx).Solved()
+		evals = append(evals, MoveEval{Move: idx, Outcome: -outcome, Plies: plies + 1})
+	}
+	return evals
+}
@@ -0,0 +1,129 @@
+//go:build ignore
+
+// tablebase_gen solves 3×3 tic-tac-toe and writes tablebase.bin, the table
+// Position.Solved reads. Run it with go generate.
+package main
+
+import (
+	"encoding/binary"
+	"log"
+	"os"
+	"slices"
+)
+
+const (
+	loss = iota
+	draw
+	win
+)
+
+var lines = [][3]int{
+	{0, 1, 2}, {3, 4, 5}, {6, 7, 8},
+	{0, 3, 6}, {1, 4, 7}, {2, 5, 8},
+	{0, 4, 8}, {2, 4, 6},
+}
+
+// symmetries map each cell of the board to the cell it is read from.
+var symmetries = func() [][9]int {
+	rot := [9]int{6, 3, 0, 7, 4, 1, 8, 5, 2}
+	flip := [9]int{2, 1, 0, 5, 4, 3, 8, 7, 6}
+	s := [9]int{0, 1, 2, 3, 4, 5, 6, 7, 8}
+	var out [][9]int
+	for range 4 {
+		var f [9]int
+		for i := range f {
+			f[i] = s[flip[i]]
+		}
+		out = append(out, s, f)
+		var r [9]int
+		for i := range r {
+			r[i] = s[rot[i]]
+		}
+		s = r
+	}
+	return out
+}()
+
+type result struct{ outcome, plies int }
+
+var solved = map[uint16]result{}
+
+func key(board [9]byte) uint16 {
+	best := uint16(0xffff)
+	for _, sym := range symmetries {
+		k := 0
+		for i := 8; i >= 0; i-- {
+			k = k*3 + int(board[sym[i]])
+		}
+		best = min(best, uint16(k))
+	}
+	return best
+}
+
+// better reports whether a is a better result than b for the side to move:
+// a win sooner, or a loss later.
+func better(a, b result) bool {
+	if a.outcome != b.outcome {
+		return a.outcome > b.outcome
+	}
+	switch a.outcome {
+	case win:
+		return a.plies < b.plies
+	case loss:
+		return a.plies > b.plies
+	}
+	return false
+}
+
+// solve returns the result for the side to move, which places mark (1 for
+// x, 2 for o).
+func solve(board [9]byte, mark byte) result {
+	k := key(board)
+	if r, ok := solved[k]; ok {
+		return r
+	}
+	r := result{outcome: draw}
+	for _, l := range lines {
+		if board[l[0]] != 0 && board[l[0]] == board[l[1]] && board[l[1]] == board[l[2]] {
+			r = result{outcome: loss}
+		}
+	}
+	if r.outcome == draw && slices.Contains(board[:], 0) {
+		first := true
+		for i := range board {
+			if board[i] != 0 {
+				continue
+			}
+			next := board
+			next[i] = mark
+			child := solve(next, 3-mark)
+			mine := result{outcome: 2 - child.outcome, plies: child.plies + 1}
+			if first || better(mine, r) {
+				r, first = mine, false
+			}
+		}
+	}
+	solved[k] = r
+	return r
+}
+
+func main() {
+	solve([9]byte{}, 1)
+
+	keys := make([]uint16, 0, len(solved))
+	for k := range solved {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	var out []byte
+	for _, k := range keys {
+		r := solved[k]
+		out = binary.LittleEndian.AppendUint16(out, k)
+		out = append(out, byte(r.outcome<<4|r.plies))
+	}
+	if err := os.WriteFile("tablebase.bin", out, 0o644); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("wrote %d positions", len(keys))
+}
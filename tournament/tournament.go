@@ -0,0 +1,534 @@
+// Package tournament runs single-elimination, double-elimination and
+// round-robin events. It only tracks pairings and results; creating rooms
+// for ready matches is up to the caller. A Tournament is not safe for
+// concurrent use.
+package tournament
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+type Format string
+
+const (
+	SingleElimination Format = "single"
+	DoubleElimination Format = "double"
+	RoundRobin        Format = "round-robin"
+)
+
+// Formats lists the supported formats in the order the lobby cycles them.
+var Formats = []Format{SingleElimination, DoubleElimination, RoundRobin}
+
+func (f Format) String() string {
+	switch f {
+	case SingleElimination:
+		return "single elimination"
+	case DoubleElimination:
+		return "double elimination"
+	case RoundRobin:
+		return "round robin"
+	}
+	return string(f)
+}
+
+type Status string
+
+const (
+	Registering Status = "registering"
+	Running     Status = "running"
+	Finished    Status = "finished"
+)
+
+// Bracket names the part of a double-elimination event a match belongs to.
+// Single-elimination matches are all in the winners bracket and round-robin
+// matches have no bracket.
+type Bracket string
+
+const (
+	Winners    Bracket = "winners"
+	Losers     Bracket = "losers"
+	GrandFinal Bracket = "grand-final"
+)
+
+const MinPlayers = 2
+
+var (
+	ErrNotRegistering = errors.New("registration is closed")
+	ErrNotRunning     = errors.New("the tournament is not running")
+	ErrRegistered     = errors.New("already registered")
+	ErrNotRegistered  = errors.New("not registered")
+	ErrTooFewPlayers  = fmt.Errorf("at least %d players are needed", MinPlayers)
+	ErrNoSuchMatch    = errors.New("no such match")
+	ErrMatchNotReady  = errors.New("match is not ready to be played")
+	ErrNotInMatch     = errors.New("winner is not playing in this match")
+)
+
+// Slot is one side of a match. A slot fed by another match stays empty
+// until that match is decided; a filled slot with no player is a bye.
+type Slot struct {
+	Player string `json:"player"`
+	Filled bool   `json:"filled"`
+	// From and TakeLoser describe where the player comes from: the winner
+	// (or loser) of match From. Zero means seeded.
+	From      int  `json:"from,omitempty"`
+	TakeLoser bool `json:"take_loser,omitempty"`
+}
+
+type Match struct {
+	ID      int     `json:"id"`
+	Bracket Bracket `json:"bracket,omitempty"`
+	Round   int     `json:"round"`
+	Slots   [2]Slot `json:"slots"`
+	// Room is the game room assigned to the match while it is played.
+	Room    string `json:"room,omitempty"`
+	Done    bool   `json:"done"`
+	Winner  string `json:"winner,omitempty"`
+	Loser   string `json:"loser,omitempty"`
+	Draw    bool   `json:"draw,omitempty"`
+	Bye     bool   `json:"bye,omitempty"`
+	Replays int    `json:"replays,omitempty"`
+}
+
+// X and O are the players seated as X and O; the first slot plays X.
+func (m *Match) X() string { return m.Slots[0].Player }
+func (m *Match) O() string { return m.Slots[1].Player }
+
+func (m *Match) ready() bool {
+	return !m.Done && m.Slots[0].Filled && m.Slots[1].Filled &&
+		m.Slots[0].Player != "" && m.Slots[1].Player != ""
+}
+
+type Tournament struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Organizer string   `json:"organizer"`
+	Format    Format   `json:"format"`
+	Status    Status   `json:"status"`
+	Players   []string `json:"players"`
+	Matches   []*Match `json:"matches"`
+	Champion  string   `json:"champion,omitempty"`
+}
+
+func New(id, name, organizer string, format Format) (*Tournament, error) {
+	if !slices.Contains(Formats, format) {
+		return nil, fmt.Errorf("unknown tournament format %q", format)
+	}
+	return &Tournament{ID: id, Name: name, Organizer: organizer, Format: format, Status: Registering}, nil
+}
+
+func (t *Tournament) Register(player string) error {
+	switch {
+	case t.Status != Registering:
+		return ErrNotRegistering
+	case slices.Contains(t.Players, player):
+		return ErrRegistered
+	}
+	t.Players = append(t.Players, player)
+	return nil
+}
+
+func (t *Tournament) Unregister(player string) error {
+	if t.Status != Registering {
+		return ErrNotRegistering
+	}
+	i := slices.Index(t.Players, player)
+	if i < 0 {
+		return ErrNotRegistered
+	}
+	t.Players = slices.Delete(t.Players, i, i+1)
+	return nil
+}
+
+// Start closes registration and builds the pairings. Players are seeded in
+// registration order.
+func (t *Tournament) Start() error {
+	switch {
+	case t.Status != Registering:
+		return ErrNotRegistering
+	case len(t.Players) < MinPlayers:
+		return ErrTooFewPlayers
+	}
+
+	t.Status = Running
+	switch t.Format {
+	case RoundRobin:
+		t.buildRoundRobin()
+	default:
+		t.buildElimination(t.Format == DoubleElimination)
+	}
+	t.settleByes()
+	t.checkFinished()
+	return nil
+}
+
+// Clone returns a deep copy, e.g. to render a tournament outside the lock
+// that guards it.
+func (t *Tournament) Clone() *Tournament {
+	c := *t
+	c.Players = slices.Clone(t.Players)
+	c.Matches = make([]*Match, len(t.Matches))
+	for i, m := range t.Matches {
+		mc := *m
+		c.Matches[i] = &mc
+	}
+	return &c
+}
+
+func (t *Tournament) Match(id int) *Match {
+	if id < 1 || id > len(t.Matches) {
+		return nil
+	}
+	return t.Matches[id-1]
+}
+
+// Ready returns the matches that can be played now. Round-robin rounds are
+// played one at a time.
+func (t *Tournament) Ready() []*Match {
+	if t.Status != Running {
+		return nil
+	}
+
+	round := 0
+	if t.Format == RoundRobin {
+		for _, m := range t.Matches {
+			if !m.Done && (round == 0 || m.Round < round) {
+				round = m.Round
+			}
+		}
+	}
+
+	var ready []*Match
+	for _, m := range t.Matches {
+		if m.ready() && (round == 0 || m.Round == round) {
+			ready = append(ready, m)
+		}
+	}
+	return ready
+}
+
+// Assign records the room a ready match is being played in.
+func (t *Tournament) Assign(matchID int, room string) error {
+	m := t.Match(matchID)
+	switch {
+	case m == nil:
+		return ErrNoSuchMatch
+	case !m.ready():
+		return ErrMatchNotReady
+	}
+	m.Room = room
+	return nil
+}
+
+// Report records a result; an empty winner is a draw. Elimination matches
+// can't end in a draw, so a drawn one is replayed with colours swapped.
+func (t *Tournament) Report(matchID int, winner string) error {
+	if t.Status != Running {
+		return ErrNotRunning
+	}
+	m := t.Match(matchID)
+	switch {
+	case m == nil:
+		return ErrNoSuchMatch
+	case !m.ready():
+		return ErrMatchNotReady
+	case winner != "" && winner != m.X() && winner != m.O():
+		return ErrNotInMatch
+	}
+	m.Room = ""
+
+	if winner == "" {
+		if t.Format != RoundRobin {
+			m.Replays++
+			m.Slots[0], m.Slots[1] = m.Slots[1], m.Slots[0]
+			return nil
+		}
+		m.Done, m.Draw = true, true
+		t.checkFinished()
+		return nil
+	}
+
+	loser := m.X()
+	if winner == m.X() {
+		loser = m.O()
+	}
+	t.complete(m, winner, loser)
+
+	// The winners-bracket champion losing the grand final forces a reset.
+	if m.Bracket == GrandFinal && m.Round == 1 && t.fromLosers(m, winner) {
+		t.addMatch(GrandFinal, 2,
+			Slot{Player: winner, Filled: true, From: m.ID},
+			Slot{Player: loser, Filled: true, From: m.ID, TakeLoser: true})
+	}
+
+	t.settleByes()
+	t.checkFinished()
+	return nil
+}
+
+// fromLosers reports whether player reached grand final m through the
+// losers bracket.
+func (t *Tournament) fromLosers(m *Match, player string) bool {
+	for _, s := range m.Slots {
+		if s.Player == player {
+			return s.TakeLoser || t.Match(s.From).Bracket == Losers
+		}
+	}
+	return false
+}
+
+// ── Building ─────────────────────────────────────────────────────────────────
+
+func (t *Tournament) addMatch(bracket Bracket, round int, x, o Slot) *Match {
+	m := &Match{ID: len(t.Matches) + 1, Bracket: bracket, Round: round, Slots: [2]Slot{x, o}}
+	t.Matches = append(t.Matches, m)
+	return m
+}
+
+func winnerOf(m *Match) Slot { return Slot{From: m.ID} }
+func loserOf(m *Match) Slot  { return Slot{From: m.ID, TakeLoser: true} }
+
+// seedOrder returns bracket positions for size seeds so that the top seeds
+// meet as late as possible: 1 v 8, 4 v 5, 2 v 7, 3 v 6 for eight.
+func seedOrder(size int) []int {
+	order := []int{1}
+	for n := 2; n <= size; n *= 2 {
+		next := make([]int, 0, n)
+		for _, s := range order {
+			next = append(next, s, n+1-s)
+		}
+		order = next
+	}
+	return order
+}
+
+func (t *Tournament) buildElimination(double bool) {
+	size := 1
+	for size < len(t.Players) {
+		size *= 2
+	}
+
+	seed := func(s int) Slot {
+		if s > len(t.Players) {
+			return Slot{Filled: true}
+		}
+		return Slot{Player: t.Players[s-1], Filled: true}
+	}
+
+	var winners [][]*Match
+	order := seedOrder(size)
+	var round []*Match
+	for i := 0; i < len(order); i += 2 {
+		round = append(round, t.addMatch(Winners, 1, seed(order[i]), seed(order[i+1])))
+	}
+	winners = append(winners, round)
+	for r := 2; len(round) > 1; r++ {
+		var next []*Match
+		for i := 0; i < len(round); i += 2 {
+			next = append(next, t.addMatch(Winners, r, winnerOf(round[i]), winnerOf(round[i+1])))
+		}
+		winners = append(winners, next)
+		round = next
+	}
+	if !double {
+		return
+	}
+
+	final := winners[len(winners)-1][0]
+	if len(winners) == 1 {
+		t.addMatch(GrandFinal, 1, winnerOf(final), loserOf(final))
+		return
+	}
+
+	// Losers round 1 pairs the first-round losers; after that, even rounds
+	// bring in the losers of the next winners round and odd rounds halve
+	// the field.
+	var losers []*Match
+	first := winners[0]
+	for i := 0; i < len(first); i += 2 {
+		losers = append(losers, t.addMatch(Losers, 1, loserOf(first[i]), loserOf(first[i+1])))
+	}
+	lr := 2
+	for wr := 1; wr < len(winners); wr++ {
+		dropping := winners[wr]
+		var next []*Match
+		for i, m := range losers {
+			// Mirror the drop-ins to avoid immediate rematches.
+			next = append(next, t.addMatch(Losers, lr, winnerOf(m), loserOf(dropping[len(dropping)-1-i])))
+		}
+		losers = next
+		lr++
+
+		if len(losers) > 1 {
+			var halved []*Match
+			for i := 0; i < len(losers); i += 2 {
+				halved = append(halved, t.addMatch(Losers, lr, winnerOf(losers[i]), winnerOf(losers[i+1])))
+			}
+			losers = halved
+			lr++
+		}
+	}
+
+	t.addMatch(GrandFinal, 1, winnerOf(final), winnerOf(losers[0]))
+}
+
+// buildRoundRobin pairs everyone once using the circle method.
+func (t *Tournament) buildRoundRobin() {
+	players := append([]string(nil), t.Players...)
+	if len(players)%2 == 1 {
+		players = append(players, "")
+	}
+	n := len(players)
+
+	for r := 1; r < n; r++ {
+		for i := 0; i < n/2; i++ {
+			x, o := players[i], players[n-1-i]
+			if x == "" || o == "" {
+				continue
+			}
+			// Alternate colours so nobody is always X.
+			if (r+i)%2 == 0 {
+				x, o = o, x
+			}
+			t.addMatch("", r, Slot{Player: x, Filled: true}, Slot{Player: o, Filled: true})
+		}
+		// Keep the first player fixed and rotate the rest.
+		players = append([]string{players[0], players[n-1]}, players[1:n-1]...)
+	}
+}
+
+// ── Advancing ────────────────────────────────────────────────────────────────
+
+func (t *Tournament) complete(m *Match, winner, loser string) {
+	m.Done, m.Winner, m.Loser = true, winner, loser
+	for _, next := range t.Matches {
+		for i := range next.Slots {
+			s := &next.Slots[i]
+			if s.From != m.ID || s.Filled {
+				continue
+			}
+			s.Filled = true
+			if s.TakeLoser {
+				s.Player = loser
+			} else {
+				s.Player = winner
+			}
+		}
+	}
+}
+
+// settleByes advances every match that has both slots filled but at most
+// one player, until nothing changes.
+func (t *Tournament) settleByes() {
+	for changed := true; changed; {
+		changed = false
+		for _, m := range t.Matches {
+			if m.Done || !m.Slots[0].Filled || !m.Slots[1].Filled {
+				continue
+			}
+			x, o := m.X(), m.O()
+			if x != "" && o != "" {
+				continue
+			}
+			m.Bye = true
+			t.complete(m, x+o, "")
+			changed = true
+		}
+	}
+}
+
+func (t *Tournament) checkFinished() {
+	if t.Status != Running {
+		return
+	}
+	for _, m := range t.Matches {
+		if !m.Done {
+			return
+		}
+	}
+
+	t.Status = Finished
+	if t.Format == RoundRobin {
+		if st := t.Standings(); len(st) > 0 {
+			t.Champion = st[0].Player
+		}
+		return
+	}
+	t.Champion = t.Matches[len(t.Matches)-1].Winner
+}
+
+// ── Standings ────────────────────────────────────────────────────────────────
+
+type Standing struct {
+	Player string `json:"player"`
+	Played int    `json:"played"`
+	Wins   int    `json:"wins"`
+	Draws  int    `json:"draws"`
+	Losses int    `json:"losses"`
+	// Points are 2 per win and 1 per draw.
+	Points     int  `json:"points"`
+	Eliminated bool `json:"eliminated,omitempty"`
+}
+
+// Standings ranks the players: by points in a round robin, and by how far
+// they got in an elimination event.
+func (t *Tournament) Standings() []Standing {
+	byPlayer := make(map[string]*Standing, len(t.Players))
+	out := make([]*Standing, len(t.Players))
+	for i, p := range t.Players {
+		out[i] = &Standing{Player: p}
+		byPlayer[p] = out[i]
+	}
+
+	lives := 1
+	if t.Format == DoubleElimination {
+		lives = 2
+	}
+	for _, m := range t.Matches {
+		if !m.Done || m.Bye {
+			continue
+		}
+		x, o := byPlayer[m.X()], byPlayer[m.O()]
+		x.Played++
+		o.Played++
+		switch {
+		case m.Draw:
+			x.Draws++
+			o.Draws++
+		case m.Winner == m.X():
+			x.Wins++
+			o.Losses++
+		default:
+			o.Wins++
+			x.Losses++
+		}
+	}
+	for _, s := range out {
+		s.Points = 2*s.Wins + s.Draws
+		s.Eliminated = t.Format != RoundRobin && t.Status != Registering && s.Losses >= lives && s.Player != t.Champion
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if t.Format != RoundRobin {
+			if (a.Player == t.Champion) != (b.Player == t.Champion) {
+				return a.Player == t.Champion
+			}
+			if a.Eliminated != b.Eliminated {
+				return !a.Eliminated
+			}
+		}
+		if a.Points != b.Points {
+			return a.Points > b.Points
+		}
+		return a.Losses < b.Losses
+	})
+
+	standings := make([]Standing, len(out))
+	for i, s := range out {
+		standings[i] = *s
+	}
+	return standings
+}
@@ -0,0 +1,299 @@
+package tournament
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestRegistration(t *testing.T) {
+	tr := newTournament(t, SingleElimination)
+
+	assertNoError(t, tr.Register("alice"))
+	assertErr(t, tr.Register("alice"), ErrRegistered)
+	assertErr(t, tr.Start(), ErrTooFewPlayers)
+
+	assertNoError(t, tr.Register("bob"))
+	assertNoError(t, tr.Register("carol"))
+	assertNoError(t, tr.Unregister("carol"))
+	assertErr(t, tr.Unregister("carol"), ErrNotRegistered)
+
+	assertNoError(t, tr.Start())
+	assertErr(t, tr.Register("dave"), ErrNotRegistering)
+	assertErr(t, tr.Start(), ErrNotRegistering)
+
+	if _, err := New("t", "bad", "org", Format("swiss")); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestSeedOrder(t *testing.T) {
+	got := seedOrder(8)
+	want := []int{1, 8, 4, 5, 2, 7, 3, 6}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestSingleElimination(t *testing.T) {
+	t.Run("four players", func(t *testing.T) {
+		tr := started(t, SingleElimination, "a", "b", "c", "d")
+
+		assertPairings(t, tr.Ready(), "a-d", "b-c")
+		play(t, tr, "a", "d")
+		play(t, tr, "c", "b")
+		assertPairings(t, tr.Ready(), "a-c")
+		play(t, tr, "c", "a")
+
+		assertChampion(t, tr, "c")
+		assertStandingOrder(t, tr, "c", "a")
+	})
+
+	t.Run("byes advance top seeds", func(t *testing.T) {
+		tr := started(t, SingleElimination, "a", "b", "c", "d", "e")
+
+		// Seeds 1-3 get byes, so 2 v 3 can already play in round 2.
+		assertPairings(t, tr.Ready(), "d-e", "b-c")
+		play(t, tr, "e", "d")
+		assertPairings(t, tr.Ready(), "a-e", "b-c")
+	})
+
+	t.Run("draws are replayed with colours swapped", func(t *testing.T) {
+		tr := started(t, SingleElimination, "a", "b")
+
+		m := tr.Ready()[0]
+		assertNoError(t, tr.Report(m.ID, ""))
+		assertPairings(t, tr.Ready(), "b-a")
+		if m.Replays != 1 {
+			t.Errorf("got %d replays, want 1", m.Replays)
+		}
+		play(t, tr, "b", "a")
+		assertChampion(t, tr, "b")
+	})
+
+	t.Run("bad reports", func(t *testing.T) {
+		tr := started(t, SingleElimination, "a", "b", "c", "d")
+		final := tr.Matches[len(tr.Matches)-1]
+
+		assertErr(t, tr.Report(99, "a"), ErrNoSuchMatch)
+		assertErr(t, tr.Report(final.ID, "a"), ErrMatchNotReady)
+		assertErr(t, tr.Report(tr.Ready()[0].ID, "b"), ErrNotInMatch)
+	})
+}
+
+func TestDoubleElimination(t *testing.T) {
+	t.Run("a player is only out after two losses", func(t *testing.T) {
+		tr := started(t, DoubleElimination, "a", "b", "c", "d")
+
+		play(t, tr, "a", "d")
+		play(t, tr, "b", "c")
+		// Winners final and losers round 1 are both ready.
+		assertPairings(t, tr.Ready(), "a-b", "d-c")
+		play(t, tr, "a", "b")
+		play(t, tr, "c", "d")
+		assertPairings(t, tr.Ready(), "c-b")
+		play(t, tr, "c", "b")
+
+		// Grand final: a comes from the winners bracket.
+		assertPairings(t, tr.Ready(), "a-c")
+		play(t, tr, "a", "c")
+		assertChampion(t, tr, "a")
+		assertStandingOrder(t, tr, "a", "c")
+	})
+
+	t.Run("grand final reset", func(t *testing.T) {
+		tr := started(t, DoubleElimination, "a", "b")
+
+		play(t, tr, "a", "b")
+		assertPairings(t, tr.Ready(), "a-b")
+		play(t, tr, "b", "a")
+		if tr.Status != Running {
+			t.Fatal("losing the first grand final should force a reset")
+		}
+		assertPairings(t, tr.Ready(), "b-a")
+		play(t, tr, "b", "a")
+		assertChampion(t, tr, "b")
+	})
+
+	t.Run("every field size finishes", func(t *testing.T) {
+		for n := 2; n <= 9; n++ {
+			tr := started(t, DoubleElimination, players(n)...)
+			playOut(t, tr)
+			if tr.Status != Finished || tr.Champion == "" {
+				t.Errorf("%d players: status %s, champion %q", n, tr.Status, tr.Champion)
+			}
+			losses := 0
+			for _, s := range tr.Standings() {
+				if s.Eliminated {
+					losses++
+				}
+			}
+			if losses != n-1 {
+				t.Errorf("%d players: %d eliminated, want %d", n, losses, n-1)
+			}
+		}
+	})
+}
+
+func TestRoundRobin(t *testing.T) {
+	t.Run("everyone plays everyone once", func(t *testing.T) {
+		for n := 2; n <= 7; n++ {
+			tr := started(t, RoundRobin, players(n)...)
+			if want := n * (n - 1) / 2; len(tr.Matches) != want {
+				t.Errorf("%d players: %d matches, want %d", n, len(tr.Matches), want)
+			}
+
+			seen := map[string]bool{}
+			for _, m := range tr.Matches {
+				pair := []string{m.X(), m.O()}
+				slices.Sort(pair)
+				key := fmt.Sprint(pair)
+				if seen[key] {
+					t.Errorf("%d players: %s paired twice", n, key)
+				}
+				seen[key] = true
+			}
+		}
+	})
+
+	t.Run("rounds are played in order", func(t *testing.T) {
+		tr := started(t, RoundRobin, "a", "b", "c", "d")
+		for _, m := range tr.Ready() {
+			if m.Round != 1 {
+				t.Fatalf("match %d from round %d is ready before round 1 is over", m.ID, m.Round)
+			}
+		}
+	})
+
+	t.Run("standings count draws as half a win", func(t *testing.T) {
+		tr := started(t, RoundRobin, "a", "b", "c")
+		for tr.Status == Running {
+			m := tr.Ready()[0]
+			// a beats everyone and the rest draw.
+			winner := ""
+			if m.X() == "a" || m.O() == "a" {
+				winner = "a"
+			}
+			assertNoError(t, tr.Report(m.ID, winner))
+		}
+
+		st := tr.Standings()
+		if st[0].Player != "a" || st[0].Points != 4 {
+			t.Errorf("leader %+v, want a with 4 points", st[0])
+		}
+		if st[1].Points != 1 || st[2].Points != 1 {
+			t.Errorf("got %+v, want b and c on one point each", st[1:])
+		}
+		assertChampion(t, tr, "a")
+	})
+}
+
+func TestClone(t *testing.T) {
+	tr := started(t, SingleElimination, "a", "b")
+	c := tr.Clone()
+
+	play(t, tr, "a", "b")
+	if c.Status != Running || c.Matches[0].Done {
+		t.Error("reporting a result changed the clone")
+	}
+}
+
+// ── helpers ──────────────────────────────────────────────────────────────────
+
+func newTournament(t testing.TB, f Format) *Tournament {
+	t.Helper()
+	tr, err := New("t1", "lunch", "org", f)
+	assertNoError(t, err)
+	return tr
+}
+
+func started(t testing.TB, f Format, names ...string) *Tournament {
+	t.Helper()
+	tr := newTournament(t, f)
+	for _, n := range names {
+		assertNoError(t, tr.Register(n))
+	}
+	assertNoError(t, tr.Start())
+	return tr
+}
+
+func players(n int) []string {
+	out := make([]string, n)
+	for i := range out {
+		out[i] = fmt.Sprintf("p%d", i+1)
+	}
+	return out
+}
+
+// play reports winner beating loser in their ready match.
+func play(t testing.TB, tr *Tournament, winner, loser string) {
+	t.Helper()
+	for _, m := range tr.Ready() {
+		if (m.X() == winner && m.O() == loser) || (m.X() == loser && m.O() == winner) {
+			assertNoError(t, tr.Report(m.ID, winner))
+			return
+		}
+	}
+	t.Fatalf("no ready match between %s and %s", winner, loser)
+}
+
+// playOut lets the first-seated player win every match.
+func playOut(t testing.TB, tr *Tournament) {
+	t.Helper()
+	for i := 0; tr.Status == Running; i++ {
+		if i > 100 {
+			t.Fatal("tournament did not finish")
+		}
+		ready := tr.Ready()
+		if len(ready) == 0 {
+			t.Fatal("running tournament has no ready matches")
+		}
+		assertNoError(t, tr.Report(ready[0].ID, ready[0].X()))
+	}
+}
+
+func assertPairings(t testing.TB, ready []*Match, want ...string) {
+	t.Helper()
+	var got []string
+	for _, m := range ready {
+		got = append(got, m.X()+"-"+m.O())
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got pairings %v, want %v", got, want)
+	}
+}
+
+func assertChampion(t testing.TB, tr *Tournament, want string) {
+	t.Helper()
+	if tr.Status != Finished {
+		t.Fatalf("tournament is %s, want finished", tr.Status)
+	}
+	if tr.Champion != want {
+		t.Errorf("got champion %q, want %q", tr.Champion, want)
+	}
+}
+
+func assertStandingOrder(t testing.TB, tr *Tournament, want ...string) {
+	t.Helper()
+	st := tr.Standings()
+	for i, name := range want {
+		if st[i].Player != name {
+			t.Errorf("standing %d is %s, want %s", i+1, st[i].Player, name)
+		}
+	}
+}
+
+func assertNoError(t testing.TB, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("didn't expect an error but got one, %v", err)
+	}
+}
+
+func assertErr(t testing.TB, got, want error) {
+	t.Helper()
+	if !errors.Is(got, want) {
+		t.Errorf("got error %v, want %v", got, want)
+	}
+}
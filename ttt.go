@@ -3,12 +3,17 @@ package ttt
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
+
+	"github.com/jwc20/ssh-ttt/variant"
 )
 
 type TicTacToe struct {
 	store    PlayerStore
 	position *Position
+	setup    *Position
+	moves    []int
 }
 
 func NewTicTacToe(store PlayerStore) *TicTacToe {
@@ -19,6 +24,21 @@ func NewTicTacToe(store PlayerStore) *TicTacToe {
 
 func (g *TicTacToe) Start(numberOfPlayers int) {
 	g.position = InitPosition()
+	if g.setup != nil {
+		g.position = g.setup.Copy()
+	}
+	g.moves = nil
+}
+
+// SetUp has the game start from p, which must be valid, instead of the
+// empty board; nil goes back to the empty board.
+func (g *TicTacToe) SetUp(p *Position) {
+	g.setup = p
+}
+
+// Setup is the position the game starts from, or nil for the empty board.
+func (g *TicTacToe) Setup() *Position {
+	return g.setup
 }
 
 func (g *TicTacToe) Finish(winner string) {
@@ -27,10 +47,14 @@ func (g *TicTacToe) Finish(winner string) {
 
 func (g *TicTacToe) MakeMove(position int) error {
 	idx := position - 1
+	if idx < 0 || idx >= SIZE {
+		return errors.New("square out of range")
+	}
 	if g.position.Board[idx] != ' ' {
 		return errors.New("square already taken")
 	}
 	g.position.Move(idx)
+	g.moves = append(g.moves, idx)
 	return nil
 }
 
@@ -67,3 +91,21 @@ func (g *TicTacToe) Winner() string {
 func (g *TicTacToe) BestMove() int {
 	return g.position.BestMove() + 1
 }
+
+// State is the current position for an engine; its moves count squares
+// from 0. It fails like Position.State if the game was set up from an
+// invalid position.
+func (g *TicTacToe) State() (variant.State, error) {
+	return g.position.State()
+}
+
+// PlayMove makes a move given the way State counts them.
+func (g *TicTacToe) PlayMove(move int) error {
+	return g.MakeMove(move + 1)
+}
+
+// Variant is the registry name of the game.
+func (g *TicTacToe) Variant() string { return "classic" }
+
+// Moves are the squares played so far, counted from 0.
+func (g *TicTacToe) Moves() []int { return slices.Clone(g.moves) }
@@ -1,6 +1,7 @@
 package ttt_test
 
 import (
+	"errors"
 	"testing"
 
 	ttt "github.com/jwc20/ssh-ttt"
@@ -114,6 +115,46 @@ func TestGame_Draw(t *testing.T) {
 	})
 }
 
+func TestGame_State(t *testing.T) {
+	game := ttt.NewTicTacToe(dummyPlayerStore)
+	game.Start(2)
+	game.MakeMove(1)
+	game.MakeMove(5)
+	game.MakeMove(2)
+
+	s, err := game.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(s.Cells()); got != "XX  O    " {
+		t.Errorf("got cells %q, want %q", got, "XX  O    ")
+	}
+	if s.Turn() != 'O' {
+		t.Errorf("got turn %q, want O", s.Turn())
+	}
+
+	if err := game.PlayMove(2); err != nil {
+		t.Fatal(err)
+	}
+	assertCurrentPlayer(t, game, "X")
+	s, err = game.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(s.Cells()); got != "XXO O    " {
+		t.Errorf("got cells %q after PlayMove(2)", got)
+	}
+
+	t.Run("an invalid set-up position is an error, not a panic", func(t *testing.T) {
+		game := ttt.NewTicTacToe(dummyPlayerStore)
+		game.SetUp(&ttt.Position{Turn: "x", Board: "xx"})
+		game.Start(2)
+		if _, err := game.State(); !errors.Is(err, ttt.ErrBadPosition) {
+			t.Errorf("got error %v, want ErrBadPosition", err)
+		}
+	})
+}
+
 func TestGame_Finish(t *testing.T) {
 	store := &ttt.StubPlayerStore{}
 	game := ttt.NewTicTacToe(store)
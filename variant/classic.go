@@ -0,0 +1,70 @@
+package variant
+
+import "slices"
+
+// Classic is the 3×3 game: three in a row wins.
+type Classic struct {
+	cells [9]rune
+	turn  rune
+}
+
+func NewClassic() *Classic {
+	c := &Classic{turn: 'X'}
+	for i := range c.cells {
+		c.cells[i] = Empty
+	}
+	return c
+}
+
+// ClassicPosition returns the 3×3 position with the given cells and side to
+// move. It does not check that the position could come up in play.
+func ClassicPosition(cells [9]rune, turn rune) *Classic {
+	return &Classic{cells: cells, turn: turn}
+}
+
+func (c *Classic) Cells() []rune { return c.cells[:] }
+func (c *Classic) Turn() rune    { return c.turn }
+
+func (c *Classic) Moves() []int {
+	if c.Over() {
+		return nil
+	}
+	var moves []int
+	for i, m := range c.cells {
+		if m == Empty {
+			moves = append(moves, i)
+		}
+	}
+	return moves
+}
+
+func (c *Classic) Play(move int) error {
+	switch {
+	case move < 0 || move >= len(c.cells):
+		return ErrOutOfRange
+	case c.cells[move] != Empty:
+		return ErrCellTaken
+	case c.Over():
+		return ErrGameOver
+	}
+	c.cells[move] = c.turn
+	c.turn = Other(c.turn)
+	return nil
+}
+
+func (c *Classic) Winner() rune {
+	return lineWinner(lines3, func(i int) rune { return c.cells[i] })
+}
+
+func (c *Classic) Over() bool {
+	return c.Winner() != Empty || !slices.Contains(c.cells[:], Empty)
+}
+
+func (c *Classic) Clone() State {
+	cc := *c
+	return &cc
+}
+
+func (c *Classic) Evaluate() int {
+	return lineScore(lines3, func(i int) rune { return c.cells[i] }, 0)
+}
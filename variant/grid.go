@@ -0,0 +1,167 @@
+package variant
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Options size a Grid board. The zero value means the variant's defaults.
+type Options struct {
+	Cols    int  `json:"cols"`
+	Rows    int  `json:"rows"`
+	InARow  int  `json:"in_a_row"`
+	Gravity bool `json:"gravity"`
+}
+
+// ConnectFour is the board Grid games get by default.
+var ConnectFour = Options{Cols: 7, Rows: 6, InARow: 4, Gravity: true}
+
+// MaxGridSide caps both sides of a Grid board.
+const MaxGridSide = 10
+
+var ErrBadOptions = errors.New("invalid board options")
+
+func (o Options) Validate() error {
+	switch {
+	case o.Cols < 3 || o.Rows < 3 || o.Cols > MaxGridSide || o.Rows > MaxGridSide:
+		return fmt.Errorf("%w: sides must be 3 to %d", ErrBadOptions, MaxGridSide)
+	case o.InARow < 3 || o.InARow > max(o.Cols, o.Rows):
+		return fmt.Errorf("%w: need 3 to %d in a row", ErrBadOptions, max(o.Cols, o.Rows))
+	}
+	return nil
+}
+
+func (o Options) String() string {
+	s := fmt.Sprintf("%d×%d, %d in a row", o.Cols, o.Rows, o.InARow)
+	if o.Gravity {
+		s += ", gravity"
+	}
+	return s
+}
+
+// ParseOptions reads options written by String.
+func ParseOptions(s string) (Options, error) {
+	var o Options
+	rest, gravity := strings.CutSuffix(s, ", gravity")
+	var tail string
+	n, _ := fmt.Sscanf(rest, "%d×%d, %d in a %s", &o.Cols, &o.Rows, &o.InARow, &tail)
+	if n != 4 || tail != "row" {
+		return Options{}, fmt.Errorf("%w: %q", ErrBadOptions, s)
+	}
+	o.Gravity = gravity
+	if err := o.Validate(); err != nil {
+		return Options{}, err
+	}
+	return o, nil
+}
+
+// Grid is the m,n,k-game: K in a row on a board of any size. With gravity
+// a mark drops to the lowest free cell of its column, as in Connect Four,
+// and moves are column numbers instead of cells.
+//
+// Cells are numbered row by row from the top.
+type Grid struct {
+	opts  Options
+	cells []rune
+	turn  rune
+	// lines is shared between clones; it never changes after NewGrid.
+	lines [][]int
+}
+
+// NewGrid returns an empty board; opts must be valid.
+func NewGrid(opts Options) *Grid {
+	if err := opts.Validate(); err != nil {
+		panic(err)
+	}
+	g := &Grid{
+		opts:  opts,
+		cells: make([]rune, opts.Cols*opts.Rows),
+		turn:  'X',
+		lines: gridLines(opts.Cols, opts.Rows, opts.InARow),
+	}
+	for i := range g.cells {
+		g.cells[i] = Empty
+	}
+	return g
+}
+
+func (g *Grid) Options() Options { return g.opts }
+func (g *Grid) Cells() []rune    { return g.cells }
+func (g *Grid) Turn() rune       { return g.turn }
+
+// Drop returns the cell a mark dropped in col would land on, or -1 when
+// the column is full.
+func (g *Grid) Drop(col int) int {
+	for row := g.opts.Rows - 1; row >= 0; row-- {
+		if cell := row*g.opts.Cols + col; g.cells[cell] == Empty {
+			return cell
+		}
+	}
+	return -1
+}
+
+func (g *Grid) Moves() []int {
+	if g.Over() {
+		return nil
+	}
+	var moves []int
+	if g.opts.Gravity {
+		for col := range g.opts.Cols {
+			if g.cells[col] == Empty {
+				moves = append(moves, col)
+			}
+		}
+		return moves
+	}
+	for i, m := range g.cells {
+		if m == Empty {
+			moves = append(moves, i)
+		}
+	}
+	return moves
+}
+
+func (g *Grid) Play(move int) error {
+	cell := move
+	if g.opts.Gravity {
+		if move < 0 || move >= g.opts.Cols {
+			return ErrOutOfRange
+		}
+		if cell = g.Drop(move); cell < 0 {
+			return ErrCellTaken
+		}
+	} else {
+		if move < 0 || move >= len(g.cells) {
+			return ErrOutOfRange
+		}
+		if g.cells[cell] != Empty {
+			return ErrCellTaken
+		}
+	}
+	if g.Over() {
+		return ErrGameOver
+	}
+	g.cells[cell] = g.turn
+	g.turn = Other(g.turn)
+	return nil
+}
+
+func (g *Grid) Winner() rune {
+	return lineWinner(g.lines, func(i int) rune { return g.cells[i] })
+}
+
+func (g *Grid) Over() bool {
+	return g.Winner() != Empty || !slices.Contains(g.cells, Empty)
+}
+
+func (g *Grid) Clone() State {
+	gc := *g
+	gc.cells = slices.Clone(g.cells)
+	return &gc
+}
+
+func (g *Grid) Evaluate() int {
+	return lineScore(g.lines, func(i int) rune { return g.cells[i] }, 0)
+}
@@ -0,0 +1,29 @@
+package variant
+
+// Misere is classic tic-tac-toe played to lose: whoever completes a line
+// loses the game.
+type Misere struct {
+	Classic
+}
+
+func NewMisere() *Misere {
+	return &Misere{Classic: *NewClassic()}
+}
+
+func (m *Misere) Winner() rune {
+	if w := m.Classic.Winner(); w != Empty {
+		return Other(w)
+	}
+	return Empty
+}
+
+func (m *Misere) Clone() State {
+	mc := *m
+	return &mc
+}
+
+// Evaluate turns the classic score around: lines of your own are now a
+// liability.
+func (m *Misere) Evaluate() int {
+	return -m.Classic.Evaluate()
+}
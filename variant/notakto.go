@@ -0,0 +1,128 @@
+package variant
+
+import "slices"
+
+// Notakto is played on several 3×3 boards with both players placing X. A
+// board with three in a row is dead and takes no more moves; whoever kills
+// the last live board loses. Turn and Winner name the seats.
+//
+// Cells are numbered board*9 + cell.
+type Notakto struct {
+	cells  []rune
+	turn   rune
+	winner rune
+}
+
+func NewNotakto(boards int) *Notakto {
+	n := &Notakto{cells: make([]rune, boards*9), turn: 'X', winner: Empty}
+	for i := range n.cells {
+		n.cells[i] = Empty
+	}
+	return n
+}
+
+func (n *Notakto) Boards() int   { return len(n.cells) / 9 }
+func (n *Notakto) Cells() []rune { return n.cells }
+func (n *Notakto) Turn() rune    { return n.turn }
+
+// Dead reports whether board b has three in a row.
+func (n *Notakto) Dead(b int) bool {
+	return lineWinner(lines3, func(i int) rune { return n.cells[b*9+i] }) != Empty
+}
+
+func (n *Notakto) Moves() []int {
+	if n.Over() {
+		return nil
+	}
+	var moves []int
+	for b := range n.Boards() {
+		if n.Dead(b) {
+			continue
+		}
+		for c := range 9 {
+			if n.cells[b*9+c] == Empty {
+				moves = append(moves, b*9+c)
+			}
+		}
+	}
+	return moves
+}
+
+func (n *Notakto) Play(move int) error {
+	switch {
+	case move < 0 || move >= len(n.cells):
+		return ErrOutOfRange
+	case n.cells[move] != Empty:
+		return ErrCellTaken
+	case n.Over():
+		return ErrGameOver
+	case n.Dead(move / 9):
+		return ErrIllegalMove
+	}
+	n.cells[move] = 'X'
+	if n.allDead() {
+		n.winner = Other(n.turn)
+	}
+	n.turn = Other(n.turn)
+	return nil
+}
+
+// completes reports whether an X on the free cell m makes a line.
+func (n *Notakto) completes(m int) bool {
+	b, c := m/9, m%9
+	for _, l := range lines3 {
+		if !slices.Contains(l, c) {
+			continue
+		}
+		full := true
+		for _, i := range l {
+			if i != c && n.cells[b*9+i] != 'X' {
+				full = false
+			}
+		}
+		if full {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *Notakto) allDead() bool {
+	for b := range n.Boards() {
+		if !n.Dead(b) {
+			return false
+		}
+	}
+	return true
+}
+
+// Winner is the seat that did not kill the last board. A full board always
+// holds a line, so the game can't be drawn.
+func (n *Notakto) Winner() rune { return n.winner }
+func (n *Notakto) Over() bool   { return n.winner != Empty }
+
+func (n *Notakto) Clone() State {
+	nc := *n
+	nc.cells = slices.Clone(n.cells)
+	return &nc
+}
+
+// Evaluate counts the safe moves left, those that don't complete a line.
+// Whoever makes the last of them leaves the opponent to kill a board, so an
+// odd count favours the side to move.
+func (n *Notakto) Evaluate() int {
+	safe := 0
+	for _, m := range n.Moves() {
+		if !n.completes(m) {
+			safe++
+		}
+	}
+	favoured := n.turn
+	if safe%2 == 0 {
+		favoured = Other(n.turn)
+	}
+	if favoured == 'X' {
+		return 1
+	}
+	return -1
+}
@@ -0,0 +1,105 @@
+package variant
+
+import "slices"
+
+// OrderChaos is Order and Chaos on a 6×6 board. Both sides place either
+// mark. Order, the first player, wins with five of the same mark in a row;
+// Chaos wins if the board fills up without one. Turn and Winner name the
+// seats, Order as 'X' and Chaos as 'O'. Moves below 36 place an X and moves
+// from 36 up an O.
+type OrderChaos struct {
+	cells [36]rune
+	turn  rune
+}
+
+var orderChaosLines = gridLines(6, 6, 5)
+
+func NewOrderChaos() *OrderChaos {
+	oc := &OrderChaos{turn: 'X'}
+	for i := range oc.cells {
+		oc.cells[i] = Empty
+	}
+	return oc
+}
+
+func (oc *OrderChaos) Cells() []rune { return oc.cells[:] }
+func (oc *OrderChaos) Turn() rune    { return oc.turn }
+
+func (oc *OrderChaos) MoveFor(cell int, mark rune) int {
+	if mark == 'O' {
+		return cell + len(oc.cells)
+	}
+	return cell
+}
+
+func (oc *OrderChaos) Moves() []int {
+	if oc.Over() {
+		return nil
+	}
+	var moves []int
+	for _, mark := range []rune{'X', 'O'} {
+		for i, c := range oc.cells {
+			if c == Empty {
+				moves = append(moves, oc.MoveFor(i, mark))
+			}
+		}
+	}
+	return moves
+}
+
+func (oc *OrderChaos) Play(move int) error {
+	if move < 0 || move >= 2*len(oc.cells) {
+		return ErrOutOfRange
+	}
+	cell, mark := move%len(oc.cells), 'X'
+	if move >= len(oc.cells) {
+		mark = 'O'
+	}
+	switch {
+	case oc.cells[cell] != Empty:
+		return ErrCellTaken
+	case oc.Over():
+		return ErrGameOver
+	}
+	oc.cells[cell] = mark
+	oc.turn = Other(oc.turn)
+	return nil
+}
+
+func (oc *OrderChaos) Winner() rune {
+	if lineWinner(orderChaosLines, func(i int) rune { return oc.cells[i] }) != Empty {
+		return 'X'
+	}
+	if !slices.Contains(oc.cells[:], Empty) {
+		return 'O'
+	}
+	return Empty
+}
+
+func (oc *OrderChaos) Over() bool { return oc.Winner() != Empty }
+
+func (oc *OrderChaos) Clone() State {
+	occ := *oc
+	return &occ
+}
+
+// Evaluate scores the lines still open to Order, those holding only one
+// kind of mark, by the square of their marks. Chaos wants it low.
+func (oc *OrderChaos) Evaluate() int {
+	score := 0
+	for _, l := range orderChaosLines {
+		x, o := 0, 0
+		for _, i := range l {
+			switch oc.cells[i] {
+			case 'X':
+				x++
+			case 'O':
+				o++
+			}
+		}
+		if x == 0 || o == 0 {
+			score += (x + o) * (x + o)
+		}
+	}
+	return score
+}
@@ -0,0 +1,129 @@
+package variant
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Qubic is tic-tac-toe in a cube of Size×Size×Size cells: Size in a row
+// along any straight line wins, including the diagonals of each plane and
+// the four space diagonals through the cube. The 4×4×4 game is the classic
+// Qubic; on 3×3×3 the first player wins by taking the centre.
+//
+// Cells are numbered layer*Size*Size + row*Size + col.
+type Qubic struct {
+	size  int
+	cells []rune
+	turn  rune
+	// lines is shared between clones; it never changes after NewQubic.
+	lines [][]int
+}
+
+// NewQubic returns an empty cube with sides of size 3 or 4.
+func NewQubic(size int) *Qubic {
+	if size != 3 && size != 4 {
+		panic(fmt.Sprintf("variant: unsupported qubic size %d", size))
+	}
+	q := &Qubic{size: size, cells: make([]rune, size*size*size), turn: 'X', lines: cubeLines(size)}
+	for i := range q.cells {
+		q.cells[i] = Empty
+	}
+	return q
+}
+
+// cubeLines lists every straight line of n cells in an n×n×n cube. Each of
+// the 13 directions is taken with its first non-zero step positive, so no
+// line is listed twice.
+func cubeLines(n int) [][]int {
+	var lines [][]int
+	for dl := -1; dl <= 1; dl++ {
+		for dr := -1; dr <= 1; dr++ {
+			for dc := -1; dc <= 1; dc++ {
+				first := dl
+				if first == 0 {
+					first = dr
+				}
+				if first == 0 {
+					first = dc
+				}
+				if first != 1 {
+					continue
+				}
+				for l := range n {
+					for r := range n {
+						for c := range n {
+							if line := cubeLine(n, l, r, c, dl, dr, dc); line != nil {
+								lines = append(lines, line)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return lines
+}
+
+// cubeLine returns the n cells from (l, r, c) stepping by (dl, dr, dc), or
+// nil when the line leaves the cube.
+func cubeLine(n, l, r, c, dl, dr, dc int) []int {
+	line := make([]int, 0, n)
+	for range n {
+		if l < 0 || l >= n || r < 0 || r >= n || c < 0 || c >= n {
+			return nil
+		}
+		line = append(line, (l*n+r)*n+c)
+		l, r, c = l+dl, r+dr, c+dc
+	}
+	return line
+}
+
+func (q *Qubic) Size() int      { return q.size }
+func (q *Qubic) Cells() []rune  { return q.cells }
+func (q *Qubic) Turn() rune     { return q.turn }
+func (q *Qubic) Lines() [][]int { return q.lines }
+
+func (q *Qubic) Moves() []int {
+	if q.Over() {
+		return nil
+	}
+	var moves []int
+	for i, m := range q.cells {
+		if m == Empty {
+			moves = append(moves, i)
+		}
+	}
+	return moves
+}
+
+func (q *Qubic) Play(move int) error {
+	switch {
+	case move < 0 || move >= len(q.cells):
+		return ErrOutOfRange
+	case q.cells[move] != Empty:
+		return ErrCellTaken
+	case q.Over():
+		return ErrGameOver
+	}
+	q.cells[move] = q.turn
+	q.turn = Other(q.turn)
+	return nil
+}
+
+func (q *Qubic) Winner() rune {
+	return lineWinner(q.lines, func(i int) rune { return q.cells[i] })
+}
+
+func (q *Qubic) Over() bool {
+	return q.Winner() != Empty || !slices.Contains(q.cells, Empty)
+}
+
+func (q *Qubic) Clone() State {
+	qc := *q
+	qc.cells = slices.Clone(q.cells)
+	return &qc
+}
+
+func (q *Qubic) Evaluate() int {
+	return lineScore(q.lines, func(i int) rune { return q.cells[i] }, 0)
+}
@@ -0,0 +1,103 @@
+package variant
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrUnknownVariant = errors.New("unknown variant")
+
+// Info describes a registered variant. Variants with a Configure function
+// take board Options; New then uses Defaults.
+type Info struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Defaults    *Options            `json:"defaults,omitempty"`
+	New         func() State        `json:"-"`
+	Configure   func(Options) State `json:"-"`
+}
+
+var registry []Info
+
+// Register adds a variant. Names must be unique; variants are listed in the
+// order they were registered.
+func Register(info Info) {
+	if _, ok := Lookup(info.Name); ok {
+		panic(fmt.Sprintf("variant: %q registered twice", info.Name))
+	}
+	registry = append(registry, info)
+}
+
+// Lookup returns the variant called name.
+func Lookup(name string) (Info, bool) {
+	for _, info := range registry {
+		if info.Name == name {
+			return info, true
+		}
+	}
+	return Info{}, false
+}
+
+// New returns the starting position of the variant called name.
+func New(name string) (State, error) {
+	info, ok := Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownVariant, name)
+	}
+	return info.New(), nil
+}
+
+// NewWith returns the starting position of the variant called name on the
+// board opts describes. Zero opts, and opts given to variants that take
+// none, are ignored.
+func NewWith(name string, opts Options) (State, error) {
+	info, ok := Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownVariant, name)
+	}
+	if info.Configure == nil || opts == (Options{}) {
+		return info.New(), nil
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return info.Configure(opts), nil
+}
+
+// All lists the registered variants.
+func All() []Info {
+	return append([]Info(nil), registry...)
+}
+
+// Names lists the registered variants' names.
+func Names() []string {
+	names := make([]string, len(registry))
+	for i, info := range registry {
+		names[i] = info.Name
+	}
+	return names
+}
+
+func init() {
+	Register(Info{Name: "classic", Description: "Three in a row on a 3×3 board.",
+		New: func() State { return NewClassic() }})
+	Register(Info{Name: "ultimate", Description: "Nine boards in one; your cell picks your opponent's board.",
+		New: func() State { return NewUltimate() }})
+	Register(Info{Name: "qubic3", Description: "Three in a row through a 3×3×3 cube.",
+		New: func() State { return NewQubic(3) }})
+	Register(Info{Name: "qubic4", Description: "Four in a row through a 4×4×4 cube.",
+		New: func() State { return NewQubic(4) }})
+	Register(Info{Name: "misere", Description: "Classic played to lose: three in a row loses.",
+		New: func() State { return NewMisere() }})
+	Register(Info{Name: "notakto", Description: "Both play X on three boards; whoever kills the last board loses.",
+		New: func() State { return NewNotakto(3) }})
+	Register(Info{Name: "wild", Description: "Place X or O each turn; complete any line to win.",
+		New: func() State { return NewWild() }})
+	Register(Info{Name: "order-chaos", Description: "6×6: Order wants five of a mark in a row, Chaos wants a full board without.",
+		New: func() State { return NewOrderChaos() }})
+	defaults := ConnectFour
+	Register(Info{Name: "mnk", Description: "Any size board, K in a row; with gravity it's Connect Four.",
+		Defaults:  &defaults,
+		New:       func() State { return NewGrid(ConnectFour) },
+		Configure: func(o Options) State { return NewGrid(o) }})
+}
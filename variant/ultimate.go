@@ -0,0 +1,123 @@
+package variant
+
+import "slices"
+
+// Drawn marks a sub-board of Ultimate that filled up without a winner.
+const Drawn = '-'
+
+// Ultimate is meta tic-tac-toe: nine 3×3 sub-boards laid out as a 3×3 grid.
+// Winning a sub-board claims that square of the meta board, and a line of
+// claimed squares wins the game. The cell a player picks inside a sub-board
+// sends the opponent to the matching sub-board; if that one is already
+// decided they may play in any open sub-board.
+//
+// Cells are numbered board*9 + cell, both counted row by row.
+type Ultimate struct {
+	cells  [81]rune
+	boards [9]rune
+	turn   rune
+	next   int
+}
+
+func NewUltimate() *Ultimate {
+	u := &Ultimate{turn: 'X', next: -1}
+	for i := range u.cells {
+		u.cells[i] = Empty
+	}
+	for i := range u.boards {
+		u.boards[i] = Empty
+	}
+	return u
+}
+
+func (u *Ultimate) Cells() []rune { return u.cells[:] }
+func (u *Ultimate) Turn() rune    { return u.turn }
+
+// Next is the sub-board the player to move must play in, or -1 when any
+// open sub-board will do.
+func (u *Ultimate) Next() int { return u.next }
+
+// BoardWinner reports sub-board b: 'X' or 'O' once won, Drawn once full,
+// Empty while still open.
+func (u *Ultimate) BoardWinner(b int) rune { return u.boards[b] }
+
+// Playable reports whether the player to move may play in sub-board b.
+func (u *Ultimate) Playable(b int) bool {
+	if u.Over() || u.boards[b] != Empty {
+		return false
+	}
+	return u.next == -1 || u.next == b
+}
+
+func (u *Ultimate) Moves() []int {
+	var moves []int
+	for b := range u.boards {
+		if !u.Playable(b) {
+			continue
+		}
+		for c := range 9 {
+			if u.cells[b*9+c] == Empty {
+				moves = append(moves, b*9+c)
+			}
+		}
+	}
+	return moves
+}
+
+func (u *Ultimate) Play(move int) error {
+	switch {
+	case move < 0 || move >= len(u.cells):
+		return ErrOutOfRange
+	case u.cells[move] != Empty:
+		return ErrCellTaken
+	case u.Over():
+		return ErrGameOver
+	case !u.Playable(move / 9):
+		return ErrIllegalMove
+	}
+	u.cells[move] = u.turn
+	b := move / 9
+	sub := u.cells[b*9 : b*9+9]
+	if w := lineWinner(lines3, func(i int) rune { return sub[i] }); w != Empty {
+		u.boards[b] = w
+	} else if !slices.Contains(sub, Empty) {
+		u.boards[b] = Drawn
+	}
+	u.next = move % 9
+	if u.boards[u.next] != Empty {
+		u.next = -1
+	}
+	u.turn = Other(u.turn)
+	return nil
+}
+
+func (u *Ultimate) Winner() rune {
+	return lineWinner(lines3, func(i int) rune {
+		if u.boards[i] == Drawn {
+			return Empty
+		}
+		return u.boards[i]
+	})
+}
+
+func (u *Ultimate) Over() bool {
+	return u.Winner() != Empty || !slices.Contains(u.boards[:], Empty)
+}
+
+func (u *Ultimate) Clone() State {
+	uc := *u
+	return &uc
+}
+
+// Evaluate weighs the meta board ten times as heavily as the sub-boards
+// still in play.
+func (u *Ultimate) Evaluate() int {
+	score := 10 * lineScore(lines3, func(i int) rune { return u.boards[i] }, Drawn)
+	for b, w := range u.boards {
+		if w != Empty {
+			continue
+		}
+		score += lineScore(lines3, func(i int) rune { return u.cells[b*9+i] }, 0)
+	}
+	return score
+}
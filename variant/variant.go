@@ -0,0 +1,142 @@
+// Package variant implements the rules of the tic-tac-toe variants the
+// servers offer. Every variant exposes the same small State interface, so
+// rooms, renderers and engines can handle any of them without knowing the
+// rules.
+package variant
+
+import "errors"
+
+// Empty marks a free cell.
+const Empty = ' '
+
+var (
+	ErrGameOver    = errors.New("game is over")
+	ErrOutOfRange  = errors.New("position out of range")
+	ErrCellTaken   = errors.New("square already taken")
+	ErrIllegalMove = errors.New("move is not allowed here")
+)
+
+// State is a game in progress. Moves are indexes into Cells unless the
+// variant says otherwise.
+type State interface {
+	// Cells is the board, one mark per cell: 'X', 'O' or Empty.
+	Cells() []rune
+	// Turn is the side to move, 'X' or 'O'. In variants where players
+	// choose which mark to place it names the seat, not a mark.
+	Turn() rune
+	// Moves lists the legal moves, in ascending order.
+	Moves() []int
+	Play(move int) error
+	// Winner is the winning side, 'X' or 'O', or Empty while undecided or
+	// drawn.
+	Winner() rune
+	Over() bool
+	Clone() State
+}
+
+// Evaluator is implemented by variants whose unfinished positions can be
+// scored without searching to the end. Scores are from X's point of view.
+type Evaluator interface {
+	Evaluate() int
+}
+
+// MarkChooser is implemented by variants where the player to move picks
+// which mark to place. Their moves encode the cell and the mark together.
+type MarkChooser interface {
+	MoveFor(cell int, mark rune) int
+}
+
+// Other returns the opponent of mark.
+func Other(mark rune) rune {
+	if mark == 'X' {
+		return 'O'
+	}
+	return 'X'
+}
+
+// lines3 are the winning lines of a 3×3 grid.
+var lines3 = [][]int{
+	{0, 1, 2}, {3, 4, 5}, {6, 7, 8},
+	{0, 3, 6}, {1, 4, 7}, {2, 5, 8},
+	{0, 4, 8}, {2, 4, 6},
+}
+
+// gridLines lists every line of k cells in a row, column or diagonal of a
+// cols×rows grid numbered row by row.
+func gridLines(cols, rows, k int) [][]int {
+	var lines [][]int
+	for _, d := range [][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}} {
+		for r := range rows {
+			for c := range cols {
+				endR, endC := r+d[0]*(k-1), c+d[1]*(k-1)
+				if endR >= rows || endC < 0 || endC >= cols {
+					continue
+				}
+				line := make([]int, k)
+				for i := range k {
+					line[i] = (r+d[0]*i)*cols + c + d[1]*i
+				}
+				lines = append(lines, line)
+			}
+		}
+	}
+	return lines
+}
+
+// lineWinner returns the mark holding a full line, or Empty.
+func lineWinner(lines [][]int, cell func(i int) rune) rune {
+	for _, l := range lines {
+		a := cell(l[0])
+		if a == Empty {
+			continue
+		}
+		full := true
+		for _, i := range l[1:] {
+			if cell(i) != a {
+				full = false
+				break
+			}
+		}
+		if full {
+			return a
+		}
+	}
+	return Empty
+}
+
+// lineScore scores lines for X: a line only one side has marks in is worth
+// the square of their count to that side. Lines holding both marks, or the
+// blocked marker, count for nothing.
+func lineScore(lines [][]int, cell func(i int) rune, blocked rune) int {
+	score := 0
+	for _, l := range lines {
+		x, o := 0, 0
+		for _, i := range l {
+			switch cell(i) {
+			case 'X':
+				x++
+			case 'O':
+				o++
+			case blocked:
+				x, o = 1, 1
+			}
+		}
+		switch {
+		case o == 0 && x > 0:
+			score += x * x
+		case x == 0 && o > 0:
+			score -= o * o
+		}
+	}
+	return score
+}
+
+// Replay plays moves on s in order, stopping at the first illegal one.
+func Replay(s State, moves []int) error {
+	for _, m := range moves {
+		if err := s.Play(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
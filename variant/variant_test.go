@@ -0,0 +1,445 @@
+package variant
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestClassic(t *testing.T) {
+	t.Run("row wins", func(t *testing.T) {
+		c := NewClassic()
+		assertNoError(t, Replay(c, []int{0, 3, 1, 4, 2}))
+		assertWinner(t, c, 'X')
+		assertErr(t, c.Play(5), ErrGameOver)
+		if c.Moves() != nil {
+			t.Errorf("got moves %v after the game ended", c.Moves())
+		}
+	})
+
+	t.Run("draw", func(t *testing.T) {
+		c := NewClassic()
+		assertNoError(t, Replay(c, []int{0, 4, 8, 1, 7, 6, 2, 5, 3}))
+		assertWinner(t, c, Empty)
+		if !c.Over() {
+			t.Error("expected a full board to be over")
+		}
+	})
+
+	t.Run("illegal moves", func(t *testing.T) {
+		c := NewClassic()
+		assertNoError(t, c.Play(4))
+		assertErr(t, c.Play(4), ErrCellTaken)
+		assertErr(t, c.Play(9), ErrOutOfRange)
+		assertErr(t, c.Play(-1), ErrOutOfRange)
+		if c.Turn() != 'O' {
+			t.Errorf("got turn %q, want 'O'", c.Turn())
+		}
+	})
+}
+
+func TestUltimateSendsToBoard(t *testing.T) {
+	u := NewUltimate()
+	if u.Next() != -1 || len(u.Moves()) != 81 {
+		t.Fatalf("opening: next %d with %d moves, want -1 with 81", u.Next(), len(u.Moves()))
+	}
+
+	// X plays the top-right cell of the centre board, sending O top right.
+	assertNoError(t, u.Play(4*9+2))
+	if u.Next() != 2 {
+		t.Errorf("got next %d, want 2", u.Next())
+	}
+	want := []int{18, 19, 20, 21, 22, 23, 24, 25, 26}
+	if got := u.Moves(); !slices.Equal(got, want) {
+		t.Errorf("got moves %v, want %v", got, want)
+	}
+	assertErr(t, u.Play(0), ErrIllegalMove)
+	assertErr(t, u.Play(4*9+2), ErrCellTaken)
+}
+
+func TestUltimateSubBoards(t *testing.T) {
+	u := NewUltimate()
+	// O takes the right column of board 0; X keeps sending O back there.
+	assertNoError(t, Replay(u, []int{
+		0*9 + 1, 1*9 + 0,
+		0*9 + 0, 0*9 + 5,
+		5*9 + 0, 0*9 + 8,
+		8*9 + 0, 0*9 + 2,
+	}))
+	if u.BoardWinner(0) != 'O' {
+		t.Fatalf("got board 0 %q, want 'O'", u.BoardWinner(0))
+	}
+	// O's last move sent X to board 2, still open.
+	if u.Next() != 2 {
+		t.Errorf("got next %d, want 2", u.Next())
+	}
+
+	// A move into a decided board's cell number frees the opponent.
+	assertNoError(t, u.Play(2*9+0))
+	if u.Next() != -1 {
+		t.Errorf("got next %d after being sent to a won board, want -1", u.Next())
+	}
+	for _, m := range u.Moves() {
+		if m/9 == 0 {
+			t.Fatalf("move %d is in the decided board 0", m)
+		}
+	}
+	assertErr(t, u.Play(0*9+3), ErrIllegalMove)
+}
+
+func TestUltimateMetaWin(t *testing.T) {
+	u := NewUltimate()
+	u.boards = [9]rune{'X', 'X', Empty, Drawn, Drawn, Drawn, 'O', 'O', Empty}
+	u.next = 2
+	assertWinner(t, u, Empty)
+
+	// X wins board 2 by taking its last needed cell.
+	u.cells[2*9+0], u.cells[2*9+1] = 'X', 'X'
+	assertNoError(t, u.Play(2*9+2))
+	assertWinner(t, u, 'X')
+	if !u.Over() || u.Moves() != nil {
+		t.Error("expected the game to be over")
+	}
+}
+
+func TestUltimateDrawnBoardsDontWin(t *testing.T) {
+	u := NewUltimate()
+	u.boards = [9]rune{Drawn, Drawn, Drawn, 'X', 'O', 'X', 'O', 'X', 'O'}
+	assertWinner(t, u, Empty)
+	if !u.Over() {
+		t.Error("expected a game with no open boards to be over")
+	}
+}
+
+func TestQubicLines(t *testing.T) {
+	// n² lines along each axis, 2n diagonals in each of the 3n planes
+	// facing an axis, and 4 space diagonals.
+	for size, want := range map[int]int{3: 49, 4: 76} {
+		if got := len(NewQubic(size).Lines()); got != want {
+			t.Errorf("size %d: got %d lines, want %d", size, got, want)
+		}
+	}
+}
+
+func TestQubic(t *testing.T) {
+	t.Run("space diagonal wins", func(t *testing.T) {
+		q := NewQubic(4)
+		// X takes (0,0,0) (1,1,1) (2,2,2) (3,3,3); O plays along layer 0's
+		// top row.
+		assertNoError(t, Replay(q, []int{0, 1, 21, 2, 42, 3}))
+		assertWinner(t, q, Empty)
+		assertNoError(t, q.Play(63))
+		assertWinner(t, q, 'X')
+		assertErr(t, q.Play(5), ErrGameOver)
+	})
+
+	t.Run("vertical line through the layers", func(t *testing.T) {
+		q := NewQubic(3)
+		// O takes the centre of every layer.
+		assertNoError(t, Replay(q, []int{0, 4, 1, 13, 8, 22}))
+		assertWinner(t, q, 'O')
+	})
+
+	t.Run("illegal moves", func(t *testing.T) {
+		q := NewQubic(3)
+		assertNoError(t, q.Play(13))
+		assertErr(t, q.Play(13), ErrCellTaken)
+		assertErr(t, q.Play(27), ErrOutOfRange)
+	})
+}
+
+func TestMisere(t *testing.T) {
+	t.Run("completing a line loses", func(t *testing.T) {
+		m := NewMisere()
+		assertNoError(t, Replay(m, []int{0, 3, 1, 4, 2}))
+		assertWinner(t, m, 'O')
+		assertErr(t, m.Play(5), ErrGameOver)
+	})
+
+	t.Run("draw", func(t *testing.T) {
+		m := NewMisere()
+		assertNoError(t, Replay(m, []int{0, 4, 8, 1, 7, 6, 2, 5, 3}))
+		assertWinner(t, m, Empty)
+		if !m.Over() {
+			t.Error("expected a full board to be over")
+		}
+	})
+
+	t.Run("lines count against you", func(t *testing.T) {
+		m := NewMisere()
+		assertNoError(t, m.Play(4))
+		if m.Evaluate() >= 0 {
+			t.Errorf("got %d for X in the centre, want a negative score", m.Evaluate())
+		}
+	})
+}
+
+func TestNotakto(t *testing.T) {
+	t.Run("killing the last board loses", func(t *testing.T) {
+		n := NewNotakto(1)
+		// Both seats place X; the second seat completes the top row.
+		assertNoError(t, Replay(n, []int{0, 4, 1}))
+		assertWinner(t, n, Empty)
+		assertNoError(t, n.Play(2))
+		assertWinner(t, n, 'X')
+		if !slices.Equal(n.Cells()[:3], []rune{'X', 'X', 'X'}) {
+			t.Errorf("got cells %q, want both seats to place X", string(n.Cells()))
+		}
+	})
+
+	t.Run("dead boards take no moves", func(t *testing.T) {
+		n := NewNotakto(2)
+		assertNoError(t, Replay(n, []int{0, 1, 2}))
+		if !n.Dead(0) || n.Over() {
+			t.Fatal("expected board 0 dead with board 1 still live")
+		}
+		assertErr(t, n.Play(5), ErrIllegalMove)
+		for _, m := range n.Moves() {
+			if m < 9 {
+				t.Fatalf("move %d is on the dead board", m)
+			}
+		}
+	})
+
+	t.Run("safe move parity", func(t *testing.T) {
+		n := NewNotakto(1)
+		// X X . / . . . / . . X leaves four safe cells with O to move:
+		// O plays the last of them only if the count is odd, so X is
+		// favoured.
+		assertNoError(t, Replay(n, []int{0, 1, 8}))
+		if n.Evaluate() <= 0 {
+			t.Errorf("got %d, want X favoured", n.Evaluate())
+		}
+	})
+}
+
+func TestWild(t *testing.T) {
+	t.Run("completing a line of either mark wins", func(t *testing.T) {
+		w := NewWild()
+		o := func(cell int) int { return w.MoveFor(cell, 'O') }
+		// X's seat places O twice; O's seat completes the row of Os.
+		assertNoError(t, Replay(w, []int{o(0), 4, o(1), 8}))
+		assertWinner(t, w, Empty)
+		assertNoError(t, w.Play(o(2)))
+		assertWinner(t, w, 'X')
+		if got := string(w.Cells()[:3]); got != "OOO" {
+			t.Errorf("got top row %q, want OOO", got)
+		}
+	})
+
+	t.Run("moves offer both marks", func(t *testing.T) {
+		w := NewWild()
+		if got := len(w.Moves()); got != 18 {
+			t.Errorf("got %d opening moves, want 18", got)
+		}
+		assertNoError(t, w.Play(w.MoveFor(4, 'O')))
+		assertErr(t, w.Play(4), ErrCellTaken)
+		assertErr(t, w.Play(18), ErrOutOfRange)
+	})
+
+	t.Run("a ready line favours the side to move", func(t *testing.T) {
+		w := NewWild()
+		assertNoError(t, Replay(w, []int{0, 1}))
+		if w.Evaluate() <= 0 {
+			t.Errorf("got %d, want X to move and win", w.Evaluate())
+		}
+	})
+}
+
+func TestOrderChaos(t *testing.T) {
+	if got := len(orderChaosLines); got != 32 {
+		t.Errorf("got %d lines, want 32", got)
+	}
+
+	t.Run("order wins with five of a mark", func(t *testing.T) {
+		oc := NewOrderChaos()
+		o := func(cell int) int { return oc.MoveFor(cell, 'O') }
+		// Order lays Os down the second column while Chaos plays Xs on the
+		// far right.
+		assertNoError(t, Replay(oc, []int{o(1), 5, o(7), 11, o(13), 17, o(19), 23}))
+		assertWinner(t, oc, Empty)
+		assertNoError(t, oc.Play(o(25)))
+		assertWinner(t, oc, 'X')
+	})
+
+	t.Run("chaos wins a full board without a line", func(t *testing.T) {
+		oc := NewOrderChaos()
+		// Rows alternate XXOOXX and OOXXOO, breaking every line of five.
+		var moves []int
+		for i := range 36 {
+			mark := 'X'
+			if (i/6)%2 == 0 && (i%6 == 2 || i%6 == 3) || (i/6)%2 == 1 && i%6 != 2 && i%6 != 3 {
+				mark = 'O'
+			}
+			moves = append(moves, oc.MoveFor(i, mark))
+		}
+		assertNoError(t, Replay(oc, moves))
+		assertWinner(t, oc, 'O')
+	})
+
+	t.Run("mixed lines favour chaos", func(t *testing.T) {
+		same, mixed := NewOrderChaos(), NewOrderChaos()
+		assertNoError(t, Replay(same, []int{14, 15}))
+		assertNoError(t, Replay(mixed, []int{14, mixed.MoveFor(15, 'O')}))
+		if mixed.Evaluate() >= same.Evaluate() {
+			t.Errorf("got %d with mixed marks, want less than %d with matching ones",
+				mixed.Evaluate(), same.Evaluate())
+		}
+	})
+}
+
+func TestGrid(t *testing.T) {
+	t.Run("gravity drops to the lowest free cell", func(t *testing.T) {
+		g := NewGrid(ConnectFour)
+		assertNoError(t, Replay(g, []int{3, 3}))
+		cells := g.Cells()
+		if cells[5*7+3] != 'X' || cells[4*7+3] != 'O' {
+			t.Errorf("got column 3 = %q, want X at the bottom and O above", column(g, 3))
+		}
+		if got := len(g.Moves()); got != 7 {
+			t.Errorf("got %d moves, want one per column", got)
+		}
+		assertErr(t, g.Play(7), ErrOutOfRange)
+	})
+
+	t.Run("full columns take no moves", func(t *testing.T) {
+		g := NewGrid(Options{Cols: 3, Rows: 3, InARow: 3, Gravity: true})
+		assertNoError(t, Replay(g, []int{0, 0, 0}))
+		assertErr(t, g.Play(0), ErrCellTaken)
+		if got := g.Moves(); !slices.Equal(got, []int{1, 2}) {
+			t.Errorf("got moves %v, want [1 2]", got)
+		}
+	})
+
+	t.Run("four in a row", func(t *testing.T) {
+		cases := map[string][]int{
+			"horizontal":    {0, 0, 1, 1, 2, 2, 3},
+			"vertical":      {0, 1, 0, 1, 0, 1, 0},
+			"diagonal":      {0, 1, 1, 2, 2, 3, 2, 3, 3, 6, 3},
+			"anti-diagonal": {6, 5, 5, 4, 4, 3, 4, 3, 3, 0, 3},
+		}
+		for name, moves := range cases {
+			g := NewGrid(ConnectFour)
+			assertNoError(t, Replay(g, moves[:len(moves)-1]))
+			if g.Winner() != Empty {
+				t.Fatalf("%s: won a move early", name)
+			}
+			assertNoError(t, g.Play(moves[len(moves)-1]))
+			if g.Winner() != 'X' {
+				t.Errorf("%s: got winner %q, want X\n%s", name, g.Winner(), string(g.Cells()))
+			}
+		}
+	})
+
+	t.Run("without gravity moves are cells", func(t *testing.T) {
+		g := NewGrid(Options{Cols: 5, Rows: 4, InARow: 4})
+		assertNoError(t, Replay(g, []int{0, 5, 6, 10, 12, 15}))
+		assertNoError(t, g.Play(18))
+		assertWinner(t, g, 'X')
+	})
+
+	t.Run("options", func(t *testing.T) {
+		for _, o := range []Options{{Cols: 2, Rows: 6, InARow: 3}, {Cols: 7, Rows: 6, InARow: 8}, {Cols: 11, Rows: 3, InARow: 3}} {
+			if err := o.Validate(); !errors.Is(err, ErrBadOptions) {
+				t.Errorf("%+v: got %v, want ErrBadOptions", o, err)
+			}
+		}
+		s, err := NewWith("mnk", Options{Cols: 5, Rows: 5, InARow: 4})
+		assertNoError(t, err)
+		if got := len(s.Cells()); got != 25 {
+			t.Errorf("got %d cells, want 25", got)
+		}
+		if s, _ := NewWith("mnk", Options{}); s.(*Grid).Options() != ConnectFour {
+			t.Error("expected zero options to give Connect Four")
+		}
+		if s, _ := NewWith("classic", ConnectFour); len(s.Cells()) != 9 {
+			t.Error("expected classic to ignore board options")
+		}
+	})
+
+	t.Run("parse options", func(t *testing.T) {
+		for _, o := range []Options{ConnectFour, {Cols: 10, Rows: 3, InARow: 3}} {
+			got, err := ParseOptions(o.String())
+			assertNoError(t, err)
+			if got != o {
+				t.Errorf("got %+v, want %+v", got, o)
+			}
+		}
+		for _, s := range []string{"", "7×6", "7×6, 4 in a line", "2×6, 3 in a row"} {
+			if _, err := ParseOptions(s); !errors.Is(err, ErrBadOptions) {
+				t.Errorf("%q: got %v, want ErrBadOptions", s, err)
+			}
+		}
+	})
+}
+
+func column(g *Grid, col int) string {
+	var b []rune
+	for row := range g.Options().Rows {
+		b = append(b, g.Cells()[row*g.Options().Cols+col])
+	}
+	return string(b)
+}
+
+func TestRegistry(t *testing.T) {
+	want := []string{"classic", "ultimate", "qubic3", "qubic4", "misere", "notakto", "wild", "order-chaos", "mnk"}
+	if got := Names(); !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	for _, info := range All() {
+		s, err := New(info.Name)
+		assertNoError(t, err)
+		if s.Turn() != 'X' || s.Over() || len(s.Moves()) == 0 {
+			t.Errorf("%s: bad starting position", info.Name)
+		}
+	}
+	if _, err := New("chess"); !errors.Is(err, ErrUnknownVariant) {
+		t.Errorf("got %v, want ErrUnknownVariant", err)
+	}
+}
+
+func TestClone(t *testing.T) {
+	for _, info := range All() {
+		s := info.New()
+		c := s.Clone()
+		assertNoError(t, c.Play(c.Moves()[0]))
+		if slices.Equal(s.Cells(), c.Cells()) {
+			t.Errorf("%s: playing on the clone changed the original", info.Name)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	c := NewClassic()
+	assertNoError(t, c.Play(4))
+	if c.Evaluate() <= 0 {
+		t.Errorf("got %d for X in the centre, want a positive score", c.Evaluate())
+	}
+
+	u := NewUltimate()
+	u.boards[4] = 'O'
+	if u.Evaluate() >= 0 {
+		t.Errorf("got %d for O owning the centre board, want a negative score", u.Evaluate())
+	}
+}
+
+func assertWinner(t testing.TB, s State, want rune) {
+	t.Helper()
+	if got := s.Winner(); got != want {
+		t.Errorf("got winner %q, want %q", got, want)
+	}
+}
+
+func assertNoError(t testing.TB, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func assertErr(t testing.TB, err, want error) {
+	t.Helper()
+	if !errors.Is(err, want) {
+		t.Errorf("got error %v, want %v", err, want)
+	}
+}
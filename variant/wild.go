@@ -0,0 +1,98 @@
+package variant
+
+import "slices"
+
+// Wild is played on a 3×3 board where each player places either mark, and
+// whoever completes a line of either mark wins. Turn and Winner name the
+// seats; moves below 9 place an X and moves from 9 up an O.
+type Wild struct {
+	cells  [9]rune
+	turn   rune
+	winner rune
+}
+
+func NewWild() *Wild {
+	w := &Wild{turn: 'X', winner: Empty}
+	for i := range w.cells {
+		w.cells[i] = Empty
+	}
+	return w
+}
+
+func (w *Wild) Cells() []rune { return w.cells[:] }
+func (w *Wild) Turn() rune    { return w.turn }
+
+func (w *Wild) MoveFor(cell int, mark rune) int {
+	if mark == 'O' {
+		return cell + len(w.cells)
+	}
+	return cell
+}
+
+func (w *Wild) Moves() []int {
+	if w.Over() {
+		return nil
+	}
+	var moves []int
+	for _, mark := range []rune{'X', 'O'} {
+		for i, c := range w.cells {
+			if c == Empty {
+				moves = append(moves, w.MoveFor(i, mark))
+			}
+		}
+	}
+	return moves
+}
+
+func (w *Wild) Play(move int) error {
+	if move < 0 || move >= 2*len(w.cells) {
+		return ErrOutOfRange
+	}
+	cell, mark := move%len(w.cells), 'X'
+	if move >= len(w.cells) {
+		mark = 'O'
+	}
+	switch {
+	case w.cells[cell] != Empty:
+		return ErrCellTaken
+	case w.Over():
+		return ErrGameOver
+	}
+	w.cells[cell] = mark
+	if lineWinner(lines3, func(i int) rune { return w.cells[i] }) != Empty {
+		w.winner = w.turn
+	}
+	w.turn = Other(w.turn)
+	return nil
+}
+
+func (w *Wild) Winner() rune { return w.winner }
+
+func (w *Wild) Over() bool {
+	return w.winner != Empty || !slices.Contains(w.cells[:], Empty)
+}
+
+func (w *Wild) Clone() State {
+	wc := *w
+	return &wc
+}
+
+// Evaluate looks for a line holding two of the same mark and a free cell:
+// whoever moves next completes it.
+func (w *Wild) Evaluate() int {
+	for _, l := range lines3 {
+		var marks []rune
+		for _, i := range l {
+			if w.cells[i] != Empty {
+				marks = append(marks, w.cells[i])
+			}
+		}
+		if len(marks) == 2 && marks[0] == marks[1] {
+			if w.turn == 'X' {
+				return 100
+			}
+			return -100
+		}
+	}
+	return 0
+}